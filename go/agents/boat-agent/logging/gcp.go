@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gcplogging "cloud.google.com/go/logging"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+// Sink is an io.Writer that main wires up as charmbracelet/log's output
+// when LOG_SINK=gcp. charmbracelet must be configured with
+// log.JSONFormatter so each Write call receives one JSON log line, which
+// Sink re-encodes as a structured Cloud Logging LogEntry instead of a
+// plain text line.
+//
+// Shipping itself is handled by the underlying *gcplogging.Logger, which
+// already batches entries in memory and flushes them to the Cloud Logging
+// API on a timer or size threshold; Sink just needs to translate fields
+// and call Flush before process exit.
+type Sink struct {
+	client    *gcplogging.Client
+	logger    *gcplogging.Logger
+	projectID string
+}
+
+// NewSink creates a Sink that ships entries to the given GCP project under
+// logName. Resource labels identify the writer as a Cloud Run revision
+// using the K_SERVICE/K_REVISION/K_CONFIGURATION env vars Cloud Run sets
+// on every instance; outside Cloud Run these are simply empty.
+func NewSink(ctx context.Context, projectID, logName string) (*Sink, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("logging: GCP_PROJECT_ID is required for LOG_SINK=gcp")
+	}
+
+	client, err := gcplogging.NewClient(ctx, fmt.Sprintf("projects/%s", projectID))
+	if err != nil {
+		return nil, fmt.Errorf("logging: failed to create Cloud Logging client: %w", err)
+	}
+
+	resource := &mrpb.MonitoredResource{
+		Type: "cloud_run_revision",
+		Labels: map[string]string{
+			"project_id":         projectID,
+			"service_name":       os.Getenv("K_SERVICE"),
+			"revision_name":      os.Getenv("K_REVISION"),
+			"configuration_name": os.Getenv("K_CONFIGURATION"),
+		},
+	}
+
+	logger := client.Logger(logName, gcplogging.CommonResource(resource))
+	return &Sink{client: client, logger: logger, projectID: projectID}, nil
+}
+
+// jsonLine is the shape charmbracelet/log's JSONFormatter emits. Fields
+// not named here (e.g. ones added via log.With) are left in the decoded
+// raw map and carried through as the LogEntry payload.
+type jsonLine struct {
+	Time        time.Time       `json:"time"`
+	Level       string          `json:"level"`
+	Msg         string          `json:"msg"`
+	Trace       string          `json:"trace"`
+	SpanID      string          `json:"spanId"`
+	HTTPRequest *httpRequestLog `json:"httpRequest"`
+}
+
+// httpRequestLog is the shape loggingMiddleware attaches to the access log
+// line; Sink maps it onto gcplogging.HTTPRequest's status/latency fields.
+type httpRequestLog struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	RemoteAddr string `json:"remoteAddr"`
+	Status     int    `json:"status"`
+	LatencyMs  int64  `json:"latencyMs"`
+}
+
+// Write implements io.Writer. It's called once per log line by
+// charmbracelet/log; any line that isn't valid JSON (for example a panic
+// message written directly to the underlying writer) is shipped as a
+// DEFAULT-severity entry with the raw bytes as payload, rather than
+// dropped.
+func (s *Sink) Write(p []byte) (int, error) {
+	var line jsonLine
+	raw := map[string]any{}
+	if err := json.Unmarshal(p, &raw); err != nil {
+		s.logger.Log(gcplogging.Entry{Payload: string(p)})
+		return len(p), nil
+	}
+	_ = json.Unmarshal(p, &line)
+
+	for _, key := range []string{"time", "level", "msg", "trace", "spanId", "httpRequest"} {
+		delete(raw, key)
+	}
+	raw["msg"] = line.Msg
+
+	entry := gcplogging.Entry{
+		Timestamp: line.Time,
+		Severity:  severityFor(line.Level),
+		Payload:   raw,
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if line.Trace != "" {
+		entry.Trace = fmt.Sprintf("projects/%s/traces/%s", s.projectID, line.Trace)
+		entry.SpanID = line.SpanID
+	}
+	if line.HTTPRequest != nil {
+		entry.HTTPRequest = &gcplogging.HTTPRequest{
+			Status:  line.HTTPRequest.Status,
+			Latency: time.Duration(line.HTTPRequest.LatencyMs) * time.Millisecond,
+		}
+	}
+
+	s.logger.Log(entry)
+	return len(p), nil
+}
+
+// Flush blocks until every buffered entry has been sent to Cloud Logging.
+// main flushes on SIGTERM through Close, once srv.Shutdown has returned, so
+// the last request's log lines aren't lost to a buffer that never got to
+// drain.
+func (s *Sink) Flush() error {
+	return s.logger.Flush()
+}
+
+// Close flushes and releases the underlying Cloud Logging client.
+func (s *Sink) Close() error {
+	if err := s.logger.Flush(); err != nil {
+		return err
+	}
+	return s.client.Close()
+}
+
+func severityFor(level string) gcplogging.Severity {
+	switch level {
+	case "debug":
+		return gcplogging.Debug
+	case "info":
+		return gcplogging.Info
+	case "warn":
+		return gcplogging.Warning
+	case "error":
+		return gcplogging.Error
+	case "fatal":
+		return gcplogging.Critical
+	default:
+		return gcplogging.Default
+	}
+}
+
+var _ io.Writer = (*Sink)(nil)
@@ -0,0 +1,65 @@
+// Package logging carries Cloud Trace correlation across boat-agent's
+// request lifecycle and implements the GCP Cloud Logging sink selected by
+// LOG_SINK=gcp. A single inbound HTTP request's trace and span IDs are
+// threaded through context.Context so the agent-side Gemini call and any
+// plugin tool invocation it triggers log under the same trace, letting
+// Cloud Logging group them in the request's log panel.
+package logging
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// TraceHeader is the header Cloud Run and the Cloud Trace load balancer
+// populate on inbound requests, and the one boat-agent should forward on
+// any outbound call it wants correlated with the request.
+const TraceHeader = "X-Cloud-Trace-Context"
+
+// Trace identifies the Cloud Trace span an inbound request belongs to, as
+// parsed from a TraceHeader value of the form
+// "TRACE_ID/SPAN_ID;o=TRACE_TRUE".
+type Trace struct {
+	ID     string
+	SpanID string
+}
+
+type traceKey struct{}
+
+// ParseTraceHeader parses a TraceHeader value. It returns false if header
+// is empty.
+func ParseTraceHeader(header string) (Trace, bool) {
+	if header == "" {
+		return Trace{}, false
+	}
+
+	traceID, rest, _ := strings.Cut(header, "/")
+	spanID, _, _ := strings.Cut(rest, ";")
+	return Trace{ID: traceID, SpanID: spanID}, true
+}
+
+// WithTrace returns a context carrying t, for downstream calls that want
+// to log under the same trace as the inbound request.
+func WithTrace(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the Trace stored by WithTrace, if any.
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey{}).(Trace)
+	return t, ok
+}
+
+// Logger returns base with the request's trace and span IDs attached as
+// fields, so a Gemini call or tool invocation made from ctx logs under the
+// same trace as the inbound request that triggered it. base is returned
+// unchanged if ctx carries no Trace.
+func Logger(ctx context.Context, base *log.Logger) *log.Logger {
+	t, ok := TraceFromContext(ctx)
+	if !ok {
+		return base
+	}
+	return base.With("trace", t.ID, "spanId", t.SpanID)
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestParseTraceHeader(t *testing.T) {
+	t.Run("Empty header", func(t *testing.T) {
+		if _, ok := ParseTraceHeader(""); ok {
+			t.Error("expected ok=false for empty header")
+		}
+	})
+
+	t.Run("Trace and span", func(t *testing.T) {
+		trace, ok := ParseTraceHeader("105445aa7843bc8bf206b12000100000/1;o=1")
+		if !ok {
+			t.Fatal("expected ok=true")
+		}
+		if trace.ID != "105445aa7843bc8bf206b12000100000" {
+			t.Errorf("ID = %q, want trace id", trace.ID)
+		}
+		if trace.SpanID != "1" {
+			t.Errorf("SpanID = %q, want %q", trace.SpanID, "1")
+		}
+	})
+
+	t.Run("Trace without span", func(t *testing.T) {
+		trace, ok := ParseTraceHeader("105445aa7843bc8bf206b12000100000")
+		if !ok {
+			t.Fatal("expected ok=true")
+		}
+		if trace.ID != "105445aa7843bc8bf206b12000100000" {
+			t.Errorf("ID = %q, want trace id", trace.ID)
+		}
+		if trace.SpanID != "" {
+			t.Errorf("SpanID = %q, want empty", trace.SpanID)
+		}
+	})
+}
+
+func TestWithTraceAndTraceFromContext(t *testing.T) {
+	t.Run("Round trips a trace", func(t *testing.T) {
+		want := Trace{ID: "abc", SpanID: "1"}
+		ctx := WithTrace(context.Background(), want)
+
+		got, ok := TraceFromContext(ctx)
+		if !ok {
+			t.Fatal("expected ok=true")
+		}
+		if got != want {
+			t.Errorf("TraceFromContext() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Missing trace", func(t *testing.T) {
+		if _, ok := TraceFromContext(context.Background()); ok {
+			t.Error("expected ok=false for context without a trace")
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	base := log.NewWithOptions(nil, log.Options{})
+
+	t.Run("No trace returns base unchanged", func(t *testing.T) {
+		if got := Logger(context.Background(), base); got != base {
+			t.Error("expected base logger to be returned unchanged")
+		}
+	})
+
+	t.Run("Trace attaches fields", func(t *testing.T) {
+		ctx := WithTrace(context.Background(), Trace{ID: "abc", SpanID: "1"})
+		if got := Logger(ctx, base); got == base {
+			t.Error("expected a derived logger, got base unchanged")
+		}
+	})
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,8 +13,13 @@ import (
 	"syscall"
 	"time"
 
+	"boat-agent/logging"
+	"boat-agent/session/pgsession"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
@@ -25,6 +32,13 @@ import (
 	"google.golang.org/genai"
 )
 
+// pluginCallTimeout bounds how long a single Lua tool call may run.
+const pluginCallTimeout = 30 * time.Second
+
+// dumpToolManifest, when set, makes main print the discovered plugin tool
+// manifest as JSON instead of starting the server.
+var dumpToolManifest = flag.Bool("dump-tool-manifest", false, "print the discovered Lua plugin tool manifest as JSON and exit")
+
 // Global styling for logging allows us to flag long running processes
 var (
 	timeWarn            = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00"))
@@ -41,6 +55,35 @@ type Config struct {
 	Port         string
 	ModelName    string
 	GoogleAPIKey string
+	PluginsDir   string
+
+	// SessionBackend selects the ADK session.Service implementation:
+	// "memory" (default) or "postgres".
+	SessionBackend string
+	DB             DBConfig
+
+	// LogSink selects where charmbracelet/log lines go: "stdout" (default,
+	// human-formatted) or "gcp" (structured, shipped to Cloud Logging).
+	LogSink      string
+	GCPProjectID string
+	GCPLogName   string
+}
+
+// DBConfig holds the Postgres connection details used when
+// SessionBackend is "postgres".
+type DBConfig struct {
+	User     string
+	Password string
+	Host     string
+	Port     string
+	Name     string
+	SSLMode  string
+}
+
+// DSN constructs the PostgreSQL Data Source Name.
+func (db DBConfig) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		db.User, db.Password, db.Host, db.Port, db.Name, db.SSLMode)
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -51,9 +94,22 @@ func LoadConfig() Config {
 	}
 
 	cfg := Config{
-		Port:         os.Getenv("PORT"),
-		ModelName:    os.Getenv("GEMINI_MODEL_NAME"),
-		GoogleAPIKey: os.Getenv("GOOGLE_API_KEY"),
+		Port:           os.Getenv("PORT"),
+		ModelName:      os.Getenv("GEMINI_MODEL_NAME"),
+		GoogleAPIKey:   os.Getenv("GOOGLE_API_KEY"),
+		PluginsDir:     os.Getenv("PLUGINS_DIR"),
+		SessionBackend: os.Getenv("SESSION_BACKEND"),
+		LogSink:        os.Getenv("LOG_SINK"),
+		GCPProjectID:   os.Getenv("GCP_PROJECT_ID"),
+		GCPLogName:     os.Getenv("GCP_LOG_NAME"),
+		DB: DBConfig{
+			User:     os.Getenv("DB_USER"),
+			Password: os.Getenv("DB_PASSWORD"),
+			Host:     os.Getenv("DB_HOST"),
+			Port:     os.Getenv("DB_PORT"),
+			Name:     os.Getenv("DB_NAME"),
+			SSLMode:  os.Getenv("DB_SSLMODE"),
+		},
 	}
 
 	if cfg.Port == "" {
@@ -64,20 +120,78 @@ func LoadConfig() Config {
 		cfg.ModelName = "gemini-2.5-flash"
 	}
 
+	if cfg.SessionBackend == "" {
+		cfg.SessionBackend = "memory"
+	}
+
+	if cfg.LogSink == "" {
+		cfg.LogSink = "stdout"
+	}
+	if cfg.GCPLogName == "" {
+		cfg.GCPLogName = "boat-agent"
+	}
+
+	if cfg.DB.Host == "" {
+		cfg.DB.Host = "localhost"
+	}
+	if cfg.DB.Port == "" {
+		cfg.DB.Port = "5432"
+	}
+	if cfg.DB.SSLMode == "" {
+		cfg.DB.SSLMode = "disable"
+	}
+
 	return cfg
 }
 
 func main() {
+	flag.Parse()
+
 	// Configure charmbracelet/log
 	log.SetOutput(os.Stdout)
 	log.SetLevel(log.DebugLevel)
 	log.SetPrefix("boat-agent")
 
 	cfg := LoadConfig()
-	log.Info("Starting boat-agent", "config", cfg.ModelName, "port", cfg.Port)
 
 	ctx := context.Background()
 
+	gcpSink, err := setupLogSink(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to init log sink: %v", err)
+	}
+
+	log.Info("Starting boat-agent", "config", cfg.ModelName, "port", cfg.Port)
+
+	// 1a. Discover Lua tool plugins (PLUGINS_DIR is opt-in; empty disables it)
+	plugins, err := NewPluginRegistry(cfg.PluginsDir, pluginCallTimeout)
+	if err != nil {
+		log.Fatalf("Failed to load plugins: %v", err)
+	}
+	if cfg.PluginsDir != "" {
+		log.Info("Loaded plugins", "dir", cfg.PluginsDir, "count", len(plugins.Tools()))
+	}
+
+	if *dumpToolManifest {
+		manifest, err := json.MarshalIndent(plugins.Manifest(), "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal tool manifest: %v", err)
+		}
+		fmt.Println(string(manifest))
+		return
+	}
+
+	// fsnotify-driven hot reload keeps the registry's tool snapshot current;
+	// picking up added/changed tools in the running agent still requires a
+	// restart, since llmagent.Config.Tools is fixed at construction.
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	go func() {
+		if err := plugins.Watch(watchCtx); err != nil {
+			log.Error("Plugin watcher exited", "error", err)
+		}
+	}()
+
 	// 1. Initialize Gemini Model
 	model, err := gemini.NewModel(ctx, cfg.ModelName, &genai.ClientConfig{
 		APIKey: cfg.GoogleAPIKey,
@@ -92,9 +206,9 @@ func main() {
 		Model:       model,
 		Description: "Agent designed to gather information about sailboats.",
 		Instruction: instruction,
-		Tools: []tool.Tool{
+		Tools: append([]tool.Tool{
 			geminitool.GoogleSearch{},
-		},
+		}, plugins.Tools()...),
 	})
 
 	if err != nil {
@@ -104,10 +218,17 @@ func main() {
 	// 3. Create Loader
 	loader := agent.NewSingleLoader(boatAgent)
 
+	// 3b. Session Service
+	sessionService, err := newSessionService(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to init session service: %v", err)
+	}
+	log.Info("Using session backend", "kind", cfg.SessionBackend)
+
 	// 4. Launcher Config
 	launcherConfig := &launcher.Config{
 		AgentLoader:    loader,
-		SessionService: session.InMemoryService(),
+		SessionService: sessionService,
 	}
 
 	// 5. Create ADK HTTP Handler
@@ -145,9 +266,62 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Drain any entries still buffered in the Cloud Logging sink before we
+	// return, so a request logged just before SIGTERM isn't lost to a
+	// buffer that never got to flush.
+	if gcpSink != nil {
+		if err := gcpSink.Close(); err != nil {
+			log.Error("Failed to flush log sink", "error", err)
+		}
+	}
+
 	log.Info("Server exited")
 }
 
+// setupLogSink configures charmbracelet/log's output according to
+// cfg.LogSink. "stdout" (the default) keeps the human-formatted console
+// output already in use; "gcp" switches to JSON formatting and routes
+// lines through a logging.Sink that ships them to Cloud Logging. The
+// returned *logging.Sink is nil for the stdout case - callers should only
+// flush/close it when non-nil.
+func setupLogSink(ctx context.Context, cfg Config) (*logging.Sink, error) {
+	switch cfg.LogSink {
+	case "", "stdout":
+		log.SetFormatter(log.TextFormatter)
+		return nil, nil
+	case "gcp":
+		sink, err := logging.NewSink(ctx, cfg.GCPProjectID, cfg.GCPLogName)
+		if err != nil {
+			return nil, err
+		}
+		log.SetFormatter(log.JSONFormatter)
+		log.SetOutput(sink)
+		return sink, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink %q", cfg.LogSink)
+	}
+}
+
+// newSessionService selects the ADK session.Service backend configured via
+// SESSION_BACKEND. InMemoryService remains the default so local
+// development needs no extra infrastructure; "postgres" persists
+// conversations to the database described by cfg.DB so boat-agent survives
+// restarts and can be scaled behind a load balancer.
+func newSessionService(ctx context.Context, cfg Config) (session.Service, error) {
+	switch cfg.SessionBackend {
+	case "", "memory":
+		return session.InMemoryService(), nil
+	case "postgres":
+		db, err := sqlx.ConnectContext(ctx, "pgx", cfg.DB.DSN())
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return pgsession.New(db, pgsession.DefaultCacheSize), nil
+	default:
+		return nil, fmt.Errorf("unknown session backend %q", cfg.SessionBackend)
+	}
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode  int
@@ -178,6 +352,14 @@ func recoveryMiddleware(next http.Handler) http.Handler {
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+
+		// A trace header on the inbound request lets every log line this
+		// request triggers - this one, and any the agent or a plugin tool
+		// call emits further down - be grouped under the same Cloud Trace.
+		if trace, ok := logging.ParseTraceHeader(r.Header.Get(logging.TraceHeader)); ok {
+			r = r.WithContext(logging.WithTrace(r.Context(), trace))
+		}
+
 		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		next.ServeHTTP(ww, r)
 
@@ -191,6 +373,15 @@ func loggingMiddleware(next http.Handler) http.Handler {
 			str = timeWarn.Render(str)
 		}
 
-		log.Info(fmt.Sprintf("%s %s %s %d %s", r.Method, r.URL.Path, r.RemoteAddr, ww.statusCode, str))
+		logging.Logger(r.Context(), log.Default()).Info(
+			fmt.Sprintf("%s %s %s %d %s", r.Method, r.URL.Path, r.RemoteAddr, ww.statusCode, str),
+			"httpRequest", map[string]any{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"remoteAddr": r.RemoteAddr,
+				"status":     ww.statusCode,
+				"latencyMs":  timesince.Milliseconds(),
+			},
+		)
 	})
 }
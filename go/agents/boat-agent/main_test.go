@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"boat-agent/logging"
+
 	"github.com/charmbracelet/log"
 )
 
@@ -30,6 +33,40 @@ func TestLoadConfig(t *testing.T) {
 			t.Errorf("expected GoogleAPIKey test-key, got %s", cfg.GoogleAPIKey)
 		}
 	})
+
+	t.Run("LogSink defaults to stdout", func(t *testing.T) {
+		cfg := LoadConfig()
+
+		if cfg.LogSink != "stdout" {
+			t.Errorf("expected LogSink stdout, got %s", cfg.LogSink)
+		}
+	})
+
+	t.Run("LogSink from environment", func(t *testing.T) {
+		t.Setenv("LOG_SINK", "gcp")
+		t.Setenv("GCP_PROJECT_ID", "test-project")
+
+		cfg := LoadConfig()
+
+		if cfg.LogSink != "gcp" {
+			t.Errorf("expected LogSink gcp, got %s", cfg.LogSink)
+		}
+		if cfg.GCPProjectID != "test-project" {
+			t.Errorf("expected GCPProjectID test-project, got %s", cfg.GCPProjectID)
+		}
+		if cfg.GCPLogName != "boat-agent" {
+			t.Errorf("expected GCPLogName boat-agent, got %s", cfg.GCPLogName)
+		}
+	})
+}
+
+func TestSetupLogSink(t *testing.T) {
+	t.Run("Unknown sink is an error", func(t *testing.T) {
+		_, err := setupLogSink(context.Background(), Config{LogSink: "syslog"})
+		if err == nil {
+			t.Error("expected an error for an unknown log sink")
+		}
+	})
 }
 
 func TestRecoveryMiddleware(t *testing.T) {
@@ -102,6 +139,30 @@ func TestLoggingMiddleware(t *testing.T) {
 			t.Errorf("expected status %d, got %d", http.StatusTeapot, rr.Code)
 		}
 	})
+
+	t.Run("Propagates X-Cloud-Trace-Context to the handler's context", func(t *testing.T) {
+		var gotTrace logging.Trace
+		var gotOK bool
+		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotTrace, gotOK = logging.TraceFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		handler := loggingMiddleware(nextHandler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(logging.TraceHeader, "abc123/1;o=1")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if !gotOK {
+			t.Fatal("expected a trace in the handler's context")
+		}
+		if gotTrace.ID != "abc123" || gotTrace.SpanID != "1" {
+			t.Errorf("got trace %+v, want ID=abc123 SpanID=1", gotTrace)
+		}
+	})
 }
 
 func TestInstructionEmbedded(t *testing.T) {
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+	"google.golang.org/adk/tool"
+)
+
+// PluginManifest describes one Lua-backed tool, as declared by a plugin
+// script's top-level `tool` table. It's the shape printed by
+// -dump-tool-manifest.
+type PluginManifest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+// PluginRegistry discovers Lua scripts from a directory and hot-reloads
+// them on change. The current tool set is held behind an atomic pointer
+// (copy-on-write) so an in-flight tool call always sees a consistent
+// snapshot, never a half-reloaded one.
+type PluginRegistry struct {
+	dir         string
+	callTimeout time.Duration
+
+	tools atomic.Pointer[[]tool.Tool]
+}
+
+// NewPluginRegistry loads every *.lua script in dir. An empty dir disables
+// plugin loading entirely, so PLUGINS_DIR is an opt-in feature.
+func NewPluginRegistry(dir string, callTimeout time.Duration) (*PluginRegistry, error) {
+	r := &PluginRegistry{dir: dir, callTimeout: callTimeout}
+
+	if dir == "" {
+		empty := []tool.Tool{}
+		r.tools.Store(&empty)
+		return r, nil
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Tools returns the current snapshot of loaded plugin tools.
+func (r *PluginRegistry) Tools() []tool.Tool {
+	return *r.tools.Load()
+}
+
+// Manifest returns the manifest of every currently loaded plugin tool.
+func (r *PluginRegistry) Manifest() []PluginManifest {
+	tools := r.Tools()
+	manifest := make([]PluginManifest, 0, len(tools))
+	for _, t := range tools {
+		lt, ok := t.(*LuaTool)
+		if !ok {
+			continue
+		}
+		manifest = append(manifest, PluginManifest{Name: lt.name, Description: lt.description, Schema: lt.schema})
+	}
+	return manifest
+}
+
+// reload re-scans dir and atomically swaps in the newly loaded tool set. A
+// script that fails to load is logged and skipped, so one broken plugin
+// can't take the others down with it.
+func (r *PluginRegistry) reload() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read plugins dir %s: %w", r.dir, err)
+	}
+
+	loaded := make([]tool.Tool, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".lua") {
+			continue
+		}
+		path := filepath.Join(r.dir, e.Name())
+		lt, err := NewLuaTool(path, r.callTimeout)
+		if err != nil {
+			log.Error("Failed to load plugin", "path", path, "error", err)
+			continue
+		}
+		loaded = append(loaded, lt)
+	}
+
+	r.tools.Store(&loaded)
+	log.Info("Loaded plugins", "dir", r.dir, "count", len(loaded))
+	return nil
+}
+
+// Watch blocks, reloading the registry whenever a .lua file in dir is
+// created, written, or removed, until ctx is canceled. Run it in its own
+// goroutine.
+func (r *PluginRegistry) Watch(ctx context.Context) error {
+	if r.dir == "" {
+		<-ctx.Done()
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create plugin watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	if err := watcher.Add(r.dir); err != nil {
+		return fmt.Errorf("failed to watch plugins dir %s: %w", r.dir, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !strings.HasSuffix(event.Name, ".lua") {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				log.Error("Failed to reload plugins", "error", err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error("Plugin watcher error", "error", err)
+		}
+	}
+}
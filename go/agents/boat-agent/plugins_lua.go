@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// LuaTool adapts a Lua plugin script into an ADK tool: the script declares
+// its metadata in a top-level `tool` table and a `run(args)` function, and
+// LuaTool.Call executes that script to completion inside a fresh,
+// sandboxed lua.LState per call. A fresh state per call means concurrent
+// calls, and a hot reload swapping the underlying source out from under a
+// LuaTool, never share interpreter state.
+type LuaTool struct {
+	name        string
+	description string
+	schema      json.RawMessage
+
+	source      []byte
+	callTimeout time.Duration
+}
+
+// NewLuaTool loads and validates the plugin at path by running it once to
+// read its `tool` table, without yet invoking run().
+func NewLuaTool(path string, callTimeout time.Duration) (*LuaTool, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin %s: %w", path, err)
+	}
+
+	lt := &LuaTool{source: source, callTimeout: callTimeout}
+	if err := lt.loadMetadata(); err != nil {
+		return nil, err
+	}
+	return lt, nil
+}
+
+// loadMetadata runs the script in a throwaway state to read its `tool`
+// table, without calling run().
+func (lt *LuaTool) loadMetadata() error {
+	state := newSandboxState()
+	defer state.Close()
+
+	if err := state.DoString(string(lt.source)); err != nil {
+		return fmt.Errorf("failed to execute plugin: %w", err)
+	}
+
+	toolTable, ok := state.GetGlobal("tool").(*lua.LTable)
+	if !ok {
+		return fmt.Errorf("plugin does not define a top-level 'tool' table")
+	}
+
+	lt.name = toolTable.RawGetString("name").String()
+	if lt.name == "" {
+		return fmt.Errorf("plugin's tool table is missing 'name'")
+	}
+	lt.description = toolTable.RawGetString("description").String()
+
+	lt.schema = json.RawMessage(`{}`)
+	if schemaTable, ok := toolTable.RawGetString("schema").(*lua.LTable); ok {
+		raw, err := luaValueToJSON(schemaTable)
+		if err != nil {
+			return fmt.Errorf("failed to encode tool schema: %w", err)
+		}
+		lt.schema = raw
+	}
+
+	return nil
+}
+
+// Name implements tool.Tool.
+func (lt *LuaTool) Name() string { return lt.name }
+
+// Description implements tool.Tool.
+func (lt *LuaTool) Description() string { return lt.description }
+
+// Schema implements tool.Tool.
+func (lt *LuaTool) Schema() json.RawMessage { return lt.schema }
+
+// Call implements tool.Tool by running the plugin's run(args) function
+// inside a fresh, sandboxed Lua state, enforcing callTimeout. gopher-lua
+// can't preempt a running script, so a script that ignores its own
+// deadline logic will still leak the goroutine below until it returns;
+// the timeout only bounds how long the caller waits for it.
+func (lt *LuaTool) Call(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, lt.callTimeout)
+	defer cancel()
+
+	type result struct {
+		out json.RawMessage
+		err error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		out, err := lt.call(args)
+		done <- result{out, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("plugin %s: %w", lt.name, ctx.Err())
+	case r := <-done:
+		return r.out, r.err
+	}
+}
+
+func (lt *LuaTool) call(args json.RawMessage) (json.RawMessage, error) {
+	state := newSandboxState()
+	defer state.Close()
+
+	if err := state.DoString(string(lt.source)); err != nil {
+		return nil, fmt.Errorf("failed to execute plugin: %w", err)
+	}
+
+	runFn := state.GetGlobal("run")
+	if runFn.Type() != lua.LTFunction {
+		return nil, fmt.Errorf("plugin does not define a 'run' function")
+	}
+
+	argsValue, err := jsonToLuaValue(state, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode args: %w", err)
+	}
+
+	if err := state.CallByParam(lua.P{Fn: runFn, NRet: 1, Protect: true}, argsValue); err != nil {
+		return nil, fmt.Errorf("plugin run() failed: %w", err)
+	}
+
+	ret := state.Get(-1)
+	state.Pop(1)
+
+	out, err := luaValueToJSON(ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin result: %w", err)
+	}
+	return out, nil
+}
+
+// sandboxedBaseFuncs are base-library globals that reach the filesystem
+// despite never opening io/os/package: dofile/loadfile read and execute
+// arbitrary files the process can access, and loadstring/load compile and
+// run arbitrary Lua source from a string, bypassing the "only this file's
+// modules" surface entirely. Deleted from the global table right after
+// OpenBase so a plugin script can't reach them.
+var sandboxedBaseFuncs = []string{"dofile", "loadfile", "loadstring", "load"}
+
+// newSandboxState opens a Lua state with only the base, table, string, and
+// math libraries plus our own http/json/log modules, minus the handful of
+// base functions in sandboxedBaseFuncs. io, os, and package are never
+// opened, so together with that denylist a plugin script has no filesystem
+// or process access beyond the capabilities this file hands it.
+func newSandboxState() *lua.LState {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	lua.OpenBase(state)
+	lua.OpenTable(state)
+	lua.OpenString(state)
+	lua.OpenMath(state)
+
+	for _, name := range sandboxedBaseFuncs {
+		state.SetGlobal(name, lua.LNil)
+	}
+
+	registerHTTPModule(state)
+	registerJSONModule(state)
+	registerLogModule(state)
+
+	return state
+}
+
+func registerHTTPModule(state *lua.LState) {
+	mod := state.NewTable()
+	state.SetField(mod, "get", state.NewFunction(luaHTTPGet))
+	state.SetGlobal("http", mod)
+}
+
+// luaHTTPGet implements Lua's http.get(url), returning (body, err) with
+// err non-nil (and body nil) on failure. Capped at 10s regardless of the
+// tool call's own timeout, since a plugin making several requests
+// shouldn't be able to stall past a single slow one.
+func luaHTTPGet(state *lua.LState) int {
+	url := state.CheckString(1)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		state.Push(lua.LNil)
+		state.Push(lua.LString(err.Error()))
+		return 2
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		state.Push(lua.LNil)
+		state.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	state.Push(lua.LString(body))
+	state.Push(lua.LNil)
+	return 2
+}
+
+func registerJSONModule(state *lua.LState) {
+	mod := state.NewTable()
+	state.SetField(mod, "encode", state.NewFunction(luaJSONEncode))
+	state.SetField(mod, "decode", state.NewFunction(luaJSONDecode))
+	state.SetGlobal("json", mod)
+}
+
+func luaJSONEncode(state *lua.LState) int {
+	raw, err := luaValueToJSON(state.CheckAny(1))
+	if err != nil {
+		state.Push(lua.LNil)
+		state.Push(lua.LString(err.Error()))
+		return 2
+	}
+	state.Push(lua.LString(raw))
+	return 1
+}
+
+func luaJSONDecode(state *lua.LState) int {
+	value, err := jsonToLuaValue(state, json.RawMessage(state.CheckString(1)))
+	if err != nil {
+		state.Push(lua.LNil)
+		state.Push(lua.LString(err.Error()))
+		return 2
+	}
+	state.Push(value)
+	return 1
+}
+
+func registerLogModule(state *lua.LState) {
+	mod := state.NewTable()
+	state.SetField(mod, "info", state.NewFunction(luaLogInfo))
+	state.SetGlobal("log", mod)
+}
+
+func luaLogInfo(state *lua.LState) int {
+	log.Info("plugin", "message", state.CheckString(1))
+	return 0
+}
+
+// luaValueToJSON converts a Lua value (as returned by a plugin's run(), or
+// from its tool.schema table) into JSON.
+func luaValueToJSON(value lua.LValue) (json.RawMessage, error) {
+	raw, err := json.Marshal(luaValueToGo(value))
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func luaValueToGo(value lua.LValue) interface{} {
+	switch v := value.(type) {
+	case *lua.LNilType:
+		return nil
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if isLuaArray(v) {
+			arr := make([]interface{}, 0, v.Len())
+			v.ForEach(func(_, val lua.LValue) {
+				arr = append(arr, luaValueToGo(val))
+			})
+			return arr
+		}
+		obj := make(map[string]interface{}, v.Len())
+		v.ForEach(func(key, val lua.LValue) {
+			obj[key.String()] = luaValueToGo(val)
+		})
+		return obj
+	default:
+		return nil
+	}
+}
+
+// isLuaArray reports whether t's keys are exactly a contiguous 1..n integer
+// sequence, in which case it should be encoded as a JSON array rather than
+// an object. Lua has no native distinction between the two.
+func isLuaArray(t *lua.LTable) bool {
+	n := t.Len()
+	count := 0
+	isArray := true
+	t.ForEach(func(key, _ lua.LValue) {
+		count++
+		if _, ok := key.(lua.LNumber); !ok {
+			isArray = false
+		}
+	})
+	return isArray && count == n
+}
+
+// jsonToLuaValue converts JSON (a tool call's args) into a Lua value
+// suitable for passing as an argument to a plugin's run() function.
+func jsonToLuaValue(state *lua.LState, raw json.RawMessage) (lua.LValue, error) {
+	var goValue interface{}
+	if err := json.Unmarshal(raw, &goValue); err != nil {
+		return nil, err
+	}
+	return goValueToLua(state, goValue), nil
+}
+
+func goValueToLua(state *lua.LState, value interface{}) lua.LValue {
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []interface{}:
+		arr := state.NewTable()
+		for i, item := range v {
+			arr.RawSetInt(i+1, goValueToLua(state, item))
+		}
+		return arr
+	case map[string]interface{}:
+		obj := state.NewTable()
+		for key, item := range v {
+			obj.RawSetString(key, goValueToLua(state, item))
+		}
+		return obj
+	default:
+		return lua.LNil
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewPluginRegistry(t *testing.T) {
+	t.Run("Empty dir disables plugin loading", func(t *testing.T) {
+		registry, err := NewPluginRegistry("", time.Second)
+		if err != nil {
+			t.Fatalf("NewPluginRegistry() error = %v", err)
+		}
+		if got := len(registry.Tools()); got != 0 {
+			t.Errorf("Tools() len = %d, want 0", got)
+		}
+	})
+
+	t.Run("Loads a script and skips a broken one", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLuaFile(t, dir, "weather.lua", `
+tool = {
+	name = "weather",
+	description = "Look up the weather",
+	schema = { type = "object" },
+}
+function run(args)
+	return { ok = true }
+end
+`)
+		writeLuaFile(t, dir, "broken.lua", `this is not valid lua`)
+
+		registry, err := NewPluginRegistry(dir, time.Second)
+		if err != nil {
+			t.Fatalf("NewPluginRegistry() error = %v", err)
+		}
+
+		tools := registry.Tools()
+		if len(tools) != 1 {
+			t.Fatalf("Tools() len = %d, want 1", len(tools))
+		}
+		if got := tools[0].Name(); got != "weather" {
+			t.Errorf("Tools()[0].Name() = %v, want weather", got)
+		}
+	})
+
+	t.Run("Manifest reflects loaded tools", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLuaFile(t, dir, "weather.lua", `
+tool = {
+	name = "weather",
+	description = "Look up the weather",
+	schema = { type = "object" },
+}
+function run(args)
+	return { ok = true }
+end
+`)
+
+		registry, err := NewPluginRegistry(dir, time.Second)
+		if err != nil {
+			t.Fatalf("NewPluginRegistry() error = %v", err)
+		}
+
+		manifest := registry.Manifest()
+		if len(manifest) != 1 {
+			t.Fatalf("Manifest() len = %d, want 1", len(manifest))
+		}
+		if manifest[0].Name != "weather" {
+			t.Errorf("Manifest()[0].Name = %v, want weather", manifest[0].Name)
+		}
+	})
+}
+
+func TestLuaJSONConversion(t *testing.T) {
+	t.Run("Round-trips an object through jsonToLuaValue and luaValueToJSON", func(t *testing.T) {
+		state := newSandboxState()
+		defer state.Close()
+
+		in := json.RawMessage(`{"name":"hull","count":2,"tags":["a","b"]}`)
+		value, err := jsonToLuaValue(state, in)
+		if err != nil {
+			t.Fatalf("jsonToLuaValue() error = %v", err)
+		}
+
+		out, err := luaValueToJSON(value)
+		if err != nil {
+			t.Fatalf("luaValueToJSON() error = %v", err)
+		}
+
+		var got, want map[string]interface{}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("failed to unmarshal round-tripped JSON: %v", err)
+		}
+		if err := json.Unmarshal(in, &want); err != nil {
+			t.Fatalf("failed to unmarshal input JSON: %v", err)
+		}
+		if got["name"] != want["name"] || got["count"] != want["count"] {
+			t.Errorf("round trip = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestNewSandboxStateDeniesFilesystemEscape(t *testing.T) {
+	for _, fn := range sandboxedBaseFuncs {
+		t.Run(fn, func(t *testing.T) {
+			state := newSandboxState()
+			defer state.Close()
+
+			err := state.DoString(fn + `("/etc/hostname")`)
+			if err == nil {
+				t.Fatalf("%s() succeeded, want it to be unavailable in the sandbox", fn)
+			}
+		})
+	}
+}
+
+func writeLuaFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,337 @@
+// Package pgsession implements the ADK session.Service interface on top of
+// Postgres, so boat-agent's conversations survive restarts and can be
+// shared across replicas sitting behind a load balancer. Run the
+// migrations in migrations/ before pointing SESSION_BACKEND at it.
+package pgsession
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/adk/session"
+)
+
+// DefaultCacheSize bounds how many hot sessions Service keeps fully
+// materialized in memory, avoiding a round trip through session_events on
+// every Get for an actively-running conversation.
+const DefaultCacheSize = 256
+
+// Service implements session.Service against the sessions/session_events
+// schema in migrations/0001_sessions.sql.
+type Service struct {
+	db    *sqlx.DB
+	cache *lruCache
+}
+
+// New creates a Service using the given connection. cacheSize <= 0 falls
+// back to DefaultCacheSize.
+func New(db *sqlx.DB, cacheSize int) *Service {
+	if cacheSize <= 0 {
+		cacheSize = DefaultCacheSize
+	}
+	return &Service{db: db, cache: newLRUCache(cacheSize)}
+}
+
+type sessionRow struct {
+	ID        string    `db:"id"`
+	Agent     string    `db:"agent"`
+	UserID    string    `db:"user_id"`
+	State     []byte    `db:"state_jsonb"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// eventRow is both the write shape for a batched INSERT and the read shape
+// scanned back out of session_events.
+type eventRow struct {
+	SessionID string    `db:"session_id"`
+	Seq       int64     `db:"seq"`
+	Role      string    `db:"role"`
+	Content   []byte    `db:"content_jsonb"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Create inserts a new session row, failing if id is already taken.
+func (s *Service) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	state, err := json.Marshal(req.State)
+	if err != nil {
+		return nil, fmt.Errorf("pgsession: failed to marshal initial state: %w", err)
+	}
+
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, agent, user_id, created_at, updated_at, state_jsonb)
+		 VALUES ($1, $2, $3, $4, $4, $5)
+		 ON CONFLICT (id) DO NOTHING`,
+		req.SessionID, req.AppName, req.UserID, now, state)
+	if err != nil {
+		return nil, fmt.Errorf("pgsession: failed to create session: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, fmt.Errorf("pgsession: session %s already exists", req.SessionID)
+	}
+
+	sess := &session.Session{
+		AppName:    req.AppName,
+		UserID:     req.UserID,
+		ID:         req.SessionID,
+		State:      req.State,
+		LastUpdate: now,
+	}
+	s.cache.put(sess)
+	return &session.CreateResponse{Session: sess}, nil
+}
+
+// Get loads a session along with its events, serving from the in-memory
+// cache when the session is already hot.
+func (s *Service) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	if sess, ok := s.cache.get(req.SessionID); ok {
+		return &session.GetResponse{Session: sess}, nil
+	}
+
+	sess, err := s.load(ctx, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(sess)
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// Delete removes a session and its event history.
+func (s *Service) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, req.SessionID); err != nil {
+		return fmt.Errorf("pgsession: failed to delete session: %w", err)
+	}
+	s.cache.evict(req.SessionID)
+	return nil
+}
+
+// ListSessions returns every session for a given agent/user pair. Event
+// history is left unloaded, matching how ADK uses ListSessions purely to
+// present a picker.
+func (s *Service) ListSessions(ctx context.Context, req *session.ListSessionsRequest) (*session.ListSessionsResponse, error) {
+	var rows []sessionRow
+	err := s.db.SelectContext(ctx, &rows,
+		`SELECT id, agent, user_id, state_jsonb, created_at, updated_at
+		 FROM sessions WHERE agent = $1 AND user_id = $2 ORDER BY updated_at DESC`,
+		req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("pgsession: failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*session.Session, 0, len(rows))
+	for _, r := range rows {
+		sess, err := rowToSession(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return &session.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+// AppendEvent persists a new event and refreshes the cached copy of sess,
+// if any. It delegates to insertEvents so a future caller replaying
+// imported history can append many events in a single round trip the same
+// way.
+func (s *Service) AppendEvent(ctx context.Context, sess *session.Session, event *session.Event) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pgsession: failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var nextSeq int64
+	if err := tx.GetContext(ctx, &nextSeq,
+		`SELECT COALESCE(MAX(seq), 0) + 1 FROM session_events WHERE session_id = $1 FOR UPDATE`,
+		sess.ID); err != nil {
+		return fmt.Errorf("pgsession: failed to allocate seq: %w", err)
+	}
+
+	row, err := eventToRow(sess.ID, nextSeq, event)
+	if err != nil {
+		return err
+	}
+	if err := insertEvents(ctx, tx, []eventRow{row}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET updated_at = $2 WHERE id = $1`, sess.ID, now); err != nil {
+		return fmt.Errorf("pgsession: failed to bump session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pgsession: failed to commit: %w", err)
+	}
+
+	sess.Events = append(sess.Events, event)
+	sess.LastUpdate = now
+	s.cache.put(sess)
+	return nil
+}
+
+// insertEvents writes rows in a single multi-row INSERT, so appending a
+// batch of events (e.g. while replaying imported history) costs one round
+// trip instead of len(rows).
+func insertEvents(ctx context.Context, tx *sqlx.Tx, rows []eventRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := tx.NamedExecContext(ctx,
+		`INSERT INTO session_events (session_id, seq, role, content_jsonb, created_at)
+		 VALUES (:session_id, :seq, :role, :content_jsonb, :created_at)`,
+		rows)
+	if err != nil {
+		return fmt.Errorf("pgsession: failed to insert events: %w", err)
+	}
+	return nil
+}
+
+func eventToRow(sessionID string, seq int64, event *session.Event) (eventRow, error) {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return eventRow{}, fmt.Errorf("pgsession: failed to marshal event: %w", err)
+	}
+	return eventRow{
+		SessionID: sessionID,
+		Seq:       seq,
+		Role:      eventRole(content),
+		Content:   content,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// eventRole best-effort extracts a queryable "role" (the event's author)
+// from its marshaled JSON, so session_events.role can be filtered on
+// without unmarshaling every event's full payload.
+func eventRole(content []byte) string {
+	var tagged struct {
+		Author string `json:"author"`
+	}
+	if err := json.Unmarshal(content, &tagged); err != nil || tagged.Author == "" {
+		return "unknown"
+	}
+	return tagged.Author
+}
+
+func (s *Service) load(ctx context.Context, sessionID string) (*session.Session, error) {
+	var row sessionRow
+	if err := s.db.GetContext(ctx, &row,
+		`SELECT id, agent, user_id, state_jsonb, created_at, updated_at FROM sessions WHERE id = $1`,
+		sessionID); err != nil {
+		return nil, fmt.Errorf("pgsession: session not found: %w", err)
+	}
+
+	var eventRows []eventRow
+	if err := s.db.SelectContext(ctx, &eventRows,
+		`SELECT session_id, seq, role, content_jsonb, created_at FROM session_events WHERE session_id = $1 ORDER BY seq ASC`,
+		sessionID); err != nil {
+		return nil, fmt.Errorf("pgsession: failed to load events: %w", err)
+	}
+
+	events := make([]*session.Event, 0, len(eventRows))
+	for _, er := range eventRows {
+		var ev session.Event
+		if err := json.Unmarshal(er.Content, &ev); err != nil {
+			return nil, fmt.Errorf("pgsession: failed to unmarshal event: %w", err)
+		}
+		events = append(events, &ev)
+	}
+
+	return rowToSession(row, events)
+}
+
+func rowToSession(row sessionRow, events []*session.Event) (*session.Session, error) {
+	var state map[string]interface{}
+	if len(row.State) > 0 {
+		if err := json.Unmarshal(row.State, &state); err != nil {
+			return nil, fmt.Errorf("pgsession: failed to unmarshal state: %w", err)
+		}
+	}
+
+	return &session.Session{
+		AppName:    row.Agent,
+		UserID:     row.UserID,
+		ID:         row.ID,
+		State:      state,
+		Events:     events,
+		LastUpdate: row.UpdatedAt,
+	}, nil
+}
+
+// lruCache is a small, mutex-guarded least-recently-used cache of hot
+// sessions keyed by session ID. It exists purely to avoid re-reading a
+// session's full event history from Postgres on every Get while a
+// conversation is actively running.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front = most recently used
+}
+
+type lruEntry struct {
+	key     string
+	session *session.Session
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache) get(key string) (*session.Session, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).session, true
+}
+
+func (c *lruCache) put(sess *session.Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[sess.ID]; ok {
+		el.Value.(*lruEntry).session = sess
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&lruEntry{key: sess.ID, session: sess})
+	c.entries[sess.ID] = el
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+var _ session.Service = (*Service)(nil)
@@ -2,15 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"navallist/internal/agent"
+	"navallist/internal/agent/sessionstore"
+	"navallist/internal/auth"
 	"navallist/internal/config"
 	"navallist/internal/data"
+	"navallist/internal/data/migrations"
+	ctxlog "navallist/internal/log"
+	"navallist/internal/mailer"
 	"navallist/internal/realtime"
 	"navallist/internal/server"
+	"navallist/internal/storagereconciler"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -18,6 +27,7 @@ import (
 	"github.com/felixge/httpsnoop"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/acme/autocert"
 	"google.golang.org/adk/session"
 )
 
@@ -31,12 +41,78 @@ func main() {
 	log.SetPrefix("backend")
 
 	ctx := context.Background()
+
+	if len(os.Args) > 1 && os.Args[1] == "realtime" {
+		if err := runRealtimeCLI(ctx, os.Args[2:]); err != nil {
+			log.Error("realtime command failed", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(ctx); err != nil {
 		log.Error("Application error", "err", err)
 		os.Exit(1)
 	}
 }
 
+// runRealtimeCLI implements the `navallist realtime ...` subcommands.
+// Currently just "diag", which connects to the configured engine and
+// Postgres and prints presence stats for every active trip's channel.
+func runRealtimeCLI(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "diag" {
+		return fmt.Errorf("usage: navallist realtime diag")
+	}
+
+	cfg, err := config.Load(os.LookupEnv)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := sqlx.Connect("pgx", cfg.DB.DSN())
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "err", err)
+		}
+	}()
+
+	rtService, err := realtime.New(data.NewSQLStore(db), realtime.EngineConfig{
+		Kind:              cfg.Realtime.Kind,
+		RedisAddrs:        cfg.Realtime.RedisAddrs,
+		RedisClusterAddrs: cfg.Realtime.RedisClusterAddrs,
+		RedisPassword:     cfg.Realtime.RedisPassword,
+		HistorySize:       cfg.Realtime.HistorySize,
+		HistoryTTL:        cfg.Realtime.HistoryTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to init realtime service: %w", err)
+	}
+	defer func() {
+		if err := rtService.Close(ctx); err != nil {
+			log.Error("failed to close realtime service", "err", err)
+		}
+	}()
+
+	stats, err := rtService.Diag(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect realtime diagnostics: %w", err)
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("no trip channels currently have connected clients")
+		return nil
+	}
+
+	fmt.Printf("%-48s %10s %8s\n", "CHANNEL", "CLIENTS", "USERS")
+	for _, s := range stats {
+		fmt.Printf("%-48s %10d %8d\n", s.Channel, s.NumClients, s.NumUsers)
+	}
+	return nil
+}
+
 func run(ctx context.Context) error {
 	// 1. Load Configuration
 	cfg, err := config.Load(os.LookupEnv)
@@ -77,23 +153,122 @@ func run(ctx context.Context) error {
 		}
 	}()
 
+	// 2b. Apply Schema Migrations. Refuses to boot if the database is ahead
+	// of what this binary knows how to migrate to - that means an older
+	// binary got deployed after a newer one already migrated the schema.
+	if err := migrateSchema(ctx, db); err != nil {
+		return fmt.Errorf("failed to apply schema migrations: %w", err)
+	}
+
 	// 3. Initialize Services
 	store := data.NewSQLStore(db)
 
-	// 3c. Initialize Blob Storage (Disk only for sample)
-	storageDir := "data/artifacts"
-	storage := data.NewDiskStorage(storageDir)
-	log.Info("Using Disk Storage", "dir", storageDir)
+	// 3c. Initialize Blob Storage
+	storage, err := data.NewStorage(ctx, data.StorageConfig{
+		Kind:                  cfg.Storage.Kind,
+		DiskBaseDir:           cfg.Storage.DiskBaseDir,
+		S3Bucket:              cfg.Storage.S3Bucket,
+		S3Region:              cfg.Storage.S3Region,
+		S3Endpoint:            cfg.Storage.S3Endpoint,
+		S3AccessKey:           cfg.Storage.S3AccessKey,
+		S3SecretKey:           cfg.Storage.S3SecretKey,
+		S3SSEAlgorithm:        cfg.Storage.S3SSEAlgorithm,
+		S3SSEKMSKeyID:         cfg.Storage.S3SSEKMSKeyID,
+		GCSBucket:             cfg.Storage.GCSBucket,
+		GCSSignServiceAccount: cfg.Storage.GCSSignServiceAccount,
+		GCSSignPrivateKey:     cfg.Storage.GCSSignPrivateKey,
+		GCSKMSKeyName:         cfg.Storage.GCSKMSKeyName,
+		GCSProjectID:          cfg.Storage.GCSProjectID,
+		AzureAccountName:      cfg.Storage.AzureAccountName,
+		AzureAccountKey:       cfg.Storage.AzureAccountKey,
+		AzureContainer:        cfg.Storage.AzureContainer,
+		AzureEncryptionScope:  cfg.Storage.AzureEncryptionScope,
+		WebDAVBaseURL:         cfg.Storage.WebDAVBaseURL,
+		WebDAVUsername:        cfg.Storage.WebDAVUsername,
+		WebDAVPassword:        cfg.Storage.WebDAVPassword,
+		SignedURLSecret:       cfg.Auth.SessionSecret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to init storage backend: %w", err)
+	}
+	log.Info("Using storage backend", "kind", cfg.Storage.Kind)
+
+	// GetArtifactSized's derived-image cache (internal/data/artifact_thumbnails.go)
+	// reads/writes through the same storage backend as the originals it
+	// resizes.
+	store.Storage = storage
+	store.StartThumbnailCacheEviction(ctx, cfg.Storage.ThumbnailCacheMaxBytes, cfg.Storage.ThumbnailCacheEvictionInterval)
+
+	// 3d. Initialize Mailer
+	mailSvc, err := mailer.New(mailer.Config{
+		Kind:         cfg.Mailer.Kind,
+		FromAddress:  cfg.Mailer.FromAddress,
+		SMTPHost:     cfg.Mailer.SMTPHost,
+		SMTPPort:     cfg.Mailer.SMTPPort,
+		SMTPUsername: cfg.Mailer.SMTPUsername,
+		SMTPPassword: cfg.Mailer.SMTPPassword,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to init mailer backend: %w", err)
+	}
+	log.Info("Using mailer backend", "kind", cfg.Mailer.Kind)
+
+	// 3e. Initialize Auth Provider. Kind selects exactly one of the two
+	// Provider categories: "ldap"/"keystone" are credential-based
+	// (PasswordProvider, no redirect), everything else - "", "oidc",
+	// "google", "github" - is redirect-based (Provider).
+	authCfg := auth.Config{
+		Kind:               cfg.Auth.Kind,
+		ClientID:           cfg.Auth.ClientID,
+		ClientSecret:       cfg.Auth.ClientSecret,
+		RedirectURL:        cfg.Auth.RedirectURL,
+		IssuerURL:          cfg.Auth.IssuerURL,
+		Scopes:             cfg.Auth.Scopes,
+		LDAPHost:           cfg.Auth.LDAPHost,
+		LDAPBindDNTemplate: cfg.Auth.LDAPBindDNTemplate,
+		LDAPUseTLS:         cfg.Auth.LDAPUseTLS,
+		KeystoneAuthURL:    cfg.Auth.KeystoneAuthURL,
+		KeystoneDomain:     cfg.Auth.KeystoneDomain,
+	}
+
+	var authProvider auth.Provider
+	var passwordProvider auth.PasswordProvider
+	switch cfg.Auth.Kind {
+	case "ldap", "keystone":
+		passwordProvider, err = auth.NewPasswordProvider(authCfg)
+	default:
+		authProvider, err = auth.New(ctx, authCfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to init auth provider: %w", err)
+	}
+	log.Info("Using auth provider", "kind", cfg.Auth.Kind)
+
+	// sseHub fans out checklist/artifact/crew/agent events to
+	// GET /api/trips/{id}/events subscribers; built before the agent and
+	// server below so both can be wired to the same hub.
+	sseHub := realtime.NewSSEHub()
+
 	// --- Embedded Agent Initialization ---
 	// 1. Initialize Agent
-	checklistAgent, err := agent.NewChecklistAgent(ctx, store, cfg.ModelName, cfg.GoogleAPIKey)
+	checklistAgent, err := agent.NewChecklistAgent(ctx, store, cfg.ModelName, cfg.GoogleAPIKey, sseHub)
 	if err != nil {
 		return fmt.Errorf("failed to create checklist agent: %w", err)
 	}
 
 	// 2. Services for ADK
-	// Use InMemoryService for sessions (transient history)
-	sessionService := session.InMemoryService()
+	sessionService, err := newSessionService(cfg.Session, db)
+	if err != nil {
+		return fmt.Errorf("failed to init session service: %w", err)
+	}
+	if compactable, ok := sessionService.(sessionstore.Compactable); ok {
+		go sessionstore.RunCompactor(ctx, compactable, 30*24*time.Hour, time.Hour)
+	}
+
+	// storagereconciler confirms or orphans "pending" artifacts created by
+	// the PresignUploader direct-upload flow, since this server never sees
+	// those bytes itself to confirm them at request time.
+	go storagereconciler.New(store, storage).Run(ctx, 5*time.Minute)
 
 	// 3. Create Local Client
 	agentClient, err := agent.NewLocalClient(checklistAgent, sessionService)
@@ -103,27 +278,75 @@ func run(ctx context.Context) error {
 	log.Info("Embedded agent initialized")
 	// -------------------------------------
 
-	// 4. Setup Server
-	srv := server.NewServer(store, cfg, agentClient, storage, frontendFS)
-
-	// 4b. Setup Realtime
-	rtService, err := realtime.NewService(store)
+	// 4. Setup Realtime. Built before the server so its Publisher can be
+	// wired into the trip handlers that push status/type changes onto a
+	// trip's channel.
+	rtService, err := realtime.New(store, realtime.EngineConfig{
+		Kind:              cfg.Realtime.Kind,
+		RedisAddrs:        cfg.Realtime.RedisAddrs,
+		RedisClusterAddrs: cfg.Realtime.RedisClusterAddrs,
+		RedisPassword:     cfg.Realtime.RedisPassword,
+		HistorySize:       cfg.Realtime.HistorySize,
+		HistoryTTL:        cfg.Realtime.HistoryTTL,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to init realtime service: %w", err)
 	}
+	defer func() {
+		if err := rtService.Close(context.Background()); err != nil {
+			log.Error("failed to close realtime service", "err", err)
+		}
+	}()
+	log.Info("Using realtime engine", "kind", cfg.Realtime.Kind)
 	go rtService.ListenToDB(ctx, cfg.DB.DSN())
 
+	// 4b. Setup Server
+	srv := server.NewServer(store, cfg, agentClient, storage, frontendFS, mailSvc, authProvider, passwordProvider, rtService, sseHub)
+
 	// Mount WebSocket handler using the new method
 	srv.MountWebSocket(rtService)
 
 	// Wrap the mux with middleware
-	handler := recoveryMiddleware(loggingMiddleware(srv.Mux))
+	handler := recoveryMiddleware(loggingMiddleware(requestIDMiddleware(srv.Mux)))
 
 	httpServer := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: handler,
 	}
 
+	// Bind the port before dropping privileges, so a root-launched systemd
+	// unit can bind 443 and then run the rest of its life unprivileged.
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind %s: %w", httpServer.Addr, err)
+	}
+
+	var challengeListener net.Listener
+	if len(cfg.Process.AutoTLSDomains) > 0 {
+		certManager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Process.AutoTLSDomains...),
+			Cache:      autocert.DirCache(filepath.Join(cfg.Storage.DiskBaseDir, "autocert")),
+		}
+		httpServer.TLSConfig = certManager.TLSConfig()
+
+		// autocert needs to answer HTTP-01 challenges on :80, separate from
+		// the main listener (which may itself be :80 in the non-TLS case).
+		challengeListener, err = net.Listen("tcp", ":80")
+		if err != nil {
+			return fmt.Errorf("failed to bind :80 for ACME HTTP-01 challenges: %w", err)
+		}
+		go func() {
+			if err := http.Serve(challengeListener, certManager.HTTPHandler(nil)); err != nil && err != http.ErrServerClosed {
+				log.Error("ACME challenge listener failed", "err", err)
+			}
+		}()
+	}
+
+	if err := dropPrivileges(cfg.Process.RunAsUser, cfg.Process.RunAsGroup); err != nil {
+		return fmt.Errorf("failed to drop privileges: %w", err)
+	}
+
 	// 5. Start Server with Graceful Shutdown
 	// Create a channel to listen for interrupt signals
 	stop := make(chan os.Signal, 1)
@@ -133,8 +356,18 @@ func run(ctx context.Context) error {
 	errChan := make(chan error, 1)
 	go func() {
 		log.Info("Server started", "addr", httpServer.Addr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- fmt.Errorf("server failed: %w", err)
+
+		var serveErr error
+		switch {
+		case len(cfg.Process.AutoTLSDomains) > 0:
+			serveErr = httpServer.ServeTLS(listener, "", "")
+		case cfg.Process.TLSCertFile != "":
+			serveErr = httpServer.ServeTLS(listener, cfg.Process.TLSCertFile, cfg.Process.TLSKeyFile)
+		default:
+			serveErr = httpServer.Serve(listener)
+		}
+		if serveErr != nil && serveErr != http.ErrServerClosed {
+			errChan <- fmt.Errorf("server failed: %w", serveErr)
 		}
 	}()
 
@@ -155,10 +388,60 @@ func run(ctx context.Context) error {
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
+	if challengeListener != nil {
+		_ = challengeListener.Close()
+	}
 
 	log.Info("Server exited properly")
 	return nil
 }
+// migrateSchema applies any schema migrations this binary knows about but
+// the database hasn't seen yet, and refuses to start if the database is
+// already ahead of this binary - that means a newer binary migrated the
+// schema and this one is an accidental downgrade, which would otherwise
+// fail confusingly on the first query against a column it doesn't expect.
+func migrateSchema(ctx context.Context, db *sqlx.DB) error {
+	migrator := migrations.NewMigrator(db)
+
+	current, err := migrator.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read current schema version: %w", err)
+	}
+	latest := migrator.LatestVersion()
+
+	if current > latest {
+		return fmt.Errorf("database schema is at version %d, newer than this binary's version %d; refusing to start", current, latest)
+	}
+	if current == latest {
+		log.Info("Schema up to date", "version", current)
+		return nil
+	}
+
+	log.Info("Applying schema migrations", "from", current, "to", latest)
+	if err := migrator.MigrateUp(ctx); err != nil {
+		return err
+	}
+	log.Info("Schema migrations applied", "version", latest)
+	return nil
+}
+
+// newSessionService selects the ADK session.Service backend configured via
+// NAVALLIST_SESSION_KIND. Postgres reuses the existing db connection;
+// InMemoryService remains the default so local development needs no extra
+// infrastructure.
+func newSessionService(cfg config.SessionConfig, db *sqlx.DB) (session.Service, error) {
+	switch cfg.Kind {
+	case "", "memory":
+		return session.InMemoryService(), nil
+	case "postgres":
+		return sessionstore.NewPostgresService(db), nil
+	case "valkey":
+		return sessionstore.NewValkeyService(cfg.ValkeyAddr, cfg.ValkeyPassword, cfg.ValkeyDB, cfg.ValkeyTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown session kind %q", cfg.Kind)
+	}
+}
+
 func recoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -196,6 +479,38 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requestIDMiddleware stamps every request with a random request_id, carried
+// via internal/log's context fields so every log line the request produces
+// - across handlers, middleware, and agent tools - can be grep'd back to a
+// single request.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqID, err := generateRequestID()
+		if err != nil {
+			log.Error("Failed to generate request id", "err", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := ctxlog.WithFields(r.Context(), "request_id", reqID)
+		w.Header().Set("X-Request-Id", reqID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// generateRequestID returns a random UUIDv4-format request id. It's hand
+// rolled rather than pulling in google/uuid since crypto/rand + a couple of
+// bit tweaks is all RFC 4122 version 4 actually requires (see
+// internal/auth/pkce.go for the same crypto/rand-based approach).
+func generateRequestID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate request id: %w", err)
+	}
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
+
 // maskSecret replaces most of the string with asterisks for logging.
 func maskSecret(s string) string {
 	if len(s) <= 4 {
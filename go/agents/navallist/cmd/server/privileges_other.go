@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import "fmt"
+
+// dropPrivileges is unsupported on non-Unix platforms; it errors out rather
+// than silently ignoring RunAsUser/RunAsGroup if they were configured.
+func dropPrivileges(runAsUser, runAsGroup string) error {
+	if runAsUser == "" && runAsGroup == "" {
+		return nil
+	}
+	return fmt.Errorf("dropping privileges is not supported on this platform")
+}
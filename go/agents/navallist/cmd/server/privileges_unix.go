@@ -0,0 +1,63 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// dropPrivileges switches the current process to runAsUser/runAsGroup. It
+// must be called after the privileged port has already been bound, since it
+// cannot be undone. Numeric uid/gid strings are accepted alongside names.
+func dropPrivileges(runAsUser, runAsGroup string) error {
+	if runAsUser == "" && runAsGroup == "" {
+		return nil
+	}
+
+	if runAsGroup != "" {
+		gid, err := lookupGID(runAsGroup)
+		if err != nil {
+			return fmt.Errorf("failed to resolve group %q: %w", runAsGroup, err)
+		}
+		if err := syscall.Setgid(gid); err != nil {
+			return fmt.Errorf("failed to setgid(%d): %w", gid, err)
+		}
+	}
+
+	if runAsUser != "" {
+		uid, err := lookupUID(runAsUser)
+		if err != nil {
+			return fmt.Errorf("failed to resolve user %q: %w", runAsUser, err)
+		}
+		if err := syscall.Setuid(uid); err != nil {
+			return fmt.Errorf("failed to setuid(%d): %w", uid, err)
+		}
+	}
+
+	return nil
+}
+
+func lookupUID(name string) (int, error) {
+	if uid, err := strconv.Atoi(name); err == nil {
+		return uid, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+func lookupGID(name string) (int, error) {
+	if gid, err := strconv.Atoi(name); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
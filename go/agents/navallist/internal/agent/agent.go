@@ -5,6 +5,7 @@ import (
 	_ "embed"
 
 	"navallist/internal/data"
+	"navallist/internal/realtime"
 
 	adkagent "google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
@@ -18,7 +19,9 @@ import (
 var instruction string
 
 // NewChecklistAgent initializes a new ADK agent for boat checklists.
-func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey string) (adkagent.Agent, error) {
+// sseHub may be nil, in which case the agent's checklist writes simply
+// aren't broadcast to StreamEvents subscribers in real time.
+func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey string, sseHub *realtime.SSEHub) (adkagent.Agent, error) {
 	// Model Setup
 	model, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
 		APIKey: apikey,
@@ -28,7 +31,14 @@ func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey
 	}
 
 	// Initialize Tools Handler
-	handler := &ChecklistTool{Store: store}
+	handler := &ChecklistTool{
+		Store:                     store,
+		SSEHub:                    sseHub,
+		UpdateItemsTimeout:        DefaultUpdateItemsTimeout,
+		UpdateMetadataTimeout:     DefaultUpdateMetadataTimeout,
+		GetCrewListTimeout:        DefaultGetCrewListTimeout,
+		GetChecklistStatusTimeout: DefaultGetChecklistStatusTimeout,
+	}
 
 	// Define Tools
 	updateTool, err := functiontool.New(
@@ -36,7 +46,7 @@ func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey
 			Name:        "update_checklist_items",
 			Description: "Updates the status and location of one or more items on the boat's checklist. You can only assign items to names exactly as they appear in 'get_crew_list'. Assigning to anyone else is forbidden.",
 		},
-		handler.UpdateItems,
+		recordToolCall(store, "update_checklist_items", handler.UpdateItems),
 	)
 	if err != nil {
 		return nil, err
@@ -47,7 +57,7 @@ func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey
 			Name:        "get_crew_list",
 			Description: "Returns a list of all crew members currently participating in this trip. Use this to find the correct names for assignments.",
 		},
-		handler.GetCrewList,
+		recordToolCall(store, "get_crew_list", handler.GetCrewList),
 	)
 	if err != nil {
 		return nil, err
@@ -58,7 +68,7 @@ func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey
 			Name:        "get_checklist_status",
 			Description: "Returns the current state of the boat checklist, including who is assigned to each item and what has been completed.",
 		},
-		handler.GetChecklistStatus,
+		recordToolCall(store, "get_checklist_status", handler.GetChecklistStatus),
 	)
 	if err != nil {
 		return nil, err
@@ -69,7 +79,7 @@ func NewChecklistAgent(ctx context.Context, store data.Store, modelName, apikey
 			Name:        "update_trip_details",
 			Description: "Updates the boat name or captain name for the trip.",
 		},
-		handler.UpdateMetadata,
+		recordToolCall(store, "update_trip_details", handler.UpdateMetadata),
 	)
 	if err != nil {
 		return nil, err
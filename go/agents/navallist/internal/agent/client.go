@@ -4,58 +4,131 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"strings"
+	"time"
 
 	"navallist/internal/data"
+	"navallist/internal/ratelimit"
 
 	adkagent "google.golang.org/adk/agent"
-	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
 )
 
-// LocalAgentClient implements data.AgentClient by calling the agent directly via ADK runner.
+// Client is implemented by every transport the server can reach the
+// checklist agent through: LocalAgentClient (in-process ADK runner),
+// RemoteAgentClient (HTTP to another ADK-compatible server), and
+// WebSocketAgentClient (a persistent WS connection to one). Handlers should
+// depend on this interface rather than a concrete transport, so the
+// transport in use can be swapped without touching handler code.
+type Client interface {
+	// CreateSession ensures a session exists for a user before RunInteraction
+	// or StreamInteraction is called against it.
+	CreateSession(ctx context.Context, appName, userID, sessionID string) error
+	// GetSession retrieves a session's current state.
+	GetSession(ctx context.Context, appName, userID, sessionID string) (map[string]interface{}, error)
+	// RunInteraction sends an interaction payload to the agent and waits for
+	// the full response, collecting every event the run produces.
+	RunInteraction(ctx context.Context, payload interface{}) (interface{}, error)
+	// StreamInteraction is like RunInteraction but yields events as they're
+	// produced instead of collecting them first, so a caller can proxy them
+	// onward (e.g. as Server-Sent Events) while the run is still in
+	// progress. Cancelling ctx must stop the underlying run.
+	StreamInteraction(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error)
+}
+
+// agentCallRatePerSec/agentCallBurst bound how many RunInteraction/
+// CreateSession calls a single session can make before being rate-limited
+// itself, separate from the Gemini-side retry/breaker logic below - this
+// just stops one misbehaving session from burning the whole retry budget
+// other sessions share against an overloaded model.
+const (
+	agentCallRatePerSec = 1.0
+	agentCallBurst      = 5
+
+	// breakerFailureThreshold/breakerCooldown govern when LocalAgentClient
+	// stops forwarding calls to Gemini after repeated overload/rate-limit
+	// errors, serving circuitBreakerOpenResult instead for a cooldown period.
+	breakerFailureThreshold = 5
+	breakerCooldown         = 30 * time.Second
+)
+
+// LocalAgentClient implements Client by calling one of its registered agents
+// directly via ADK runner, routing each call to the runner registered under
+// the request's app_name (see AgentRegistry).
 type LocalAgentClient struct {
-	Runner   *runner.Runner
+	Registry *AgentRegistry
 	Sessions session.Service
-	Agent    adkagent.Agent
+
+	// limiter/breaker implement the retry/backoff + circuit breaker layer
+	// around RunInteraction/CreateSession (see resilience.go), protecting
+	// Gemini from retry storms during an overload and vice versa.
+	limiter *ratelimit.Limiter
+	breaker *circuitBreaker
 }
 
-// NewLocalClient creates a new LocalAgentClient.
+var _ Client = (*LocalAgentClient)(nil)
+var _ AgentHealthReporter = (*LocalAgentClient)(nil)
+
+// NewLocalClient creates a new LocalAgentClient with a as its sole
+// registered agent, under its own Name(). Call Register to add more.
 func NewLocalClient(a adkagent.Agent, s session.Service) (*LocalAgentClient, error) {
-	r, err := runner.New(runner.Config{
-		AppName:        a.Name(),
-		Agent:          a,
-		SessionService: s,
-	})
-	if err != nil {
+	registry := NewAgentRegistry(s)
+	if err := registry.Register(a.Name(), a); err != nil {
 		return nil, err
 	}
 	return &LocalAgentClient{
-		Runner:   r,
+		Registry: registry,
 		Sessions: s,
-		Agent:    a,
+		limiter:  ratelimit.NewLimiter(agentCallRatePerSec, agentCallBurst),
+		breaker:  newCircuitBreaker(breakerFailureThreshold, breakerCooldown),
 	}, nil
 }
 
-// CreateSession ensures the session exists in the session service.
+// Register adds agent to the client under name, or replaces it if name is
+// already in use, so a new or updated agent can be hot-swapped in without
+// restarting the process.
+func (c *LocalAgentClient) Register(name string, a adkagent.Agent) error {
+	return c.Registry.Register(name, a)
+}
+
+// Unregister removes the agent registered under name.
+func (c *LocalAgentClient) Unregister(name string) {
+	c.Registry.Unregister(name)
+}
+
+// HealthSnapshot reports the circuit breaker's current state and the last
+// few overload/rate-limit errors it's seen, for GET /healthz/agent.
+func (c *LocalAgentClient) HealthSnapshot() HealthSnapshot {
+	return c.breaker.snapshot()
+}
+
+// CreateSession ensures the session exists in the session service, after
+// confirming appName is a registered agent.
 func (c *LocalAgentClient) CreateSession(ctx context.Context, appName, userID, sessionID string) error {
-	// Check name match
-	if c.Agent.Name() != appName {
-		// Log warning or error? For now just proceed as we only have one agent.
+	if _, err := c.Registry.lookup(appName); err != nil {
+		return err
 	}
 
-	_, err := c.Sessions.Create(ctx, &session.CreateRequest{
-		AppName:   appName,
-		UserID:    userID,
-		SessionID: sessionID,
+	_, err := c.callWithResilience(ctx, sessionID, func(ctx context.Context) (interface{}, error) {
+		return c.Sessions.Create(ctx, &session.CreateRequest{
+			AppName:   appName,
+			UserID:    userID,
+			SessionID: sessionID,
+		})
 	})
 	return err
 }
 
 // GetSession retrieves the session state.
 func (c *LocalAgentClient) GetSession(ctx context.Context, appName, userID, sessionID string) (map[string]interface{}, error) {
+	if _, err := c.Registry.lookup(appName); err != nil {
+		return nil, err
+	}
+
 	resp, err := c.Sessions.Get(ctx, &session.GetRequest{
 		AppName:   appName,
 		UserID:    userID,
@@ -77,17 +150,97 @@ func (c *LocalAgentClient) GetSession(ctx context.Context, appName, userID, sess
 	return result, err
 }
 
-// RunInteraction sends an interaction payload to the agent.
+// RunInteraction sends an interaction payload to the agent, retrying
+// transient Gemini overload/rate-limit errors with backoff (see
+// resilience.go) and serving circuitBreakerOpenResult instead of a real run
+// once the breaker has tripped.
 func (c *LocalAgentClient) RunInteraction(ctx context.Context, payload interface{}) (interface{}, error) {
-	// Marshall payload to JSON then Unmarshal to a struct that matches /run body
-	b, err := json.Marshal(payload)
+	_, _, sessionID, _, err := parseRunPayload(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("[DEBUG] Raw Interaction Payload: %s\n", string(b))
+	result, err := c.callWithResilience(ctx, sessionID, func(ctx context.Context) (interface{}, error) {
+		return c.runOnce(ctx, payload)
+	})
+	if errors.Is(err, errBreakerOpen) {
+		return circuitBreakerOpenResult, nil
+	}
+	return result, err
+}
+
+// runOnce performs a single, non-retried agent run, used as the inner call
+// callWithResilience retries on overload/rate-limit errors.
+func (c *LocalAgentClient) runOnce(ctx context.Context, payload interface{}) (interface{}, error) {
+	appName, userID, sessionID, newMessage, err := parseRunPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	ra, err := c.Registry.lookup(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*session.Event
+	for event, err := range ra.runner.Run(ctx, userID, sessionID, newMessage, adkagent.RunConfig{}) {
+		if err != nil {
+			return nil, translateRunErr(err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// StreamInteraction is RunInteraction's streaming counterpart: instead of
+// collecting every event before returning, it hands back the runner's own
+// iterator directly, so a caller (e.g. the SSE stream handler) can forward
+// each event as soon as it's produced and stop early by abandoning the
+// range loop or cancelling ctx.
+func (c *LocalAgentClient) StreamInteraction(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error) {
+	appName, userID, sessionID, newMessage, err := parseRunPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	ra, err := c.Registry.lookup(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	run := ra.runner.Run(ctx, userID, sessionID, newMessage, adkagent.RunConfig{})
+	return func(yield func(*session.Event, error) bool) {
+		for event, err := range run {
+			if err != nil {
+				yield(nil, translateRunErr(err))
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// translateRunErr maps the runner's "session not found" error string onto
+// data.ErrNotFound, matching the sentinel handlers already check for (see
+// ChecklistHandler.RunInteraction's self-healing retry).
+func translateRunErr(err error) error {
+	if strings.Contains(err.Error(), "session") && strings.Contains(err.Error(), "not found") {
+		return data.ErrNotFound
+	}
+	return err
+}
+
+// parseRunPayload decodes the loosely-typed /run request body (app_name,
+// user_id, session_id, new_message) that both RunInteraction and
+// StreamInteraction accept, converting its debug-friendly jsonContent shape
+// back into a real genai.Content.
+func parseRunPayload(payload interface{}) (appName, userID, sessionID string, newMessage *genai.Content, err error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", "", "", nil, err
+	}
 
-	// Define a local struct that matches the incoming JSON exactly for debugging
 	type jsonPart struct {
 		Text       string `json:"text"`
 		InlineData *struct {
@@ -107,51 +260,34 @@ func (c *LocalAgentClient) RunInteraction(ctx context.Context, payload interface
 		NewMessage *jsonContent `json:"new_message"`
 	}
 	if err := json.Unmarshal(b, &req); err != nil {
-		return nil, fmt.Errorf("invalid payload structure: %w", err)
+		return "", "", "", nil, fmt.Errorf("invalid payload structure: %w", err)
 	}
 
 	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
-		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+		return "", "", "", nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
 	}
 
-	// Convert our debug struct back to the real genai.Content
-	var realNewMessage *genai.Content
-	if req.NewMessage != nil {
-		realNewMessage = &genai.Content{
-			Role: req.NewMessage.Role,
-		}
-		for i, p := range req.NewMessage.Parts {
-			if p.Text != "" {
-				fmt.Printf("[DEBUG] Part %d: Text: %q\n", i, p.Text)
-				realNewMessage.Parts = append(realNewMessage.Parts, &genai.Part{Text: p.Text})
-			}
-			if p.InlineData != nil {
-				fmt.Printf("[DEBUG] Part %d: InlineData MIMEType: %q, Data Length: %d\n", i, p.InlineData.MIMEType, len(p.InlineData.Data))
-				data, err := base64.StdEncoding.DecodeString(p.InlineData.Data)
-				if err != nil {
-					return nil, fmt.Errorf("failed to decode base64 data for part %d: %w", i, err)
-				}
-				realNewMessage.Parts = append(realNewMessage.Parts, &genai.Part{
-					InlineData: &genai.Blob{
-						MIMEType: p.InlineData.MIMEType,
-						Data:     data,
-					},
-				})
-			}
-		}
+	if req.NewMessage == nil {
+		return req.AppName, req.UserID, req.SessionID, nil, nil
 	}
 
-	// Run
-	var events []*session.Event
-	for event, err := range c.Runner.Run(ctx, req.UserID, req.SessionID, realNewMessage, adkagent.RunConfig{}) {
-		if err != nil {
-			if strings.Contains(err.Error(), "session") && strings.Contains(err.Error(), "not found") {
-				return nil, data.ErrNotFound
+	content := &genai.Content{Role: req.NewMessage.Role}
+	for i, p := range req.NewMessage.Parts {
+		if p.Text != "" {
+			content.Parts = append(content.Parts, &genai.Part{Text: p.Text})
+		}
+		if p.InlineData != nil {
+			decoded, err := base64.StdEncoding.DecodeString(p.InlineData.Data)
+			if err != nil {
+				return "", "", "", nil, fmt.Errorf("failed to decode base64 data for part %d: %w", i, err)
 			}
-			return nil, err
+			content.Parts = append(content.Parts, &genai.Part{
+				InlineData: &genai.Blob{
+					MIMEType: p.InlineData.MIMEType,
+					Data:     decoded,
+				},
+			})
 		}
-		events = append(events, event)
 	}
-
-	return events, nil
+	return req.AppName, req.UserID, req.SessionID, content, nil
 }
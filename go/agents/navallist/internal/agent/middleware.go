@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
+	"google.golang.org/adk/tool"
+)
+
+// recordToolCall wraps a functiontool handler so every invocation is
+// persisted as an AgentEvent - inputs, outputs, duration, and a coarse
+// error class - for GET /api/trips/{id}/timeline's debrief feed and
+// POST /api/trips/{id}/replay's what-if reconstruction. Recording is
+// best-effort: a failure to resolve the trip or write the event is
+// logged, not returned, since the tool call itself already ran.
+func recordToolCall[Args any, Ret any](store data.Store, toolName string, fn func(tool.Context, Args) (Ret, error)) func(tool.Context, Args) (Ret, error) {
+	return func(ctx tool.Context, args Args) (Ret, error) {
+		start := time.Now()
+		result, err := fn(ctx, args)
+		duration := time.Since(start)
+
+		tripID, tripErr := store.GetTripIDBySessionID(ctx, ctx.SessionID())
+		if tripErr != nil {
+			log.Warn("failed to resolve trip for agent event", "tool", toolName, "session_id", ctx.SessionID(), "error", tripErr)
+			return result, err
+		}
+
+		argsJSON, marshalErr := json.Marshal(args)
+		if marshalErr != nil {
+			log.Warn("failed to marshal agent event args", "tool", toolName, "error", marshalErr)
+			argsJSON = []byte("null")
+		}
+		resultJSON, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			log.Warn("failed to marshal agent event result", "tool", toolName, "error", marshalErr)
+			resultJSON = []byte("null")
+		}
+
+		status := "success"
+		errClass := ""
+		if err != nil {
+			status = "error"
+			errClass = classifyError(err)
+		}
+
+		event := models.AgentEvent{
+			TripID:     tripID,
+			SessionID:  ctx.SessionID(),
+			UserID:     ctx.UserID(),
+			ToolName:   toolName,
+			ArgsJSON:   argsJSON,
+			ResultJSON: resultJSON,
+			Status:     status,
+			ErrorClass: errClass,
+			DurationMS: duration.Milliseconds(),
+		}
+		if _, recErr := store.RecordAgentEvent(ctx, event); recErr != nil {
+			log.Warn("failed to record agent event", "tool", toolName, "trip_id", tripID, "error", recErr)
+		}
+
+		return result, err
+	}
+}
+
+// classifyError buckets a tool call's error into a coarse class for the
+// timeline/replay feed, rather than storing the full (and potentially
+// noisy/unstable) error message as the thing callers filter or group on.
+func classifyError(err error) string {
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "context"
+	default:
+		return "internal"
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import "context"
+
+// ProgressFrame is one incremental update a ProgressEmitter streams while a
+// tool iterates a bulk operation (currently just UpdateItems iterating
+// args.Updates), matching the "event: progress" SSE frames
+// handlers.RunInteraction interleaves with a run's events.
+type ProgressFrame struct {
+	Current     int    `json:"current"`
+	Total       int    `json:"total"`
+	CurrentItem string `json:"current_item"`
+	Status      string `json:"status"` // "in_progress", "done", or "error"
+}
+
+// ProgressEmitter streams ProgressFrame updates for a long-running tool
+// call, injected into a run via WithProgressEmitter. Implementations must
+// not block indefinitely: a caller that stops listening (e.g. a dropped
+// SSE connection) should still let the tool call itself complete.
+type ProgressEmitter interface {
+	EmitProgress(frame ProgressFrame)
+}
+
+type contextKey string
+
+const progressEmitterContextKey contextKey = "progress_emitter"
+
+// WithProgressEmitter attaches emitter to ctx so a tool invoked through it
+// can stream progress frames for the call (see ChecklistTool.UpdateItems).
+func WithProgressEmitter(ctx context.Context, emitter ProgressEmitter) context.Context {
+	return context.WithValue(ctx, progressEmitterContextKey, emitter)
+}
+
+// ProgressEmitterFromContext retrieves the ProgressEmitter attached by
+// WithProgressEmitter, if any was.
+func ProgressEmitterFromContext(ctx context.Context) (ProgressEmitter, bool) {
+	emitter, ok := ctx.Value(progressEmitterContextKey).(ProgressEmitter)
+	return emitter, ok
+}
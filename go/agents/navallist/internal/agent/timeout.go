@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// Default per-tool timeouts, configured onto ChecklistTool by
+// NewChecklistAgent. UpdateItems gets the most slack since it can touch
+// several checklist items (and, via resolveAssignment, crew lookups) in one
+// call; the read-only tools don't have that fan-out and shouldn't need it.
+const (
+	DefaultUpdateItemsTimeout        = 20 * time.Second
+	DefaultUpdateMetadataTimeout     = 10 * time.Second
+	DefaultGetCrewListTimeout        = 5 * time.Second
+	DefaultGetChecklistStatusTimeout = 5 * time.Second
+)
+
+// WithToolTimeout bounds a tool call's database work to d, so a stalled
+// query can't outlive the HTTP request that triggered the tool call by more
+// than d. The parent ctx is typically the tool.Context ADK hands the
+// handler - WithToolTimeout only needs it to satisfy context.Context, not
+// any of tool.Context's extra methods, so the returned context is a plain
+// context.Context safe to pass to Store calls alongside reads already taken
+// from the original tool.Context (SessionID, UserID, ...).
+func WithToolTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d)
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"navallist/internal/data"
+	"navallist/internal/data/syncstore"
+	ctxlog "navallist/internal/log"
+	"navallist/internal/realtime"
 
 	"github.com/charmbracelet/log"
 	"google.golang.org/adk/tool"
@@ -35,6 +39,50 @@ type ToolResult struct {
 // ChecklistTool holds dependencies for the agent's checklist tools.
 type ChecklistTool struct {
 	Store data.Store
+
+	// SSEHub fans out item.updated events from updateItemInternal to
+	// connected crew browsers. May be nil, in which case the agent's
+	// writes simply aren't broadcast in real time.
+	SSEHub *realtime.SSEHub
+
+	// UpdateItemsTimeout, UpdateMetadataTimeout, GetCrewListTimeout, and
+	// GetChecklistStatusTimeout bound how long the matching tool's database
+	// work may run before WithToolTimeout cancels it, so a stalled query
+	// can't outlive the HTTP request that triggered the tool call. Set by
+	// NewChecklistAgent; a zero value falls back to the matching
+	// DefaultXTimeout constant in timeout.go.
+	UpdateItemsTimeout        time.Duration
+	UpdateMetadataTimeout     time.Duration
+	GetCrewListTimeout        time.Duration
+	GetChecklistStatusTimeout time.Duration
+}
+
+func (t *ChecklistTool) updateItemsTimeout() time.Duration {
+	if t.UpdateItemsTimeout > 0 {
+		return t.UpdateItemsTimeout
+	}
+	return DefaultUpdateItemsTimeout
+}
+
+func (t *ChecklistTool) updateMetadataTimeout() time.Duration {
+	if t.UpdateMetadataTimeout > 0 {
+		return t.UpdateMetadataTimeout
+	}
+	return DefaultUpdateMetadataTimeout
+}
+
+func (t *ChecklistTool) getCrewListTimeout() time.Duration {
+	if t.GetCrewListTimeout > 0 {
+		return t.GetCrewListTimeout
+	}
+	return DefaultGetCrewListTimeout
+}
+
+func (t *ChecklistTool) getChecklistStatusTimeout() time.Duration {
+	if t.GetChecklistStatusTimeout > 0 {
+		return t.GetChecklistStatusTimeout
+	}
+	return DefaultGetChecklistStatusTimeout
 }
 
 // resolveTripID looks up the database PK from the ADK Session ID.
@@ -43,6 +91,7 @@ func (t *ChecklistTool) resolveTripID(ctx context.Context, adkSessionID string)
 	if err != nil {
 		return "", fmt.Errorf("trip not found for session '%s' (ensure you are logged in or trip is created): %w", adkSessionID, err)
 	}
+	ctxlog.FromContext(ctx).Debug("resolved trip for tool call", "adk_session_id", adkSessionID, "trip_id", id)
 	return id, nil
 }
 
@@ -54,7 +103,10 @@ func (t *ChecklistTool) GetCrewList(ctx tool.Context, _ struct{}) (interface{},
 		return nil, err
 	}
 
-	crew, err := t.Store.GetActiveCrewNames(ctx, tripID)
+	dbCtx, cancel := WithToolTimeout(ctx, t.getCrewListTimeout())
+	defer cancel()
+
+	crew, err := t.Store.GetActiveCrewNames(dbCtx, tripID)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +124,10 @@ func (t *ChecklistTool) GetChecklistStatus(ctx tool.Context, _ struct{}) (interf
 		return nil, err
 	}
 
-	items, err := t.Store.GetTripReport(ctx, tripID)
+	dbCtx, cancel := WithToolTimeout(ctx, t.getChecklistStatusTimeout())
+	defer cancel()
+
+	items, err := t.Store.GetTripReport(dbCtx, tripID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,95 +142,110 @@ type UpdateItemsArgs struct {
 	Updates []UpdateChecklistArgs `json:"updates" jsonschema:"The list of individual item updates to apply."`
 }
 
-// UpdateItems allows the agent to update one or more items in a single tool call.
+// UpdateItems allows the agent to update one or more items in a single tool
+// call. All items are applied in one Store.UpdateItemsBatch transaction, so
+// if the caller (or the underlying HTTP request) cancels partway through,
+// none of the batch's writes are left behind - see WithToolTimeout and
+// data.SQLStore.UpdateItemsBatch.
 func (t *ChecklistTool) UpdateItems(ctx tool.Context, args UpdateItemsArgs) (ToolResult, error) {
-	log.Info("Tool UpdateItems called", "count", len(args.Updates), "session_id", ctx.SessionID())
-
-	var successes []string
-	var errors []string
-	var warnings []string
-
-	for _, update := range args.Updates {
-		// reuse the logic from updateItemInternal
-		res, err := t.updateItemInternal(ctx, update)
-		if err != nil {
-			log.Error("Update failed for item", "item", update.ItemName, "error", err)
-			errors = append(errors, fmt.Sprintf("%s: %v", update.ItemName, err))
-		} else {
-			successes = append(successes, update.ItemName)
-			if res.Status == "warning" {
-				warnings = append(warnings, res.Message)
-			}
-		}
-	}
-
-	msg := fmt.Sprintf("Updated %d items: %s.", len(successes), strings.Join(successes, ", "))
-	status := "success"
-
-	if len(errors) > 0 {
-		msg += fmt.Sprintf(" Failed items: %s.", strings.Join(errors, ", "))
-		status = "partial_success"
-	}
-
-	if len(warnings) > 0 {
-		msg += fmt.Sprintf(" Warnings: %s.", strings.Join(warnings, ", "))
-		if status == "success" {
-			status = "warning"
-		}
-	}
-
-	return ToolResult{Status: status, Message: msg}, nil
-}
-
-// updateItemInternal is the internal helper for updating checklist items.
-func (t *ChecklistTool) updateItemInternal(ctx tool.Context, args UpdateChecklistArgs) (result ToolResult, err error) {
-	log.Info("Internal updateItemInternal called", "args", args, "session_id", ctx.SessionID())
+	ctxlog.FromContext(ctx).Debug("Tool UpdateItems called", "count", len(args.Updates), "session_id", ctx.SessionID())
 
 	adkID := ctx.SessionID()
 	if adkID == "" {
 		return ToolResult{Status: "error"}, fmt.Errorf("session_id missing from context")
 	}
 
-	// Resolve the real DB ID
 	tripID, err := t.resolveTripID(ctx, adkID)
 	if err != nil {
 		return ToolResult{Status: "error"}, err
 	}
 
-	// --- Check for Artifacts (Photos) in Context ---
-	photoID := args.PhotoArtifactID
-	if photoID != "" && !strings.Contains(photoID, "?v=") {
-		photoID = ""
+	emitter, hasEmitter := ProgressEmitterFromContext(ctx)
+	if hasEmitter {
+		emitter.EmitProgress(ProgressFrame{Total: len(args.Updates), Status: "in_progress"})
+	}
+
+	batch := make([]data.BatchItemUpdate, len(args.Updates))
+	for i, u := range args.Updates {
+		batch[i] = data.BatchItemUpdate{
+			ItemName:       u.ItemName,
+			IsChecked:      u.IsChecked,
+			Location:       u.Location,
+			AssignedToName: u.AssignedToName,
+		}
 	}
 
-	updated, matchFound, err := t.Store.UpdateItemWithAssignment(ctx, tripID, args.ItemName, args.IsChecked, args.Location, photoID, ctx.UserID(), args.AssignedToName)
+	dbCtx, cancel := WithToolTimeout(ctx, t.updateItemsTimeout())
+	defer cancel()
+
+	results, err := t.Store.UpdateItemsBatch(dbCtx, tripID, ctx.UserID(), batch)
 	if err != nil {
-		return ToolResult{Status: "error"}, fmt.Errorf("failed to update: %w", err)
+		log.Error("UpdateItemsBatch failed", "count", len(args.Updates), "error", err)
+		if hasEmitter {
+			emitter.EmitProgress(ProgressFrame{Total: len(args.Updates), Status: "error"})
+		}
+		return ToolResult{Status: "error"}, fmt.Errorf("failed to update items: %w", err)
 	}
 
-	loc := ""
-	if updated.LocationText != nil {
-		loc = *updated.LocationText
+	origin := ctx.UserID()
+	if origin == "" {
+		origin = "agent"
 	}
-	msg := fmt.Sprintf("Updated %s: Checked=%v, Location=%s", updated.Name, updated.IsChecked, loc)
-	status := "success"
 
-	if updated.AssignedToName != nil {
-		msg += fmt.Sprintf(", Assigned To=%s", *updated.AssignedToName)
-		if !matchFound {
-			msg += " (Warning: Name not in crew list)"
-			status = "warning"
+	var names []string
+	var warnings []string
+	for i, res := range results {
+		update := args.Updates[i]
+		names = append(names, update.ItemName)
+		if !res.MatchFound {
+			warnings = append(warnings, fmt.Sprintf("%s: name not in crew list", update.ItemName))
 		}
+
+		// Log each write as an op too, so an offline peer pulling
+		// GET /api/trips/{id}/ops?since= still sees the agent's edit even
+		// though it went through the direct write path, not ApplyOps.
+		if err := t.Store.EmitServerOp(ctx, tripID, update.ItemName, syncstore.FieldIsChecked, update.IsChecked, origin); err != nil {
+			log.Warn("failed to emit sync op", "item", update.ItemName, "field", syncstore.FieldIsChecked, "error", err)
+		}
+		if update.Location != "" {
+			if err := t.Store.EmitServerOp(ctx, tripID, update.ItemName, syncstore.FieldLocation, update.Location, origin); err != nil {
+				log.Warn("failed to emit sync op", "item", update.ItemName, "field", syncstore.FieldLocation, "error", err)
+			}
+		}
+
+		if t.SSEHub != nil {
+			if err := t.SSEHub.Publish(tripID, "item.updated", res.Item.UpdatedAt, res.Item); err != nil {
+				log.Warn("failed to publish SSE event", "item", update.ItemName, "error", err)
+			}
+		}
+
+		if hasEmitter {
+			emitter.EmitProgress(ProgressFrame{
+				Current:     i + 1,
+				Total:       len(args.Updates),
+				CurrentItem: update.ItemName,
+				Status:      "in_progress",
+			})
+		}
+	}
+
+	if hasEmitter {
+		emitter.EmitProgress(ProgressFrame{Current: len(args.Updates), Total: len(args.Updates), Status: "done"})
 	}
-	if photoID != "" {
-		msg += " (Photo attached)"
+
+	msg := fmt.Sprintf("Updated %d items: %s.", len(names), strings.Join(names, ", "))
+	status := "success"
+	if len(warnings) > 0 {
+		msg += fmt.Sprintf(" Warnings: %s.", strings.Join(warnings, ", "))
+		status = "warning"
 	}
+
 	return ToolResult{Status: status, Message: msg}, nil
 }
 
 // UpdateMetadata is the function called by the agent to update trip details.
 func (t *ChecklistTool) UpdateMetadata(ctx tool.Context, args UpdateTripArgs) (ToolResult, error) {
-	log.Info("Tool UpdateMetadata called", "args", args, "session_id", ctx.SessionID())
+	ctxlog.FromContext(ctx).Debug("Tool UpdateMetadata called", "args", args, "session_id", ctx.SessionID())
 
 	adkID := ctx.SessionID()
 	if adkID == "" {
@@ -190,7 +260,10 @@ func (t *ChecklistTool) UpdateMetadata(ctx tool.Context, args UpdateTripArgs) (T
 		cName = &args.CaptainName
 	}
 
-	updated, err := t.Store.UpdateTripMetadata(ctx, adkID, bName, cName)
+	dbCtx, cancel := WithToolTimeout(ctx, t.updateMetadataTimeout())
+	defer cancel()
+
+	updated, err := t.Store.UpdateTripMetadata(dbCtx, adkID, bName, cName)
 	if err != nil {
 		return ToolResult{Status: "error"}, fmt.Errorf("failed to update metadata: %w", err)
 	}
@@ -205,6 +278,6 @@ func (t *ChecklistTool) UpdateMetadata(ctx tool.Context, args UpdateTripArgs) (T
 	}
 
 	msg := fmt.Sprintf("Updated Trip: Boat='%s', Captain='%s'", b, c)
-	log.Info("UpdateMetadata success", "boat_name", b)
+	ctxlog.FromContext(ctx).Debug("UpdateMetadata success", "boat_name", b)
 	return ToolResult{Status: "success", Message: msg}, nil
 }
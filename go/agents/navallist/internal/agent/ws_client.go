@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"google.golang.org/adk/session"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketAgentClient implements Client over a persistent WebSocket
+// connection to a remote ADK-compatible server, for deployments where a
+// long-lived connection is cheaper than one HTTP round-trip per message
+// (e.g. an agent pool behind a load balancer that pins a session to a
+// single backend for its lifetime). CreateSession/GetSession/RunInteraction
+// send one framed request and wait for its matching response;
+// StreamInteraction leaves the connection open and yields every frame the
+// server sends until it signals the run is done.
+type WebSocketAgentClient struct {
+	// URL is the remote server's WebSocket endpoint (e.g.
+	// "wss://agent-pool:8000/ws").
+	URL string
+	// Dialer opens the connection. Defaults to websocket.DefaultDialer if nil.
+	Dialer *websocket.Dialer
+}
+
+var _ Client = (*WebSocketAgentClient)(nil)
+
+// NewWebSocketClient creates a WebSocketAgentClient pointed at url.
+func NewWebSocketClient(url string) *WebSocketAgentClient {
+	return &WebSocketAgentClient{URL: url, Dialer: websocket.DefaultDialer}
+}
+
+// wsFrame is the envelope every message exchanged over the WebSocket uses:
+// op identifies the request/response kind, and the remaining fields are
+// populated depending on op.
+type wsFrame struct {
+	Op      string          `json:"op"` // create_session, get_session, run, run_sse, event, done, error
+	AppName string          `json:"app_name,omitempty"`
+	UserID  string          `json:"user_id,omitempty"`
+	Session string          `json:"session_id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+func (c *WebSocketAgentClient) dial(ctx context.Context) (*websocket.Conn, error) {
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, _, err := dialer.DialContext(ctx, c.URL, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("ws agent: dial: %w", err)
+	}
+	return conn, nil
+}
+
+// roundTrip opens a connection, sends one frame, reads exactly one response
+// frame back, and closes the connection - the request/response pattern
+// CreateSession/GetSession/RunInteraction all share.
+func (c *WebSocketAgentClient) roundTrip(ctx context.Context, req wsFrame) (wsFrame, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return wsFrame{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	if err := conn.WriteJSON(req); err != nil {
+		return wsFrame{}, fmt.Errorf("ws agent: write: %w", err)
+	}
+
+	var resp wsFrame
+	if err := conn.ReadJSON(&resp); err != nil {
+		return wsFrame{}, fmt.Errorf("ws agent: read: %w", err)
+	}
+	if resp.Op == "error" {
+		return wsFrame{}, fmt.Errorf("ws agent: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// CreateSession sends a create_session frame and waits for its ack.
+func (c *WebSocketAgentClient) CreateSession(ctx context.Context, appName, userID, sessionID string) error {
+	_, err := c.roundTrip(ctx, wsFrame{Op: "create_session", AppName: appName, UserID: userID, Session: sessionID})
+	return err
+}
+
+// GetSession sends a get_session frame and decodes the session state it's
+// answered with.
+func (c *WebSocketAgentClient) GetSession(ctx context.Context, appName, userID, sessionID string) (map[string]interface{}, error) {
+	resp, err := c.roundTrip(ctx, wsFrame{Op: "get_session", AppName: appName, UserID: userID, Session: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(resp.Payload, &result); err != nil {
+		return nil, fmt.Errorf("ws agent: decode session: %w", err)
+	}
+	return result, nil
+}
+
+// RunInteraction sends a run frame and waits for the full event list.
+func (c *WebSocketAgentClient) RunInteraction(ctx context.Context, payload interface{}) (interface{}, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.roundTrip(ctx, wsFrame{Op: "run", Payload: body})
+	if err != nil {
+		return nil, err
+	}
+	var events []*session.Event
+	if err := json.Unmarshal(resp.Payload, &events); err != nil {
+		return nil, fmt.Errorf("ws agent: decode run response: %w", err)
+	}
+	return events, nil
+}
+
+// StreamInteraction opens a connection, sends one run_sse frame, and yields
+// an event per "event" frame the server sends back until it sends a "done"
+// frame (or the connection closes, or ctx is cancelled).
+func (c *WebSocketAgentClient) StreamInteraction(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.WriteJSON(wsFrame{Op: "run_sse", Payload: body}); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ws agent: write: %w", err)
+	}
+
+	return func(yield func(*session.Event, error) bool) {
+		defer func() { _ = conn.Close() }()
+
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				_ = conn.Close()
+			case <-stop:
+			}
+		}()
+
+		for {
+			var frame wsFrame
+			if err := conn.ReadJSON(&frame); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				yield(nil, fmt.Errorf("ws agent: read: %w", err))
+				return
+			}
+			switch frame.Op {
+			case "done":
+				return
+			case "error":
+				yield(nil, fmt.Errorf("ws agent: %s", frame.Error))
+				return
+			case "event":
+				var event session.Event
+				if err := json.Unmarshal(frame.Payload, &event); err != nil {
+					yield(nil, fmt.Errorf("ws agent: decode event: %w", err))
+					return
+				}
+				if !yield(&event, nil) {
+					return
+				}
+			}
+		}
+	}, nil
+}
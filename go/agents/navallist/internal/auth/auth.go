@@ -0,0 +1,98 @@
+// Package auth provides a pluggable identity layer for the navallist
+// server: a redirect-based Provider interface for OAuth2/OIDC-style
+// connectors (generic OIDC, Google, GitHub), a credential-based
+// PasswordProvider interface for directory-style connectors (LDAP,
+// Keystone), and the HMAC token signing used for session cookies, refresh
+// tokens, and Centrifuge connect tokens.
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Identity is the normalized result of a successful authentication,
+// regardless of which Provider produced it.
+type Identity struct {
+	Subject string // Stable, provider-scoped identifier (e.g. Google "sub", GitHub user id).
+	Email   string
+	Name    string
+}
+
+// Provider implements a redirect-based login flow: the user is sent to
+// AuthorizationURL, and the resulting authorization code is handed to
+// Exchange to resolve an Identity.
+type Provider interface {
+	// Name identifies the provider for logging and the "iss"-equivalent tag
+	// stored alongside a user's identity.
+	Name() string
+	// AuthorizationURL builds the URL to redirect the user to, binding the
+	// given CSRF state and PKCE code challenge (S256) to the request.
+	AuthorizationURL(state, codeChallenge string) string
+	// Exchange redeems an authorization code (plus the PKCE verifier that
+	// produced the challenge sent to AuthorizationURL) for an Identity.
+	Exchange(ctx context.Context, code, codeVerifier string) (*Identity, error)
+}
+
+// PasswordProvider implements a direct-credential login flow (LDAP bind,
+// Keystone password auth) with no redirect and no PKCE.
+type PasswordProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Config carries the settings needed to construct any Provider or
+// PasswordProvider; only the fields relevant to cfg.Kind are read.
+type Config struct {
+	Kind string // "oidc", "google", "github", "ldap", or "keystone"
+
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// IssuerURL is required for Kind=="oidc" and used to discover the
+	// authorization/token endpoints via /.well-known/openid-configuration.
+	IssuerURL string
+
+	Scopes []string
+
+	// LDAPHost and LDAPBindDNTemplate are required for Kind=="ldap".
+	LDAPHost           string
+	LDAPBindDNTemplate string
+	LDAPUseTLS         bool
+
+	// KeystoneAuthURL and KeystoneDomain are required for Kind=="keystone".
+	KeystoneAuthURL string
+	KeystoneDomain  string
+}
+
+// New constructs the redirect-based Provider selected by cfg.Kind. An empty
+// Kind returns a nil Provider and no error, since a deployment can run with
+// the email-code login flow alone.
+func New(ctx context.Context, cfg Config) (Provider, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, nil
+	case "oidc":
+		return NewOIDCProvider(ctx, cfg.IssuerURL, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes)
+	case "google":
+		return NewGoogleProvider(ctx, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL)
+	case "github":
+		return NewGitHubProvider(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL), nil
+	default:
+		return nil, fmt.Errorf("unknown auth provider kind %q", cfg.Kind)
+	}
+}
+
+// NewPasswordProvider constructs the credential-based PasswordProvider
+// selected by cfg.Kind.
+func NewPasswordProvider(cfg Config) (PasswordProvider, error) {
+	switch cfg.Kind {
+	case "ldap":
+		return NewLDAPProvider(cfg.LDAPHost, cfg.LDAPBindDNTemplate, cfg.LDAPUseTLS), nil
+	case "keystone":
+		return NewKeystoneProvider(cfg.KeystoneAuthURL, cfg.KeystoneDomain), nil
+	default:
+		return nil, fmt.Errorf("unknown password provider kind %q", cfg.Kind)
+	}
+}
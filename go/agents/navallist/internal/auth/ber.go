@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Minimal BER encoding/decoding for the one LDAPv3 exchange this package
+// needs (a simple bind request/response). This intentionally isn't a
+// general-purpose ASN.1/BER library — just enough TLV plumbing to avoid
+// vendoring a full LDAP client for a single bind call.
+
+const (
+	berTagInteger      = 0x02
+	berTagOctetString  = 0x04
+	berTagSequence     = 0x30 // universal, constructed
+	berTagBindRequest  = 0x60 // application 0, constructed
+	berTagBindResponse = 0x61 // application 1, constructed
+	berTagAuthSimple   = 0x80 // context-specific 0, primitive
+)
+
+func berEncodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for n > 0 {
+		b = append([]byte{byte(n & 0xff)}, b...)
+		n >>= 8
+	}
+	return append([]byte{byte(0x80 | len(b))}, b...)
+}
+
+func berTLV(tag byte, value []byte) []byte {
+	out := []byte{tag}
+	out = append(out, berEncodeLength(len(value))...)
+	return append(out, value...)
+}
+
+func berEncodeInt(n int64) []byte {
+	if n == 0 {
+		return berTLV(berTagInteger, []byte{0})
+	}
+	var b []byte
+	for n > 0 {
+		b = append([]byte{byte(n & 0xff)}, b...)
+		n >>= 8
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return berTLV(berTagInteger, b)
+}
+
+// writeBindRequest writes a complete LDAPv3 simple-bind LDAPMessage.
+func writeBindRequest(w io.Writer, messageID int, bindDN, password string) error {
+	bindOp := berTLV(berTagBindRequest, concat(
+		berEncodeInt(3), // LDAP version 3
+		berTLV(berTagOctetString, []byte(bindDN)),
+		berTLV(berTagAuthSimple, []byte(password)),
+	))
+	msg := berTLV(berTagSequence, concat(berEncodeInt(int64(messageID)), bindOp))
+
+	_, err := w.Write(msg)
+	return err
+}
+
+// readBindResponse reads one LDAPMessage and extracts the BindResponse's
+// resultCode and diagnosticMessage.
+func readBindResponse(r *bufio.Reader) (resultCode int, diagnostic string, err error) {
+	msgTag, msgBody, err := berReadTLV(r)
+	if err != nil {
+		return 0, "", err
+	}
+	if msgTag != berTagSequence {
+		return 0, "", fmt.Errorf("ldap: expected LDAPMessage SEQUENCE, got tag 0x%02x", msgTag)
+	}
+
+	br := bufio.NewReader(newByteSliceReader(msgBody))
+
+	// messageID
+	if _, _, err := berReadTLV(br); err != nil {
+		return 0, "", fmt.Errorf("ldap: failed to read messageID: %w", err)
+	}
+
+	opTag, opBody, err := berReadTLV(br)
+	if err != nil {
+		return 0, "", fmt.Errorf("ldap: failed to read protocolOp: %w", err)
+	}
+	if opTag != berTagBindResponse {
+		return 0, "", fmt.Errorf("ldap: expected BindResponse, got tag 0x%02x", opTag)
+	}
+
+	opReader := bufio.NewReader(newByteSliceReader(opBody))
+
+	_, resultCodeBytes, err := berReadTLV(opReader)
+	if err != nil {
+		return 0, "", fmt.Errorf("ldap: failed to read resultCode: %w", err)
+	}
+	for _, b := range resultCodeBytes {
+		resultCode = resultCode<<8 | int(b)
+	}
+
+	if _, _, err := berReadTLV(opReader); err != nil { // matchedDN
+		return resultCode, "", nil
+	}
+	_, diagBytes, err := berReadTLV(opReader) // diagnosticMessage
+	if err != nil {
+		return resultCode, "", nil
+	}
+
+	return resultCode, string(diagBytes), nil
+}
+
+// berReadTLV reads one BER tag-length-value triple. Only single-byte tags
+// and lengths up to 4 bytes are supported, sufficient for bind responses.
+func berReadTLV(r *bufio.Reader) (tag byte, value []byte, err error) {
+	tag, err = r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lenByte, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var length int
+	if lenByte&0x80 == 0 {
+		length = int(lenByte)
+	} else {
+		numBytes := int(lenByte & 0x7f)
+		for i := 0; i < numBytes; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+
+	value = make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return 0, nil, err
+	}
+	return tag, value, nil
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+type byteSliceReader struct {
+	b   []byte
+	pos int
+}
+
+func newByteSliceReader(b []byte) *byteSliceReader { return &byteSliceReader{b: b} }
+
+func (r *byteSliceReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.pos:])
+	r.pos += n
+	return n, nil
+}
@@ -0,0 +1,18 @@
+package auth
+
+import "context"
+
+// googleIssuer is Google's well-known OIDC issuer; discovery resolves the
+// actual authorization/token/userinfo endpoints from here.
+const googleIssuer = "https://accounts.google.com"
+
+// NewGoogleProvider returns an OIDCProvider preconfigured for Google Sign-In,
+// matching the app's pre-existing users.google_sub column.
+func NewGoogleProvider(ctx context.Context, clientID, clientSecret, redirectURL string) (*OIDCProvider, error) {
+	p, err := NewOIDCProvider(ctx, googleIssuer, clientID, clientSecret, redirectURL, []string{"openid", "email", "profile"})
+	if err != nil {
+		return nil, err
+	}
+	p.name = "google"
+	return p, nil
+}
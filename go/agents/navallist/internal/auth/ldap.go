@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// LDAPProvider implements PasswordProvider via a plain LDAPv3 simple bind,
+// mirroring Dex's ldap connector: the username is substituted into a DN
+// template (e.g. "uid=%s,ou=people,dc=example,dc=com") and bound directly
+// against the directory, with no directory-wide search step.
+//
+// Only what's needed for a simple bind is hand-rolled here (see ber.go)
+// rather than pulling in a full LDAP client library.
+type LDAPProvider struct {
+	host        string // "host:port"
+	bindDNTempl string // contains one "%s" for the username
+	useTLS      bool
+}
+
+// NewLDAPProvider returns a PasswordProvider that binds against host using
+// bindDNTemplate (e.g. "uid=%s,ou=people,dc=example,dc=com") as the
+// username's distinguished name.
+func NewLDAPProvider(host, bindDNTemplate string, useTLS bool) *LDAPProvider {
+	return &LDAPProvider{host: host, bindDNTempl: bindDNTemplate, useTLS: useTLS}
+}
+
+// Name implements PasswordProvider.
+func (p *LDAPProvider) Name() string { return "ldap" }
+
+// Authenticate implements PasswordProvider by performing a simple bind; a
+// successful bind resultCode of 0 is the only signal of identity available
+// without a follow-up directory search.
+func (p *LDAPProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("ldap: username and password are required")
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if p.useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", p.host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", p.host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap: failed to connect to %s: %w", p.host, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	bindDN := fmt.Sprintf(p.bindDNTempl, username)
+
+	if err := writeBindRequest(conn, 1, bindDN, password); err != nil {
+		return nil, fmt.Errorf("ldap: failed to send bind request: %w", err)
+	}
+
+	resultCode, diagnostic, err := readBindResponse(bufio.NewReader(conn))
+	if err != nil {
+		return nil, fmt.Errorf("ldap: failed to read bind response: %w", err)
+	}
+	if resultCode != 0 {
+		return nil, fmt.Errorf("ldap: bind rejected (resultCode=%d): %s", resultCode, diagnostic)
+	}
+
+	// No attribute search is performed for a plain bind; the DN's username
+	// component is the only identity we can report.
+	cn := username
+	if idx := strings.Index(bindDN, "="); idx != -1 {
+		if comma := strings.Index(bindDN, ","); comma != -1 && comma > idx {
+			cn = bindDN[idx+1 : comma]
+		}
+	}
+
+	return &Identity{Subject: bindDN, Name: cn}, nil
+}
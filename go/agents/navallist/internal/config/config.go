@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // EnvGetter is a function that looks up an environment variable.
@@ -20,6 +23,33 @@ const (
 	DefaultDBName      = "navallistdb"
 	DefaultDBSSLMode   = "disable"
 	DefaultSiteURL     = "http://localhost:8080"
+	DefaultStorageKind = "disk"
+	DefaultStorageDir  = "data/artifacts"
+	DefaultSessionKind = "memory"
+	DefaultMailerKind  = "dev"
+	DefaultMailerFrom  = "noreply@navallist.local"
+	DefaultRTEngine    = "memory"
+
+	// DefaultThumbnailCacheMaxBytes bounds the total size of derived-image
+	// thumbnails GetArtifactSized caches before the eviction goroutine
+	// starts reclaiming the least-recently-accessed ones.
+	DefaultThumbnailCacheMaxBytes = 512 * 1024 * 1024
+	// DefaultThumbnailCacheEvictionInterval is how often that goroutine runs.
+	DefaultThumbnailCacheEvictionInterval = 10 * time.Minute
+
+	// DefaultRTHistorySize is how many past events a reconnecting client
+	// can replay on a trip channel.
+	DefaultRTHistorySize = 100
+	// DefaultRTHistoryTTL is how long those past events stay replayable.
+	DefaultRTHistoryTTL = 5 * time.Minute
+
+	// DefaultVerificationTTL is how long an email verification code stays valid.
+	DefaultVerificationTTL = 10 * time.Minute
+
+	// DefaultSessionCookieTTL is how long a signed session cookie stays valid.
+	DefaultSessionCookieTTL = 1 * time.Hour
+	// DefaultRefreshTokenTTL is how long a persisted refresh token stays valid.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
 )
 
 // Config holds the application configuration.
@@ -31,6 +61,137 @@ type Config struct {
 	FrontendDir  string
 	SiteURL      string
 	DB           DBConfig
+	Storage      StorageConfig
+	Session      SessionConfig
+	Mailer       MailerConfig
+	Process      ProcessConfig
+	Auth         AuthConfig
+	Realtime     RealtimeConfig
+}
+
+// AuthConfig holds settings for the pluggable identity provider used to log
+// users in, plus the secret used to sign session cookies, refresh tokens,
+// and Centrifuge connect tokens.
+type AuthConfig struct {
+	Kind string // "oidc", "google", "github", "ldap", or "keystone"
+
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	IssuerURL string
+	Scopes    []string
+
+	LDAPHost           string
+	LDAPBindDNTemplate string
+	LDAPUseTLS         bool
+
+	KeystoneAuthURL string
+	KeystoneDomain  string
+
+	// SessionSecret signs session cookies, refresh tokens, Centrifuge
+	// connect tokens, and signed artifact URLs via
+	// internal/auth.SignToken/VerifyToken.
+	SessionSecret []byte
+
+	SessionCookieTTL time.Duration
+	RefreshTokenTTL  time.Duration
+}
+
+// ProcessConfig holds settings for how the backend binds its port, whether
+// it terminates TLS itself, and what user it drops to afterwards.
+type ProcessConfig struct {
+	// RunAsUser/RunAsGroup name (or numeric id) the account to switch to
+	// after the port is bound, so the process can bind 443 as root and then
+	// run unprivileged. Empty means stay as the user that started it.
+	RunAsUser  string
+	RunAsGroup string
+
+	// TLSCertFile/TLSKeyFile serve a fixed certificate. AutoTLSDomains takes
+	// precedence over them if both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AutoTLSDomains, if set, requests certificates from Let's Encrypt via
+	// autocert for the listed domains and serves the HTTP-01 challenge on
+	// :80 alongside the main listener.
+	AutoTLSDomains []string
+}
+
+// MailerConfig holds settings for the pluggable outbound email backend used
+// by the email-verification login flow.
+type MailerConfig struct {
+	Kind string // "dev" (default) or "smtp"
+
+	FromAddress string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	VerificationTTL time.Duration
+}
+
+// SessionConfig holds settings for the durable agent session backend.
+type SessionConfig struct {
+	Kind string // "memory" (default), "postgres", or "valkey"
+
+	ValkeyAddr     string
+	ValkeyPassword string
+	ValkeyDB       int
+	ValkeyTTL      time.Duration
+}
+
+// StorageConfig holds settings for the pluggable blob storage backend.
+type StorageConfig struct {
+	Kind string // "disk", "s3", "gcs", "azure", or "webdav"
+
+	DiskBaseDir string
+
+	S3Bucket       string
+	S3Region       string
+	S3Endpoint     string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3SSEAlgorithm string
+	S3SSEKMSKeyID  string
+
+	GCSBucket             string
+	GCSSignServiceAccount string
+	GCSSignPrivateKey     string
+	GCSKMSKeyName         string
+	GCSProjectID          string
+
+	AzureAccountName     string
+	AzureAccountKey      string
+	AzureContainer       string
+	AzureEncryptionScope string
+
+	WebDAVBaseURL  string
+	WebDAVUsername string
+	WebDAVPassword string
+
+	// ThumbnailCacheMaxBytes/ThumbnailCacheEvictionInterval bound the
+	// derived-image cache GetArtifactSized writes to (see
+	// internal/data/artifact_thumbnails.go).
+	ThumbnailCacheMaxBytes         int64
+	ThumbnailCacheEvictionInterval time.Duration
+}
+
+// RealtimeConfig holds settings for the pluggable Centrifuge broker and
+// presence manager the realtime service's Node runs on.
+type RealtimeConfig struct {
+	Kind string // "memory" (default) or "redis"
+
+	RedisAddrs        []string
+	RedisClusterAddrs []string
+	RedisPassword     string
+
+	// HistorySize/HistoryTTL bound the replay buffer a reconnecting client
+	// uses to catch up on channel history it missed while disconnected.
+	HistorySize int
+	HistoryTTL  time.Duration
 }
 
 // DBConfig holds database connection details.
@@ -76,11 +237,135 @@ func Load(lookup EnvGetter) (*Config, error) {
 			Name:     get("NAVALLIST_DB_NAME", DefaultDBName),
 			SSLMode:  get("NAVALLIST_DB_SSLMODE", DefaultDBSSLMode),
 		},
+		Storage: StorageConfig{
+			Kind:                  get("NAVALLIST_STORAGE_KIND", DefaultStorageKind),
+			DiskBaseDir:           get("NAVALLIST_STORAGE_DIR", DefaultStorageDir),
+			S3Bucket:              getOptional("NAVALLIST_STORAGE_S3_BUCKET"),
+			S3Region:              getOptional("NAVALLIST_STORAGE_S3_REGION"),
+			S3Endpoint:            getOptional("NAVALLIST_STORAGE_S3_ENDPOINT"),
+			S3AccessKey:           getOptional("NAVALLIST_STORAGE_S3_ACCESS_KEY"),
+			S3SecretKey:           getOptional("NAVALLIST_STORAGE_S3_SECRET_KEY"),
+			S3SSEAlgorithm:        getOptional("NAVALLIST_STORAGE_S3_SSE_ALGORITHM"),
+			S3SSEKMSKeyID:         getOptional("NAVALLIST_STORAGE_S3_SSE_KMS_KEY_ID"),
+			GCSBucket:             getOptional("NAVALLIST_STORAGE_GCS_BUCKET"),
+			GCSSignServiceAccount: getOptional("NAVALLIST_STORAGE_GCS_SIGN_SERVICE_ACCOUNT"),
+			GCSSignPrivateKey:     getOptional("NAVALLIST_STORAGE_GCS_SIGN_PRIVATE_KEY"),
+			GCSKMSKeyName:         getOptional("NAVALLIST_STORAGE_GCS_KMS_KEY_NAME"),
+			GCSProjectID:          getOptional("NAVALLIST_STORAGE_GCS_PROJECT_ID"),
+			AzureAccountName:      getOptional("NAVALLIST_STORAGE_AZURE_ACCOUNT_NAME"),
+			AzureAccountKey:       getOptional("NAVALLIST_STORAGE_AZURE_ACCOUNT_KEY"),
+			AzureContainer:        getOptional("NAVALLIST_STORAGE_AZURE_CONTAINER"),
+			AzureEncryptionScope:  getOptional("NAVALLIST_STORAGE_AZURE_ENCRYPTION_SCOPE"),
+			WebDAVBaseURL:         getOptional("NAVALLIST_STORAGE_WEBDAV_URL"),
+			WebDAVUsername:        getOptional("NAVALLIST_STORAGE_WEBDAV_USER"),
+			WebDAVPassword:        getOptional("NAVALLIST_STORAGE_WEBDAV_PASS"),
+
+			ThumbnailCacheMaxBytes:         atoi64OrDefault(getOptional("NAVALLIST_STORAGE_THUMBNAIL_CACHE_MAX_BYTES"), DefaultThumbnailCacheMaxBytes),
+			ThumbnailCacheEvictionInterval: durationOrDefault(getOptional("NAVALLIST_STORAGE_THUMBNAIL_CACHE_EVICTION_INTERVAL"), DefaultThumbnailCacheEvictionInterval),
+		},
+		Session: SessionConfig{
+			Kind:           get("NAVALLIST_SESSION_KIND", DefaultSessionKind),
+			ValkeyAddr:     get("NAVALLIST_SESSION_VALKEY_ADDR", "localhost:6379"),
+			ValkeyPassword: getOptional("NAVALLIST_SESSION_VALKEY_PASS"),
+			ValkeyDB:       atoiOrDefault(getOptional("NAVALLIST_SESSION_VALKEY_DB"), 0),
+			ValkeyTTL:      durationOrDefault(getOptional("NAVALLIST_SESSION_VALKEY_TTL"), 24*time.Hour),
+		},
+		Mailer: MailerConfig{
+			Kind:            get("NAVALLIST_MAILER_KIND", DefaultMailerKind),
+			FromAddress:     get("NAVALLIST_MAILER_FROM", DefaultMailerFrom),
+			SMTPHost:        getOptional("NAVALLIST_MAILER_SMTP_HOST"),
+			SMTPPort:        get("NAVALLIST_MAILER_SMTP_PORT", "587"),
+			SMTPUsername:    getOptional("NAVALLIST_MAILER_SMTP_USER"),
+			SMTPPassword:    getOptional("NAVALLIST_MAILER_SMTP_PASS"),
+			VerificationTTL: durationOrDefault(getOptional("NAVALLIST_MAILER_VERIFICATION_TTL"), DefaultVerificationTTL),
+		},
+		Process: ProcessConfig{
+			RunAsUser:      getOptional("NAVALLIST_RUN_AS_USER"),
+			RunAsGroup:     getOptional("NAVALLIST_RUN_AS_GROUP"),
+			TLSCertFile:    getOptional("NAVALLIST_TLS_CERT_FILE"),
+			TLSKeyFile:     getOptional("NAVALLIST_TLS_KEY_FILE"),
+			AutoTLSDomains: splitAndTrim(getOptional("NAVALLIST_AUTOTLS_DOMAINS")),
+		},
+		Realtime: RealtimeConfig{
+			Kind:              get("NAVALLIST_RT_ENGINE", DefaultRTEngine),
+			RedisAddrs:        splitAndTrim(getOptional("NAVALLIST_RT_REDIS_ADDRS")),
+			RedisClusterAddrs: splitAndTrim(getOptional("NAVALLIST_RT_REDIS_CLUSTER_ADDRS")),
+			RedisPassword:     getOptional("NAVALLIST_RT_REDIS_PASS"),
+			HistorySize:       atoiOrDefault(getOptional("NAVALLIST_RT_HISTORY_SIZE"), DefaultRTHistorySize),
+			HistoryTTL:        durationOrDefault(getOptional("NAVALLIST_RT_HISTORY_TTL"), DefaultRTHistoryTTL),
+		},
+		Auth: AuthConfig{
+			// Empty Kind means no redirect provider is configured; the
+			// server still supports the email-code login flow on its own.
+			Kind:               getOptional("NAVALLIST_AUTH_KIND"),
+			ClientID:           getOptional("NAVALLIST_AUTH_CLIENT_ID"),
+			ClientSecret:       getOptional("NAVALLIST_AUTH_CLIENT_SECRET"),
+			RedirectURL:        get("NAVALLIST_AUTH_REDIRECT_URL", strings.TrimRight(siteURL, "/")+"/auth/callback"),
+			IssuerURL:          getOptional("NAVALLIST_AUTH_ISSUER_URL"),
+			Scopes:             splitAndTrim(getOptional("NAVALLIST_AUTH_SCOPES")),
+			LDAPHost:           getOptional("NAVALLIST_AUTH_LDAP_HOST"),
+			LDAPBindDNTemplate: getOptional("NAVALLIST_AUTH_LDAP_BIND_DN_TEMPLATE"),
+			LDAPUseTLS:         get("NAVALLIST_AUTH_LDAP_TLS", "false") == "true",
+			KeystoneAuthURL:    getOptional("NAVALLIST_AUTH_KEYSTONE_URL"),
+			KeystoneDomain:     get("NAVALLIST_AUTH_KEYSTONE_DOMAIN", "Default"),
+			SessionSecret:      []byte(get("NAVALLIST_AUTH_SESSION_SECRET", "insecure-dev-session-secret")),
+			SessionCookieTTL:   durationOrDefault(getOptional("NAVALLIST_AUTH_SESSION_COOKIE_TTL"), DefaultSessionCookieTTL),
+			RefreshTokenTTL:    durationOrDefault(getOptional("NAVALLIST_AUTH_REFRESH_TOKEN_TTL"), DefaultRefreshTokenTTL),
+		},
 	}
 
 	return cfg, nil
 }
 
+func atoiOrDefault(s string, fallback int) int {
+	if s == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func atoi64OrDefault(s string, fallback int64) int64 {
+	if s == "" {
+		return fallback
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// splitAndTrim parses a comma-separated list like "a.com, b.com" into
+// []string{"a.com", "b.com"}, returning nil for an empty input.
+func splitAndTrim(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func durationOrDefault(s string, fallback time.Duration) time.Duration {
+	if s == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
 // DSN constructs the PostgreSQL Data Source Name.
 func (db DBConfig) DSN() string {
 	q := make(url.Values)
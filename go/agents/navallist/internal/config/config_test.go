@@ -1,6 +1,7 @@
 package config
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -49,14 +50,28 @@ func TestLoad(t *testing.T) {
 				if cfg.DB.User != "navallist_user" {
 					t.Errorf("Default DB User = %v, want navallist_user", cfg.DB.User)
 				}
+				if cfg.Mailer.Kind != "dev" {
+					t.Errorf("Default Mailer Kind = %v, want dev", cfg.Mailer.Kind)
+				}
+				if cfg.Auth.Kind != "" {
+					t.Errorf("Default Auth Kind = %v, want empty (no redirect provider configured)", cfg.Auth.Kind)
+				}
+				if cfg.Realtime.Kind != "memory" {
+					t.Errorf("Default Realtime Kind = %v, want memory", cfg.Realtime.Kind)
+				}
+				if cfg.Realtime.HistorySize != DefaultRTHistorySize {
+					t.Errorf("Default Realtime HistorySize = %v, want %v", cfg.Realtime.HistorySize, DefaultRTHistorySize)
+				}
 			},
 		},
 		{
 			name: "Env Overrides",
 			env: map[string]string{
-				"NAVALLIST_PORT":      "9090",
-				"NAVALLIST_DB_USER":   "test_user",
-				"NAVALLIST_OA_CLIENT": "client_id",
+				"NAVALLIST_PORT":           "9090",
+				"NAVALLIST_DB_USER":        "test_user",
+				"NAVALLIST_OA_CLIENT":      "client_id",
+				"NAVALLIST_RT_ENGINE":      "redis",
+				"NAVALLIST_RT_REDIS_ADDRS": "redis-a:6379, redis-b:6379",
 			},
 			validate: func(t *testing.T, cfg *Config) {
 				if cfg.Port != "9090" {
@@ -65,6 +80,12 @@ func TestLoad(t *testing.T) {
 				if cfg.DB.User != "test_user" {
 					t.Errorf("DB User = %v, want test_user", cfg.DB.User)
 				}
+				if cfg.Realtime.Kind != "redis" {
+					t.Errorf("Realtime Kind = %v, want redis", cfg.Realtime.Kind)
+				}
+				if want := []string{"redis-a:6379", "redis-b:6379"}; !slices.Equal(cfg.Realtime.RedisAddrs, want) {
+					t.Errorf("Realtime RedisAddrs = %v, want %v", cfg.Realtime.RedisAddrs, want)
+				}
 			},
 		},
 	}
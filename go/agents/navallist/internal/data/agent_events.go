@@ -0,0 +1,53 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"navallist/internal/data/models"
+)
+
+// RecordAgentEvent persists one agent tool-call invocation - recorded by
+// the recordToolCall middleware in internal/agent around each
+// functiontool handler - for GET /api/trips/{id}/timeline and
+// POST /api/trips/{id}/replay.
+func (s *SQLStore) RecordAgentEvent(ctx context.Context, event models.AgentEvent) (*models.AgentEvent, error) {
+	query := `
+		INSERT INTO agent_event (trip_id, session_id, user_id, tool_name, args_json, result_json, status, error_class, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, trip_id, session_id, user_id, tool_name, args_json, result_json, status, error_class, duration_ms, created_at
+	`
+	var recorded models.AgentEvent
+	err := s.db.QueryRowxContext(ctx, query,
+		event.TripID, event.SessionID, event.UserID, event.ToolName,
+		event.ArgsJSON, event.ResultJSON, event.Status, event.ErrorClass, event.DurationMS,
+	).StructScan(&recorded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record agent event: %w", err)
+	}
+	return &recorded, nil
+}
+
+// GetAgentEvents returns tripID's recorded agent tool-call events in
+// creation order, optionally stopping at upToEventID (0 means "all") so a
+// what-if replay can reconstruct state as of a specific tool call instead
+// of the whole trip's history.
+func (s *SQLStore) GetAgentEvents(ctx context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error) {
+	query := `
+		SELECT id, trip_id, session_id, user_id, tool_name, args_json, result_json, status, error_class, duration_ms, created_at
+		FROM agent_event
+		WHERE trip_id = $1 AND ($2 = 0 OR id <= $2)
+		ORDER BY id
+	`
+	var events []models.AgentEvent
+	err := s.db.SelectContext(ctx, &events, query, tripID, upToEventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch agent events: %w", err)
+	}
+	return events, nil
+}
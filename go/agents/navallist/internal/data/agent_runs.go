@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"navallist/internal/data/models"
+)
+
+// CreateAgentRun starts a row for a newly opened agent stream
+// (GET /api/trips/{id}/agent/stream), returning the run the handler appends
+// AgentRunEvents to as it proxies events to the browser.
+func (s *SQLStore) CreateAgentRun(ctx context.Context, tripID, userID, sessionID, transport string) (*models.AgentRun, error) {
+	query := `
+		INSERT INTO agent_run (trip_id, user_id, session_id, transport)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, trip_id, user_id, session_id, transport, status, error, created_at, ended_at
+	`
+	var run models.AgentRun
+	err := s.db.QueryRowxContext(ctx, query, tripID, userID, sessionID, transport).StructScan(&run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent run: %w", err)
+	}
+	return &run, nil
+}
+
+// AppendAgentRunEvent records one SSE event emitted during runID at seq, so
+// a client that reconnects with Last-Event-ID can resume via
+// GetAgentRunEventsSince instead of re-running the agent.
+func (s *SQLStore) AppendAgentRunEvent(ctx context.Context, runID string, seq int64, payload []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO agent_run_event (run_id, seq, payload)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (run_id, seq) DO NOTHING
+	`, runID, seq, payload)
+	if err != nil {
+		return fmt.Errorf("failed to append agent run event: %w", err)
+	}
+	return nil
+}
+
+// FinishAgentRun marks runID as done, recording runErr's message (if any)
+// and the status it implies.
+func (s *SQLStore) FinishAgentRun(ctx context.Context, runID string, runErr error) error {
+	status := "complete"
+	var errMsg *string
+	if runErr != nil {
+		status = "error"
+		if errors.Is(runErr, context.Canceled) {
+			status = "cancelled"
+		}
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE agent_run SET status = $1, error = $2, ended_at = $3 WHERE id = $4
+	`, status, errMsg, now, runID)
+	if err != nil {
+		return fmt.Errorf("failed to finish agent run: %w", err)
+	}
+	return nil
+}
+
+// GetAgentRunEventsSince returns runID's events with seq greater than
+// sinceSeq, in order, so a resumed stream can replay what it missed before
+// continuing live.
+func (s *SQLStore) GetAgentRunEventsSince(ctx context.Context, runID string, sinceSeq int64) ([]models.AgentRunEvent, error) {
+	var events []models.AgentRunEvent
+	err := s.db.SelectContext(ctx, &events, `
+		SELECT run_id, seq, payload, created_at FROM agent_run_event
+		WHERE run_id = $1 AND seq > $2
+		ORDER BY seq
+	`, runID, sinceSeq)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch agent run events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,143 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"navallist/internal/imaging"
+
+	"github.com/charmbracelet/log"
+)
+
+// allowedThumbnailSizes bounds GetArtifactSized to a fixed set of sizes, so
+// a client can't make this server decode-and-resize (and cache) an
+// unbounded number of distinct derivatives of the same photo just by
+// varying the size query parameter.
+var allowedThumbnailSizes = map[int]bool{128: true, 512: true, 1024: true}
+
+// thumbnailCachePrefix namespaces derived-image blobs in BlobStorage apart
+// from the original artifacts they're generated from.
+const thumbnailCachePrefix = "cache/"
+
+// GetArtifactSized returns artifact id resized to fit size on its longest
+// edge, generating and caching it in Storage on first request for that
+// (id, size) pair; subsequent requests return the cached bytes directly.
+func (s *SQLStore) GetArtifactSized(ctx context.Context, id string, size int) ([]byte, string, error) {
+	if !allowedThumbnailSizes[size] {
+		return nil, "", fmt.Errorf("thumbnail cache: unsupported size %d", size)
+	}
+	if s.Storage == nil {
+		return nil, "", fmt.Errorf("thumbnail cache: no storage backend configured")
+	}
+
+	art, err := s.GetArtifactByID(ctx, id)
+	if err != nil {
+		return nil, "", ErrNotFound
+	}
+
+	cachePath := thumbnailCachePath(id, size)
+	if cached, err := s.Storage.Load(ctx, cachePath); err == nil {
+		if err := s.touchThumbnailCache(ctx, id, size); err != nil {
+			log.Error("Failed to bump thumbnail cache last-accessed time", "artifact_id", id, "size", size, "error", err)
+		}
+		return cached, "image/jpeg", nil
+	}
+
+	original, err := s.Storage.Load(ctx, art.StoragePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("thumbnail cache: failed to load original artifact: %w", err)
+	}
+
+	resized, err := imaging.Resize(original, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("thumbnail cache: failed to resize artifact: %w", err)
+	}
+
+	if _, err := s.Storage.Save(ctx, cachePath, resized, "image/jpeg"); err != nil {
+		return nil, "", fmt.Errorf("thumbnail cache: failed to save derived image: %w", err)
+	}
+	if err := s.recordThumbnailCache(ctx, id, size, cachePath, len(resized)); err != nil {
+		return nil, "", fmt.Errorf("thumbnail cache: failed to record cache entry: %w", err)
+	}
+
+	return resized, "image/jpeg", nil
+}
+
+// StartThumbnailCacheEviction launches a goroutine that, every interval,
+// deletes the least-recently-accessed thumbnail cache entries once their
+// total size exceeds maxBytes. It runs until ctx is cancelled.
+func (s *SQLStore) StartThumbnailCacheEviction(ctx context.Context, maxBytes int64, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.evictThumbnailCache(ctx, maxBytes); err != nil {
+					log.Error("Failed to evict thumbnail cache", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *SQLStore) evictThumbnailCache(ctx context.Context, maxBytes int64) error {
+	var total int64
+	if err := s.db.GetContext(ctx, &total, `SELECT COALESCE(SUM(byte_size), 0) FROM artifact_thumbnail`); err != nil {
+		return fmt.Errorf("failed to sum thumbnail cache size: %w", err)
+	}
+	if total <= maxBytes {
+		return nil
+	}
+
+	type cacheRow struct {
+		ArtifactID  string `db:"artifact_id"`
+		Size        int    `db:"size"`
+		StoragePath string `db:"storage_path"`
+		ByteSize    int64  `db:"byte_size"`
+	}
+	var rows []cacheRow
+	query := `SELECT artifact_id, size, storage_path, byte_size FROM artifact_thumbnail ORDER BY last_accessed_at ASC`
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return fmt.Errorf("failed to list thumbnail cache entries: %w", err)
+	}
+
+	for _, r := range rows {
+		if total <= maxBytes {
+			break
+		}
+		if err := s.Storage.Delete(ctx, r.StoragePath); err != nil {
+			log.Error("Failed to delete evicted thumbnail blob", "artifact_id", r.ArtifactID, "size", r.Size, "error", err)
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM artifact_thumbnail WHERE artifact_id = $1 AND size = $2`, r.ArtifactID, r.Size); err != nil {
+			log.Error("Failed to delete thumbnail cache record", "artifact_id", r.ArtifactID, "size", r.Size, "error", err)
+			continue
+		}
+		total -= r.ByteSize
+	}
+	return nil
+}
+
+func thumbnailCachePath(artifactID string, size int) string {
+	return fmt.Sprintf("%s%s_%d", thumbnailCachePrefix, artifactID, size)
+}
+
+func (s *SQLStore) recordThumbnailCache(ctx context.Context, artifactID string, size int, storagePath string, byteSize int) error {
+	query := `
+		INSERT INTO artifact_thumbnail (artifact_id, size, storage_path, byte_size, last_accessed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (artifact_id, size) DO UPDATE SET storage_path = $3, byte_size = $4, last_accessed_at = $5
+	`
+	_, err := s.db.ExecContext(ctx, query, artifactID, size, storagePath, byteSize, time.Now())
+	return err
+}
+
+func (s *SQLStore) touchThumbnailCache(ctx context.Context, artifactID string, size int) error {
+	query := `UPDATE artifact_thumbnail SET last_accessed_at = $1 WHERE artifact_id = $2 AND size = $3`
+	_, err := s.db.ExecContext(ctx, query, time.Now(), artifactID, size)
+	return err
+}
@@ -0,0 +1,163 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"navallist/internal/data/models"
+)
+
+// maxTripUploadBytes caps how much finalized upload data a single trip can
+// accumulate across every resumable upload session, enforced transactionally
+// by FinalizeUpload.
+const maxTripUploadBytes = 500 * 1024 * 1024 // 500MB
+
+// ErrUploadQuotaExceeded is returned by FinalizeUpload when completing the
+// session would push the trip over maxTripUploadBytes.
+var ErrUploadQuotaExceeded = errors.New("trip upload quota exceeded")
+
+// ErrUploadIncomplete is returned by FinalizeUpload when the session hasn't
+// received every byte of its declared total size yet.
+var ErrUploadIncomplete = errors.New("upload is not yet complete")
+
+// CreateUploadSession starts a new tus-style resumable upload for a photo of
+// the given declared size, returning the session AppendUploadChunk/
+// FinalizeUpload act on.
+func (s *SQLStore) CreateUploadSession(ctx context.Context, tripID, itemName, filename, mimeType string, totalSize int64) (*models.ArtifactUpload, error) {
+	query := `
+		INSERT INTO artifact_uploads (trip_id, item_name, filename, mime_type, total_size, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $6)
+		RETURNING id, trip_id, item_name, filename, mime_type, total_size, received_size, data, status, created_at, updated_at
+	`
+	var u models.ArtifactUpload
+	now := time.Now()
+	err := s.db.QueryRowxContext(ctx, query, tripID, itemName, filename, mimeType, totalSize, now).StructScan(&u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return &u, nil
+}
+
+// GetUploadSession retrieves an upload session by ID, returning ErrNotFound
+// if it doesn't exist.
+func (s *SQLStore) GetUploadSession(ctx context.Context, uploadID string) (*models.ArtifactUpload, error) {
+	query := `
+		SELECT id, trip_id, item_name, filename, mime_type, total_size, received_size, data, status, created_at, updated_at
+		FROM artifact_uploads WHERE id = $1
+	`
+	var u models.ArtifactUpload
+	err := s.db.GetContext(ctx, &u, query, uploadID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+	return &u, nil
+}
+
+// AppendUploadChunk appends chunk to an in-progress upload session and
+// advances its received size, returning the session's state after the
+// append. The row is locked for the duration of the append so concurrent
+// PATCH requests against the same session can't interleave and corrupt it.
+func (s *SQLStore) AppendUploadChunk(ctx context.Context, uploadID string, chunk []byte) (*models.ArtifactUpload, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var u models.ArtifactUpload
+	query := `
+		SELECT id, trip_id, item_name, filename, mime_type, total_size, received_size, data, status, created_at, updated_at
+		FROM artifact_uploads WHERE id = $1 FOR UPDATE
+	`
+	if err := tx.GetContext(ctx, &u, query, uploadID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock upload session: %w", err)
+	}
+
+	if u.Status != "pending" {
+		return nil, fmt.Errorf("upload session %s is already %s", uploadID, u.Status)
+	}
+
+	now := time.Now()
+	u.Data = append(u.Data, chunk...)
+	u.ReceivedSize += int64(len(chunk))
+	u.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE artifact_uploads SET data = $1, received_size = $2, updated_at = $3 WHERE id = $4`,
+		u.Data, u.ReceivedSize, now, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to append upload chunk: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit upload chunk: %w", err)
+	}
+	return &u, nil
+}
+
+// FinalizeUpload marks a fully-received session complete, enforcing
+// maxTripUploadBytes for the trip. Calling it again on an already-complete
+// session is a no-op that just returns the session.
+func (s *SQLStore) FinalizeUpload(ctx context.Context, uploadID string) (*models.ArtifactUpload, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var u models.ArtifactUpload
+	query := `
+		SELECT id, trip_id, item_name, filename, mime_type, total_size, received_size, data, status, created_at, updated_at
+		FROM artifact_uploads WHERE id = $1 FOR UPDATE
+	`
+	if err := tx.GetContext(ctx, &u, query, uploadID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock upload session: %w", err)
+	}
+
+	if u.Status == "complete" {
+		return &u, nil
+	}
+	if u.ReceivedSize != u.TotalSize {
+		return nil, ErrUploadIncomplete
+	}
+
+	// Lock the trip row so two sessions for the same trip can't both pass
+	// the quota check against a stale usage total.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM trip WHERE id = $1 FOR UPDATE`, u.TripID); err != nil {
+		return nil, fmt.Errorf("failed to lock trip: %w", err)
+	}
+
+	var usage int64
+	err = tx.GetContext(ctx, &usage,
+		`SELECT COALESCE(SUM(total_size), 0) FROM artifact_uploads WHERE trip_id = $1 AND status = 'complete'`, u.TripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute trip upload usage: %w", err)
+	}
+	if usage+u.TotalSize > maxTripUploadBytes {
+		return nil, ErrUploadQuotaExceeded
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE artifact_uploads SET status = 'complete', updated_at = $1 WHERE id = $2`, now, uploadID); err != nil {
+		return nil, fmt.Errorf("failed to finalize upload: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit finalize: %w", err)
+	}
+
+	u.Status = "complete"
+	u.UpdatedAt = now
+	return &u, nil
+}
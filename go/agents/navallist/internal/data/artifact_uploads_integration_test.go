@@ -0,0 +1,111 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestUploadSessionLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifact_uploads", "trip", "users")
+	defer cleanupData(t, db, "artifact_uploads", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_upload", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	session, err := store.CreateUploadSession(ctx, trip.ID, "item1", "video.mp4", "video/mp4", 10)
+	if err != nil {
+		t.Fatalf("CreateUploadSession failed: %v", err)
+	}
+	if session.ReceivedSize != 0 || session.Status != "pending" {
+		t.Errorf("Expected a fresh pending session, got %+v", session)
+	}
+
+	fetched, err := store.GetUploadSession(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetUploadSession failed: %v", err)
+	}
+	if fetched.Filename != "video.mp4" {
+		t.Errorf("Expected filename video.mp4, got %s", fetched.Filename)
+	}
+
+	if _, err := store.FinalizeUpload(ctx, session.ID); !errors.Is(err, ErrUploadIncomplete) {
+		t.Errorf("Expected ErrUploadIncomplete before all chunks arrive, got %v", err)
+	}
+
+	if _, err := store.AppendUploadChunk(ctx, session.ID, []byte("12345")); err != nil {
+		t.Fatalf("AppendUploadChunk failed: %v", err)
+	}
+	after, err := store.AppendUploadChunk(ctx, session.ID, []byte("67890"))
+	if err != nil {
+		t.Fatalf("AppendUploadChunk failed: %v", err)
+	}
+	if after.ReceivedSize != 10 || string(after.Data) != "1234567890" {
+		t.Fatalf("Expected fully received session, got %+v", after)
+	}
+
+	finalized, err := store.FinalizeUpload(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("FinalizeUpload failed: %v", err)
+	}
+	if finalized.Status != "complete" {
+		t.Errorf("Expected status complete, got %s", finalized.Status)
+	}
+
+	// Finalizing again is a no-op rather than an error.
+	if _, err := store.FinalizeUpload(ctx, session.ID); err != nil {
+		t.Errorf("Expected re-finalizing a complete session to succeed, got %v", err)
+	}
+
+	if _, err := store.GetUploadSession(ctx, "no-such-session"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFinalizeUpload_EnforcesTripQuota(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifact_uploads", "trip", "users")
+	defer cleanupData(t, db, "artifact_uploads", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_upload_quota", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	oversized, err := store.CreateUploadSession(ctx, trip.ID, "item1", "huge.mp4", "video/mp4", maxTripUploadBytes+1)
+	if err != nil {
+		t.Fatalf("CreateUploadSession failed: %v", err)
+	}
+	if _, err := store.AppendUploadChunk(ctx, oversized.ID, make([]byte, maxTripUploadBytes+1)); err != nil {
+		t.Fatalf("AppendUploadChunk failed: %v", err)
+	}
+
+	if _, err := store.FinalizeUpload(ctx, oversized.ID); !errors.Is(err, ErrUploadQuotaExceeded) {
+		t.Errorf("Expected ErrUploadQuotaExceeded, got %v", err)
+	}
+}
@@ -2,30 +2,85 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"navallist/internal/data/models"
 )
 
-// CreateArtifact saves artifact metadata to the database.
+// CreateArtifact saves artifact metadata to the database for a file this
+// server wrote the bytes for itself, so it's immediately "confirmed".
 func (s *SQLStore) CreateArtifact(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error) {
+	return s.insertArtifact(ctx, tripID, filename, mimeType, storagePath, "confirmed")
+}
+
+// CreateExternalArtifact records an artifact a client is about to upload
+// directly to the storage backend via a PresignUploader-minted URL (see
+// POST /api/artifacts/presign). It starts "pending" since this server
+// never sees the bytes; internal/storagereconciler later confirms or
+// orphans it based on whether the object actually shows up.
+func (s *SQLStore) CreateExternalArtifact(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error) {
+	return s.insertArtifact(ctx, tripID, filename, mimeType, storagePath, "pending")
+}
+
+func (s *SQLStore) insertArtifact(ctx context.Context, tripID, filename, mimeType, storagePath, status string) (*models.Artifact, error) {
 	query := `
-		INSERT INTO artifact (trip_id, filename, mime_type, storage_path, created_at)
-		VALUES (NULLIF($1, ''), $2, $3, $4, $5)
-		RETURNING id, trip_id, filename, mime_type, storage_path, created_at
+		INSERT INTO artifact (trip_id, filename, mime_type, storage_path, status, created_at)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6)
+		RETURNING id, trip_id, filename, mime_type, storage_path, status, created_at
 	`
 	var a models.Artifact
-	err := s.db.QueryRowxContext(ctx, query, tripID, filename, mimeType, storagePath, time.Now()).StructScan(&a)
+	err := s.db.QueryRowxContext(ctx, query, tripID, filename, mimeType, storagePath, status, time.Now()).StructScan(&a)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create artifact: %w", err)
 	}
 	return &a, nil
 }
 
+// ConfirmArtifact marks a pending artifact "confirmed" once
+// internal/storagereconciler verifies the client's direct upload landed.
+func (s *SQLStore) ConfirmArtifact(ctx context.Context, id string) (*models.Artifact, error) {
+	query := `
+		UPDATE artifact SET status = 'confirmed' WHERE id = $1
+		RETURNING id, trip_id, filename, mime_type, storage_path, status, created_at
+	`
+	var a models.Artifact
+	if err := s.db.QueryRowxContext(ctx, query, id).StructScan(&a); err != nil {
+		return nil, fmt.Errorf("failed to confirm artifact: %w", err)
+	}
+	return &a, nil
+}
+
+// MarkArtifactOrphan flags a pending artifact "orphan" once
+// internal/storagereconciler gives up waiting for the client's direct
+// upload to land.
+func (s *SQLStore) MarkArtifactOrphan(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE artifact SET status = 'orphan' WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to mark artifact orphan: %w", err)
+	}
+	return nil
+}
+
+// ListPendingArtifacts returns every artifact still "pending" a direct
+// upload that was created before olderThan, for
+// internal/storagereconciler's sweep to check for existence.
+func (s *SQLStore) ListPendingArtifacts(ctx context.Context, olderThan time.Time) ([]models.Artifact, error) {
+	query := `
+		SELECT id, trip_id, checklist_item_id, filename, mime_type, storage_path, status, created_at
+		FROM artifact WHERE status = 'pending' AND created_at < $1
+	`
+	var artifacts []models.Artifact
+	if err := s.db.SelectContext(ctx, &artifacts, query, olderThan); err != nil {
+		return nil, fmt.Errorf("failed to list pending artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
 // GetArtifact retrieves artifact metadata by filename.
 func (s *SQLStore) GetArtifact(ctx context.Context, filename string) (*models.Artifact, error) {
-	query := `SELECT id, trip_id, filename, mime_type, storage_path, created_at FROM artifact WHERE filename = $1 LIMIT 1`
+	query := `SELECT id, trip_id, filename, mime_type, storage_path, status, created_at FROM artifact WHERE filename = $1 LIMIT 1`
 	var a models.Artifact
 	err := s.db.GetContext(ctx, &a, query, filename)
 	if err != nil {
@@ -36,7 +91,7 @@ func (s *SQLStore) GetArtifact(ctx context.Context, filename string) (*models.Ar
 
 // GetArtifactByID retrieves artifact metadata by ID.
 func (s *SQLStore) GetArtifactByID(ctx context.Context, id string) (*models.Artifact, error) {
-	query := `SELECT id, trip_id, filename, mime_type, storage_path, created_at FROM artifact WHERE id = $1 LIMIT 1`
+	query := `SELECT id, trip_id, filename, mime_type, storage_path, status, created_at FROM artifact WHERE id = $1 LIMIT 1`
 	var a models.Artifact
 	err := s.db.GetContext(ctx, &a, query, id)
 	if err != nil {
@@ -44,3 +99,76 @@ func (s *SQLStore) GetArtifactByID(ctx context.Context, id string) (*models.Arti
 	}
 	return &a, nil
 }
+
+// ListArtifactsByTrip retrieves every artifact belonging to a trip, whether
+// or not it has been linked to a checklist item yet.
+func (s *SQLStore) ListArtifactsByTrip(ctx context.Context, tripID string) ([]models.Artifact, error) {
+	query := `SELECT id, trip_id, checklist_item_id, filename, mime_type, storage_path, status, created_at FROM artifact WHERE trip_id = $1`
+	var artifacts []models.Artifact
+	if err := s.db.SelectContext(ctx, &artifacts, query, tripID); err != nil {
+		return nil, fmt.Errorf("failed to list artifacts for trip: %w", err)
+	}
+	return artifacts, nil
+}
+
+// SaveArtifactExif persists the EXIF-derived metadata and hashes extracted
+// from an upload, linked to the artifact record CreateArtifact already
+// wrote. See imaging.Normalize for why this is the only place GPS data
+// survives.
+func (s *SQLStore) SaveArtifactExif(ctx context.Context, exif models.ArtifactExif) error {
+	query := `
+		INSERT INTO artifacts_exif (artifact_id, content_hash, phash, gps_lat, gps_lon, captured_at, orientation, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		exif.ArtifactID, exif.ContentHash, exif.PHash, exif.GPSLat, exif.GPSLon, exif.CapturedAt, exif.Orientation, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save artifact exif: %w", err)
+	}
+	return nil
+}
+
+// FindArtifactByHash looks up an artifact by its original upload's SHA-256
+// content hash, letting callers dedupe a re-upload of a byte-identical
+// photo onto the existing artifact instead of storing it again.
+func (s *SQLStore) FindArtifactByHash(ctx context.Context, contentHash string) (*models.Artifact, error) {
+	query := `
+		SELECT a.id, a.trip_id, a.checklist_item_id, a.filename, a.mime_type, a.storage_path, a.status, a.created_at
+		FROM artifact a
+		JOIN artifacts_exif e ON e.artifact_id = a.id
+		WHERE e.content_hash = $1
+		LIMIT 1
+	`
+	var a models.Artifact
+	err := s.db.GetContext(ctx, &a, query, contentHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find artifact by hash: %w", err)
+	}
+	return &a, nil
+}
+
+// FindArtifactsNear returns every artifact on tripID with a GPS tag within
+// radiusMeters of (lat, lon), using the spherical law of cosines over the
+// artifacts_exif geotags.
+func (s *SQLStore) FindArtifactsNear(ctx context.Context, tripID string, lat, lon, radiusMeters float64) ([]models.Artifact, error) {
+	query := `
+		SELECT a.id, a.trip_id, a.checklist_item_id, a.filename, a.mime_type, a.storage_path, a.status, a.created_at
+		FROM artifact a
+		JOIN artifacts_exif e ON e.artifact_id = a.id
+		WHERE a.trip_id = $1
+		  AND e.gps_lat IS NOT NULL AND e.gps_lon IS NOT NULL
+		  AND 6371000 * acos(
+		        LEAST(1.0, cos(radians($2)) * cos(radians(e.gps_lat)) * cos(radians(e.gps_lon) - radians($3))
+		                 + sin(radians($2)) * sin(radians(e.gps_lat)))
+		      ) <= $4
+		ORDER BY a.created_at DESC
+	`
+	var artifacts []models.Artifact
+	if err := s.db.SelectContext(ctx, &artifacts, query, tripID, lat, lon, radiusMeters); err != nil {
+		return nil, fmt.Errorf("failed to find artifacts near point: %w", err)
+	}
+	return artifacts, nil
+}
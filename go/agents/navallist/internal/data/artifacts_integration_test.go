@@ -3,9 +3,16 @@
 package data
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
 	"testing"
 
+	"navallist/internal/data/models"
+
 	"github.com/charmbracelet/log"
 )
 
@@ -94,3 +101,152 @@ func TestArtifactOperations(t *testing.T) {
 		}
 	})
 }
+
+func TestArtifactExifAndGeoLookup(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifacts_exif", "artifact", "trip", "users")
+	defer cleanupData(t, db, "artifacts_exif", "artifact", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_artifact_exif", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	artifact, err := store.CreateArtifact(ctx, trip.ID, "geotagged.jpg", "image/jpeg", "/tmp/geotagged.jpg")
+	if err != nil {
+		t.Fatalf("CreateArtifact failed: %v", err)
+	}
+
+	lat, lon := 37.8199, -122.4783 // Golden Gate Bridge
+	phash := int64(-42)
+	if err := store.SaveArtifactExif(ctx, models.ArtifactExif{
+		ArtifactID:  artifact.ID,
+		ContentHash: "deadbeef",
+		PHash:       &phash,
+		GPSLat:      &lat,
+		GPSLon:      &lon,
+		Orientation: 6,
+	}); err != nil {
+		t.Fatalf("SaveArtifactExif failed: %v", err)
+	}
+
+	t.Run("FindArtifactByHash", func(t *testing.T) {
+		found, err := store.FindArtifactByHash(ctx, "deadbeef")
+		if err != nil {
+			t.Fatalf("FindArtifactByHash failed: %v", err)
+		}
+		if found.ID != artifact.ID {
+			t.Errorf("Expected artifact %s, got %s", artifact.ID, found.ID)
+		}
+
+		if _, err := store.FindArtifactByHash(ctx, "unknown-hash"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindArtifactsNear", func(t *testing.T) {
+		nearby, err := store.FindArtifactsNear(ctx, trip.ID, lat, lon, 100)
+		if err != nil {
+			t.Fatalf("FindArtifactsNear failed: %v", err)
+		}
+		if len(nearby) != 1 || nearby[0].ID != artifact.ID {
+			t.Errorf("Expected to find artifact %s nearby, got %v", artifact.ID, nearby)
+		}
+
+		// Tokyo is nowhere near the Golden Gate Bridge.
+		far, err := store.FindArtifactsNear(ctx, trip.ID, 35.6762, 139.6503, 100)
+		if err != nil {
+			t.Fatalf("FindArtifactsNear failed: %v", err)
+		}
+		if len(far) != 0 {
+			t.Errorf("Expected no artifacts near Tokyo, got %v", far)
+		}
+	})
+}
+
+func TestGetArtifactSized(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	store.Storage = NewDiskStorage(t.TempDir())
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifact_thumbnail", "artifact", "trip", "users")
+	defer cleanupData(t, db, "artifact_thumbnail", "artifact", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_artifact_sized", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode test jpeg: %v", err)
+	}
+
+	if _, err := store.Storage.Save(ctx, "originals/photo.jpg", buf.Bytes(), "image/jpeg"); err != nil {
+		t.Fatalf("Failed to save original artifact: %v", err)
+	}
+	artifact, err := store.CreateArtifact(ctx, trip.ID, "photo.jpg", "image/jpeg", "originals/photo.jpg")
+	if err != nil {
+		t.Fatalf("CreateArtifact failed: %v", err)
+	}
+
+	t.Run("GeneratesAndCachesThumbnail", func(t *testing.T) {
+		data, contentType, err := store.GetArtifactSized(ctx, artifact.ID, 128)
+		if err != nil {
+			t.Fatalf("GetArtifactSized failed: %v", err)
+		}
+		if contentType != "image/jpeg" {
+			t.Errorf("Expected content type image/jpeg, got %s", contentType)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to decode thumbnail: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 128 {
+			t.Errorf("Expected thumbnail width 128, got %d", b.Dx())
+		}
+
+		// Second request should hit the cache and return identical bytes.
+		cached, _, err := store.GetArtifactSized(ctx, artifact.ID, 128)
+		if err != nil {
+			t.Fatalf("GetArtifactSized (cached) failed: %v", err)
+		}
+		if !bytes.Equal(cached, data) {
+			t.Error("Expected cached thumbnail bytes to match the originally generated ones")
+		}
+	})
+
+	t.Run("RejectsUnlistedSize", func(t *testing.T) {
+		if _, _, err := store.GetArtifactSized(ctx, artifact.ID, 999); err == nil {
+			t.Error("Expected an error for a size not in the allow-list")
+		}
+	})
+
+	t.Run("ReturnsNotFoundForMissingArtifact", func(t *testing.T) {
+		if _, _, err := store.GetArtifactSized(ctx, "nonexistent-id", 128); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
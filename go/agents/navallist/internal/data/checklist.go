@@ -9,77 +9,98 @@ import (
 	"navallist/internal/data/models"
 
 	"github.com/charmbracelet/log"
+	"github.com/jmoiron/sqlx"
 )
 
-// UpdateItem updates a specific item's status and details, using an UPSERT to prevent duplicates.
-func (s *SQLStore) UpdateItem(ctx context.Context, tripID string, itemName string, isChecked bool, location string, _ string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error) {
-	// Determine the user to record.
-	var userToRecord *string
-	var nameToRecord *string
-
-	if isChecked {
-		userToRecord = userID
-		if completedByName != "" {
-			nameToRecord = &completedByName
-		}
-	} else {
-		userToRecord = nil
-		nameToRecord = nil
-	}
-
-	// We use INSERT ... ON CONFLICT to ensure atomicity and prevent race conditions creating duplicates.
-	// Note: 'name' must be exactly the same for conflict detection (Postgres unique constraint is case-sensitive by default).
-	// If items are pre-seeded, we should use their exact names.
+// sqlExecutor is satisfied by both *sqlx.DB and *sqlx.Tx, letting
+// upsertChecklistItem run standalone (UpdateItem) or as one of several
+// writes inside a larger transaction (ApplyMutations).
+type sqlExecutor interface {
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+}
 
+// upsertChecklistItem is the shared UPSERT powering both UpdateItem and
+// ApplyMutations. is_checked/location_text/completed_by_* overwrite
+// unconditionally (last-writer-wins); assigned_to_* only overwrite when
+// non-nil, so a write that doesn't touch assignment doesn't clear it.
+// version increments on every write, backing the sync API's conflict
+// resolution and change feed.
+//
+// We use INSERT ... ON CONFLICT to ensure atomicity and prevent race
+// conditions creating duplicates. Note: 'name' must be exactly the same
+// for conflict detection (Postgres unique constraint is case-sensitive by
+// default). If items are pre-seeded, we should use their exact names.
+func upsertChecklistItem(ctx context.Context, ex sqlExecutor, tripID, itemName string, isChecked bool, location string, userToRecord, nameToRecord, assignedToUserID, assignedToName *string) (*models.ChecklistItem, error) {
 	upsertQuery := `
-		INSERT INTO checklist_item (trip_id, category, name, is_checked, location_text, completed_by_user_id, completed_by_name, assigned_to_user_id, assigned_to_name, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (trip_id, name) 
-		DO UPDATE SET 
+		INSERT INTO checklist_item (trip_id, category, name, is_checked, location_text, completed_by_user_id, completed_by_name, assigned_to_user_id, assigned_to_name, version, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1, $10)
+		ON CONFLICT (trip_id, name)
+		DO UPDATE SET
 			is_checked = EXCLUDED.is_checked,
-			location_text = CASE 
-				WHEN EXCLUDED.location_text IS NOT NULL AND EXCLUDED.location_text <> '' THEN EXCLUDED.location_text 
-				ELSE checklist_item.location_text 
+			location_text = CASE
+				WHEN EXCLUDED.location_text IS NOT NULL AND EXCLUDED.location_text <> '' THEN EXCLUDED.location_text
+				ELSE checklist_item.location_text
 			END,
 			completed_by_user_id = EXCLUDED.completed_by_user_id,
 			completed_by_name = EXCLUDED.completed_by_name,
 			assigned_to_user_id = COALESCE(EXCLUDED.assigned_to_user_id, checklist_item.assigned_to_user_id),
 			assigned_to_name = COALESCE(EXCLUDED.assigned_to_name, checklist_item.assigned_to_name),
+			version = checklist_item.version + 1,
 			updated_at = EXCLUDED.updated_at
-		RETURNING id, trip_id, category, name, item_type, is_checked, count_value, location_text, flagged_issue, completed_by_user_id, completed_by_name, assigned_to_user_id, assigned_to_name, updated_at
+		RETURNING id, trip_id, category, name, item_type, is_checked, count_value, location_text, flagged_issue, completed_by_user_id, completed_by_name, assigned_to_user_id, assigned_to_name, version, updated_at
 	`
 
 	// Default category if item is new
 	category := "General"
 
-	log.Info("Executing UPSERT", "tripID", tripID, "name", itemName)
-
-	start := time.Now()
 	var item models.ChecklistItem
-	err := s.db.QueryRowxContext(ctx, upsertQuery,
+	err := ex.QueryRowxContext(ctx, upsertQuery,
 		tripID, category, itemName, isChecked, location, userToRecord, nameToRecord, assignedToUserID, assignedToName, time.Now(),
 	).StructScan(&item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert item: %w", err)
+	}
+	return &item, nil
+}
+
+// UpdateItem updates a specific item's status and details, using an UPSERT to prevent duplicates.
+func (s *SQLStore) UpdateItem(ctx context.Context, tripID string, itemName string, isChecked bool, location string, _ string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error) {
+	// Determine the user to record.
+	var userToRecord *string
+	var nameToRecord *string
+
+	if isChecked {
+		userToRecord = userID
+		if completedByName != "" {
+			nameToRecord = &completedByName
+		}
+	}
 
+	log.Info("Executing UPSERT", "tripID", tripID, "name", itemName)
+
+	start := time.Now()
+	item, err := upsertChecklistItem(ctx, s.db, tripID, itemName, isChecked, location, userToRecord, nameToRecord, assignedToUserID, assignedToName)
 	if err != nil {
 		slog.Error("UPSERT failed", "error", err, "duration", time.Since(start))
-		return nil, fmt.Errorf("failed to upsert item: %w", err)
+		return nil, err
 	}
 	slog.Info("UPSERT success", "id", item.ID, "duration", time.Since(start))
 
-	return &item, nil
+	return item, nil
 }
 
 // AddItemPhoto ensures the checklist item exists (via UPSERT) and then links the artifact to it.
 func (s *SQLStore) AddItemPhoto(ctx context.Context, tripID string, itemName string, photoArtifactID string) (*models.ChecklistItem, error) {
 	// 1. Ensure item exists
 	upsertQuery := `
-		INSERT INTO checklist_item (trip_id, category, name, is_checked, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (trip_id, name) 
-		DO UPDATE SET 
+		INSERT INTO checklist_item (trip_id, category, name, is_checked, version, updated_at)
+		VALUES ($1, $2, $3, $4, 1, $5)
+		ON CONFLICT (trip_id, name)
+		DO UPDATE SET
 			is_checked = EXCLUDED.is_checked,
+			version = checklist_item.version + 1,
 			updated_at = EXCLUDED.updated_at
-		RETURNING id, trip_id, category, name, item_type, is_checked, count_value, location_text, flagged_issue, updated_at
+		RETURNING id, trip_id, category, name, item_type, is_checked, count_value, location_text, flagged_issue, version, updated_at
 	`
 
 	category := "General"
@@ -5,6 +5,7 @@ package data
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -76,4 +77,33 @@ func TestChecklistOperations(t *testing.T) {
 			t.Errorf("Expected linkedItemID %s, got %s", item.ID, linkedItemID)
 		}
 	})
+
+	t.Run("UpdateItemsBatch rolls back on caller cancellation", func(t *testing.T) {
+		// A context that's already past its deadline stands in for an HTTP
+		// client that disconnected partway through the batch: whichever
+		// upsert is in flight when the ctx is checked should fail, and the
+		// transaction's defer tx.Rollback() should mean neither item in the
+		// batch is left behind.
+		cancelCtx, cancel := context.WithTimeout(ctx, 1*time.Nanosecond)
+		defer cancel()
+		time.Sleep(time.Millisecond)
+
+		updates := []BatchItemUpdate{
+			{ItemName: "Life Jackets", IsChecked: true, Location: "Cabin"},
+			{ItemName: "Fire Extinguisher", IsChecked: true, Location: "Galley"},
+		}
+		if _, err := store.UpdateItemsBatch(cancelCtx, trip.ID, "", updates); err == nil {
+			t.Fatal("expected UpdateItemsBatch to fail against a canceled context")
+		}
+
+		for _, u := range updates {
+			var count int
+			if err := db.GetContext(ctx, &count, "SELECT count(*) FROM checklist_item WHERE trip_id = $1 AND name = $2", trip.ID, u.ItemName); err != nil {
+				t.Fatalf("failed to check for partial state: %v", err)
+			}
+			if count != 0 {
+				t.Errorf("expected %q to not be persisted after a rolled-back batch, found %d rows", u.ItemName, count)
+			}
+		}
+	})
 }
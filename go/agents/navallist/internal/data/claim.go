@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ClaimGuestContributions rewrites completed_by_user_id/assigned_to_user_id
+// from a guest name to a newly-verified user, for every checklist_item on a
+// trip the user already has access to (owns, or is listed crew on). It
+// returns the number of checklist_item rows rewritten.
+//
+// A pg_notify("db_events", ...) is emitted per affected trip so realtime
+// subscribers (see internal/realtime/listener.go) get a "user_claim" event
+// and can refresh attributions without a page reload.
+func (s *SQLStore) ClaimGuestContributions(ctx context.Context, guestName, userID string) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE checklist_item ci
+		SET completed_by_user_id = CASE WHEN ci.completed_by_name = $1 THEN $2 ELSE ci.completed_by_user_id END,
+		    assigned_to_user_id = CASE WHEN ci.assigned_to_name = $1 THEN $2 ELSE ci.assigned_to_user_id END
+		WHERE (ci.completed_by_name = $1 OR ci.assigned_to_name = $1)
+		AND ci.trip_id IN (
+			SELECT id FROM trip WHERE user_id = $2
+			UNION
+			SELECT trip_id FROM trip_crew WHERE user_id = $2
+		)
+		RETURNING ci.trip_id
+	`
+	rows, err := tx.QueryxContext(ctx, query, guestName, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim guest contributions: %w", err)
+	}
+
+	var tripIDs []string
+	for rows.Next() {
+		var tripID string
+		if err := rows.Scan(&tripID); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		tripIDs = append(tripIDs, tripID)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+
+	notified := make(map[string]bool, len(tripIDs))
+	for _, tripID := range tripIDs {
+		if notified[tripID] {
+			continue
+		}
+		notified[tripID] = true
+		if err := notifyUserClaimed(ctx, tx, tripID, guestName, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(tripIDs), nil
+}
+
+// notifyUserClaimed publishes a "user_claim" event shaped like
+// realtime.DBEvent, matching the payload the DB triggers for other tables
+// send over the same "db_events" channel.
+func notifyUserClaimed(ctx context.Context, tx *sqlx.Tx, tripID, guestName, userID string) error {
+	payload := struct {
+		Table  string `json:"table"`
+		Action string `json:"action"`
+		Data   struct {
+			GuestName string `json:"guest_name"`
+			UserID    string `json:"user_id"`
+		} `json:"data"`
+		TripID string `json:"trip_id"`
+	}{
+		Table:  "user_claim",
+		Action: "claimed",
+		TripID: tripID,
+	}
+	payload.Data.GuestName = guestName
+	payload.Data.UserID = userID
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user_claim event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_notify('db_events', $1)`, string(raw))
+	return err
+}
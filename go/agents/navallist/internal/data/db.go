@@ -11,6 +11,13 @@ import (
 // SQLStore implements the Store interface using a PostgreSQL database.
 type SQLStore struct {
 	db *sqlx.DB
+
+	// Storage backs GetArtifactSized's derived-image cache (see
+	// artifact_thumbnails.go): it's where both original artifacts and their
+	// cached thumbnails are read from/written to. Left nil, GetArtifactSized
+	// just errors - most callers that never serve thumbnails don't need to
+	// set it.
+	Storage BlobStorage
 }
 
 var _ Store = (*SQLStore)(nil)
@@ -7,6 +7,8 @@ import (
 	"os"
 	"testing"
 
+	"navallist/internal/data/migrations"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
@@ -36,4 +38,28 @@ func cleanupData(t *testing.T, db *sqlx.DB, tables ...string) {
 			t.Logf("Failed to cleanup table %s: %v", table, err)
 		}
 	}
+}
+
+// testMigrator migrates db to exactly upToVersion, the schema a test wants
+// to seed data against, and returns a *migrations.Migrator over the full
+// embedded set - so a later call to its MigrateTo/MigrateUp plays forward
+// from there. This is how a staged migration test (migrate old,
+// seed, migrate forward, assert the seeded rows/columns survived) gets an
+// intermediate checkpoint instead of always starting from the latest schema.
+func testMigrator(t *testing.T, db *sqlx.DB, upToVersion int) *migrations.Migrator {
+	t.Helper()
+
+	all := migrations.Load()
+	staged := make([]migrations.Migration, 0, len(all))
+	for _, m := range all {
+		if m.Version <= upToVersion {
+			staged = append(staged, m)
+		}
+	}
+
+	migrator := migrations.NewMigratorFrom(db, staged)
+	if err := migrator.MigrateTo(context.Background(), upToVersion); err != nil {
+		t.Fatalf("failed to migrate to version %d: %v", upToVersion, err)
+	}
+	return migrations.NewMigratorFrom(db, all)
 }
\ No newline at end of file
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// MaxVerificationAttempts caps how many wrong codes a pending verification
+// tolerates before it must be restarted via CreateEmailVerification.
+const MaxVerificationAttempts = 5
+
+var (
+	// ErrInvalidCode is returned when the submitted code doesn't match the
+	// pending verification, or none exists for the email.
+	ErrInvalidCode = errors.New("invalid verification code")
+	// ErrCodeExpired is returned when the submitted code matched a pending
+	// verification that has already passed its TTL.
+	ErrCodeExpired = errors.New("verification code expired")
+	// ErrTooManyAttempts is returned once a pending verification has been
+	// guessed against MaxVerificationAttempts times.
+	ErrTooManyAttempts = errors.New("too many verification attempts")
+)
+
+// GenerateVerificationCode returns a random 6-digit numeric code, zero-padded.
+func GenerateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// CreateEmailVerification stores a fresh code for email, replacing any
+// previously pending one and resetting its attempt counter.
+func (s *SQLStore) CreateEmailVerification(ctx context.Context, email, code string, ttl time.Duration) error {
+	query := `
+		INSERT INTO email_verification (email, code, attempts, expires_at, created_at)
+		VALUES ($1, $2, 0, $3, $4)
+		ON CONFLICT (email)
+		DO UPDATE SET code = EXCLUDED.code, attempts = 0, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, query, email, code, now.Add(ttl), now)
+	return err
+}
+
+// ConsumeEmailVerification checks code against the pending verification for
+// email. On success the row is deleted so the code cannot be replayed; on
+// mismatch the attempt counter is bumped and ErrInvalidCode is returned.
+func (s *SQLStore) ConsumeEmailVerification(ctx context.Context, email, code string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row struct {
+		Code      string    `db:"code"`
+		Attempts  int       `db:"attempts"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	err = tx.GetContext(ctx, &row, `SELECT code, attempts, expires_at FROM email_verification WHERE email = $1 FOR UPDATE`, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	if time.Now().After(row.ExpiresAt) {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM email_verification WHERE email = $1`, email); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return ErrCodeExpired
+	}
+
+	if row.Attempts >= MaxVerificationAttempts {
+		return ErrTooManyAttempts
+	}
+
+	if row.Code != code {
+		if _, err := tx.ExecContext(ctx, `UPDATE email_verification SET attempts = attempts + 1 WHERE email = $1`, email); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return ErrInvalidCode
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_verification WHERE email = $1`, email); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestEmailVerificationAndClaim(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "checklist_item", "trip_crew", "trip", "users", "email_verification")
+	defer cleanupData(t, db, "checklist_item", "trip_crew", "trip", "users", "email_verification")
+
+	email := "sailor@example.com"
+
+	if err := store.CreateEmailVerification(ctx, email, "111111", time.Minute); err != nil {
+		t.Fatalf("CreateEmailVerification failed: %v", err)
+	}
+
+	if err := store.ConsumeEmailVerification(ctx, email, "000000"); err != ErrInvalidCode {
+		t.Fatalf("ConsumeEmailVerification(wrong code) error = %v, want ErrInvalidCode", err)
+	}
+
+	if err := store.ConsumeEmailVerification(ctx, email, "111111"); err != nil {
+		t.Fatalf("ConsumeEmailVerification(correct code) failed: %v", err)
+	}
+
+	if err := store.ConsumeEmailVerification(ctx, email, "111111"); err != ErrInvalidCode {
+		t.Fatalf("replaying a consumed code = %v, want ErrInvalidCode", err)
+	}
+
+	user, err := store.GetOrCreateUserByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserByEmail failed: %v", err)
+	}
+
+	sameUser, err := store.GetOrCreateUserByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserByEmail (second call) failed: %v", err)
+	}
+	if sameUser.ID != user.ID {
+		t.Errorf("GetOrCreateUserByEmail should be idempotent, got different IDs %s vs %s", sameUser.ID, user.ID)
+	}
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_claim", user.ID, "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("GetOrCreateTrip failed: %v", err)
+	}
+
+	if _, err := store.UpdateItem(ctx, trip.ID, "Life Jackets", true, "", "", nil, "Guest Sam", nil, nil); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	claimed, err := store.ClaimGuestContributions(ctx, "Guest Sam", user.ID)
+	if err != nil {
+		t.Fatalf("ClaimGuestContributions failed: %v", err)
+	}
+	if claimed != 1 {
+		t.Errorf("ClaimGuestContributions() = %d, want 1", claimed)
+	}
+
+	report, err := store.GetTripReport(ctx, trip.ID)
+	if err != nil {
+		t.Fatalf("GetTripReport failed: %v", err)
+	}
+	if len(report) != 1 || report[0].CompletedByUserID == nil || *report[0].CompletedByUserID != user.ID {
+		t.Errorf("expected claimed item to have completed_by_user_id = %s, got %+v", user.ID, report)
+	}
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"navallist/internal/data/models"
+)
+
+// GetOrCreateBlob records a content-addressed blob the first time hash is
+// seen, or returns the existing row unchanged if a trip has already
+// uploaded these exact bytes before - the dedup step that lets identical
+// photos across different trips share one underlying storagePath.
+func (s *SQLStore) GetOrCreateBlob(ctx context.Context, hash string, size int64, mimeType, storagePath string) (*models.Blob, error) {
+	var b models.Blob
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO blob (hash, size, mime_type, storage_path)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (hash) DO UPDATE SET hash = blob.hash
+		RETURNING hash, size, mime_type, storage_path, created_at
+	`, hash, size, mimeType, storagePath).StructScan(&b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create blob: %w", err)
+	}
+	return &b, nil
+}
+
+// manifestRow mirrors artifact_manifest's columns for scanning; Layers/Meta
+// come back as raw JSONB bytes and are decoded by rowToManifest.
+type manifestRow struct {
+	ID        string    `db:"id"`
+	Layers    []byte    `db:"layers"`
+	Meta      []byte    `db:"meta"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func rowToManifest(row manifestRow) (*models.ArtifactManifest, error) {
+	var layers []models.Layer
+	if err := json.Unmarshal(row.Layers, &layers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest layers: %w", err)
+	}
+	var meta map[string]string
+	if len(row.Meta) > 0 {
+		if err := json.Unmarshal(row.Meta, &meta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal manifest meta: %w", err)
+		}
+	}
+	return &models.ArtifactManifest{
+		ID:        row.ID,
+		Layers:    layers,
+		Meta:      meta,
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
+
+// manifestID derives a stable content hash for a manifest from its layers
+// (in a canonical, hash-order-independent sequence) and meta tags, so
+// uploading byte-identical content with identical tags - even from a
+// different trip - produces the same manifest id and is a no-op insert.
+func manifestID(layers []models.Layer, meta map[string]string) string {
+	sorted := make([]models.Layer, len(layers))
+	copy(sorted, layers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ContentHash < sorted[j].ContentHash })
+
+	h := sha256.New()
+	for _, l := range sorted {
+		fmt.Fprintf(h, "layer:%s:%d:%s\n", l.ContentHash, l.Size, l.MimeType)
+	}
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "meta:%s=%s\n", k, meta[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CreateArtifactManifest records a manifest referencing layers (each of
+// which must already exist via GetOrCreateBlob), returning the existing
+// manifest unchanged if the same layers/meta were already recorded.
+func (s *SQLStore) CreateArtifactManifest(ctx context.Context, layers []models.Layer, meta map[string]string) (*models.ArtifactManifest, error) {
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("artifact manifest requires at least one layer")
+	}
+
+	id := manifestID(layers, meta)
+	layersJSON, err := json.Marshal(layers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest layers: %w", err)
+	}
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest meta: %w", err)
+	}
+
+	var row manifestRow
+	err = s.db.QueryRowxContext(ctx, `
+		INSERT INTO artifact_manifest (id, layers, meta)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET id = artifact_manifest.id
+		RETURNING id, layers, meta, created_at
+	`, id, layersJSON, metaJSON).StructScan(&row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact manifest: %w", err)
+	}
+	return rowToManifest(row)
+}
+
+// GetArtifactManifest retrieves a manifest by ID, returning ErrNotFound if
+// no manifest has that ID.
+func (s *SQLStore) GetArtifactManifest(ctx context.Context, id string) (*models.ArtifactManifest, error) {
+	var row manifestRow
+	err := s.db.QueryRowxContext(ctx, `
+		SELECT id, layers, meta, created_at FROM artifact_manifest WHERE id = $1
+	`, id).StructScan(&row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get artifact manifest: %w", err)
+	}
+	return rowToManifest(row)
+}
+
+// GetBlob retrieves a blob by its content hash, returning ErrNotFound if no
+// blob has that hash - used by GetArtifact to resolve a raw blob hash that
+// isn't wrapped in a manifest.
+func (s *SQLStore) GetBlob(ctx context.Context, hash string) (*models.Blob, error) {
+	var b models.Blob
+	err := s.db.GetContext(ctx, &b, `SELECT hash, size, mime_type, storage_path, created_at FROM blob WHERE hash = $1`, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+	return &b, nil
+}
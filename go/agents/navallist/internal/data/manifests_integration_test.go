@@ -0,0 +1,91 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestBlobAndManifestOperations(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifact_manifest", "blob")
+	defer cleanupData(t, db, "artifact_manifest", "blob")
+
+	const hash = "deadbeef"
+	blob, err := store.GetOrCreateBlob(ctx, hash, 1024, "image/jpeg", "de/deadbeef")
+	if err != nil {
+		t.Fatalf("GetOrCreateBlob failed: %v", err)
+	}
+	if blob.Hash != hash || blob.StoragePath != "de/deadbeef" {
+		t.Errorf("unexpected blob: %+v", blob)
+	}
+
+	// Re-creating the same blob should return the existing row unchanged.
+	again, err := store.GetOrCreateBlob(ctx, hash, 999, "image/png", "other/path")
+	if err != nil {
+		t.Fatalf("GetOrCreateBlob (repeat) failed: %v", err)
+	}
+	if again.Size != blob.Size || again.StoragePath != blob.StoragePath {
+		t.Errorf("expected repeat GetOrCreateBlob to return the existing row, got %+v", again)
+	}
+
+	fetched, err := store.GetBlob(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetBlob failed: %v", err)
+	}
+	if fetched.Hash != hash {
+		t.Errorf("expected GetBlob to return hash %q, got %q", hash, fetched.Hash)
+	}
+
+	if _, err := store.GetBlob(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for a missing blob, got %v", err)
+	}
+
+	layers := []models.Layer{{ContentHash: hash, Size: 1024, MimeType: "image/jpeg"}}
+	meta := map[string]string{"trip_id": "trip-1", "checklist_item_id": "item-1"}
+	manifest, err := store.CreateArtifactManifest(ctx, layers, meta)
+	if err != nil {
+		t.Fatalf("CreateArtifactManifest failed: %v", err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].ContentHash != hash {
+		t.Errorf("unexpected manifest layers: %+v", manifest.Layers)
+	}
+	if manifest.Meta["trip_id"] != "trip-1" {
+		t.Errorf("unexpected manifest meta: %+v", manifest.Meta)
+	}
+
+	// Creating the same layers/meta again should return the same manifest ID.
+	again2, err := store.CreateArtifactManifest(ctx, layers, meta)
+	if err != nil {
+		t.Fatalf("CreateArtifactManifest (repeat) failed: %v", err)
+	}
+	if again2.ID != manifest.ID {
+		t.Errorf("expected repeat CreateArtifactManifest to return the same manifest ID, got %q vs %q", again2.ID, manifest.ID)
+	}
+
+	got, err := store.GetArtifactManifest(ctx, manifest.ID)
+	if err != nil {
+		t.Fatalf("GetArtifactManifest failed: %v", err)
+	}
+	if got.ID != manifest.ID {
+		t.Errorf("expected GetArtifactManifest to return ID %q, got %q", manifest.ID, got.ID)
+	}
+
+	if _, err := store.GetArtifactManifest(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for a missing manifest, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"testing"
+
+	"navallist/internal/data/models"
+)
+
+func TestManifestID_OrderIndependent(t *testing.T) {
+	layers := []models.Layer{
+		{ContentHash: "aaa", Size: 1, MimeType: "image/jpeg"},
+		{ContentHash: "bbb", Size: 2, MimeType: "image/jpeg"},
+	}
+	reversed := []models.Layer{layers[1], layers[0]}
+	meta := map[string]string{"trip_id": "t1", "checklist_item_id": "i1"}
+
+	id := manifestID(layers, meta)
+	reversedID := manifestID(reversed, meta)
+	if id != reversedID {
+		t.Errorf("expected manifest id to be independent of layer order, got %q and %q", id, reversedID)
+	}
+}
+
+func TestManifestID_DiffersOnContentOrMeta(t *testing.T) {
+	layers := []models.Layer{{ContentHash: "aaa", Size: 1, MimeType: "image/jpeg"}}
+	meta := map[string]string{"trip_id": "t1"}
+
+	base := manifestID(layers, meta)
+
+	otherLayers := []models.Layer{{ContentHash: "ccc", Size: 1, MimeType: "image/jpeg"}}
+	if manifestID(otherLayers, meta) == base {
+		t.Error("expected a different content hash to produce a different manifest id")
+	}
+
+	otherMeta := map[string]string{"trip_id": "t2"}
+	if manifestID(layers, otherMeta) == base {
+		t.Error("expected different meta to produce a different manifest id")
+	}
+}
@@ -0,0 +1,81 @@
+// Package migrations manages navallist's numbered, forward-only SQL schema
+// migrations. Migration files live in sql/ and are embedded into the
+// binary, so a deployed server never depends on the filesystem layout this
+// repo happens to use.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/*.sql
+var sqlFS embed.FS
+
+// Migration is one numbered, forward-only schema change.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Load parses every sql/NNNN_name.sql file embedded in the binary into a
+// Migration, sorted ascending by Version. It panics on a malformed
+// migration filename or a duplicate version, since both indicate a bug in
+// this package rather than a runtime condition callers can recover from.
+func Load() []Migration {
+	entries, err := fs.ReadDir(sqlFS, "sql")
+	if err != nil {
+		panic(fmt.Sprintf("migrations: failed to read embedded sql dir: %v", err))
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version, name, err := parseFilename(entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("migrations: %v", err))
+		}
+		if existing, ok := seen[version]; ok {
+			panic(fmt.Sprintf("migrations: version %d defined twice (%s and %s)", version, existing, entry.Name()))
+		}
+		seen[version] = entry.Name()
+
+		body, err := sqlFS.ReadFile("sql/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("migrations: failed to read %s: %v", entry.Name(), err))
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations
+}
+
+// parseFilename splits "0008_artifact_manifest.sql" into (8, "artifact_manifest").
+func parseFilename(filename string) (int, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	if base == filename {
+		return 0, "", fmt.Errorf("%q is not a .sql file", filename)
+	}
+
+	prefix, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, "", fmt.Errorf("%q has no _name suffix after its version number", filename)
+	}
+
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q does not start with a numeric version: %w", filename, err)
+	}
+
+	return version, name, nil
+}
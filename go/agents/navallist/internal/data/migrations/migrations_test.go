@@ -0,0 +1,56 @@
+package migrations
+
+import "testing"
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		filename    string
+		wantVersion int
+		wantName    string
+		wantErr     bool
+	}{
+		{filename: "0001_agent_session.sql", wantVersion: 1, wantName: "agent_session"},
+		{filename: "0008_artifact_manifest.sql", wantVersion: 8, wantName: "artifact_manifest"},
+		{filename: "0003_auth_refresh_tokens.sql", wantVersion: 3, wantName: "auth_refresh_tokens"},
+		{filename: "README.md", wantErr: true},
+		{filename: "0004.sql", wantErr: true},
+		{filename: "abcd_init.sql", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			version, name, err := parseFilename(tt.filename)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFilename(%q): expected an error", tt.filename)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFilename(%q): unexpected error: %v", tt.filename, err)
+			}
+			if version != tt.wantVersion || name != tt.wantName {
+				t.Errorf("parseFilename(%q) = (%d, %q), want (%d, %q)", tt.filename, version, name, tt.wantVersion, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLoad_SortedAndComplete(t *testing.T) {
+	migrations := Load()
+	if len(migrations) == 0 {
+		t.Fatal("Load() returned no migrations")
+	}
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("Load() not sorted ascending: version %d comes after %d", migrations[i].Version, migrations[i-1].Version)
+		}
+	}
+
+	for _, migration := range migrations {
+		if migration.SQL == "" {
+			t.Errorf("migration %d_%s has empty SQL", migration.Version, migration.Name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+// TestStagedMigration_ArtifactManifestPreservesExistingArtifacts migrates
+// to the schema version just before artifact_manifest was introduced, seeds
+// a trip and an artifact against it, then migrates the rest of the way
+// forward and confirms both rows are untouched - the staged-migration shape
+// testMigrator exists for, applied here to the trip/artifact data the
+// 0008_artifact_manifest migration runs alongside.
+func TestStagedMigration_ArtifactManifestPreservesExistingArtifacts(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	cleanupData(t, db, "artifact", "trip", "users")
+	defer cleanupData(t, db, "artifact", "trip", "users")
+
+	migrator := testMigrator(t, db, 7) // before 0008_artifact_manifest
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_staged_migration", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip on pre-manifest schema: %v", err)
+	}
+	artifact, err := store.CreateArtifact(ctx, trip.ID, "staged.jpg", "image/jpeg", "/tmp/staged.jpg")
+	if err != nil {
+		t.Fatalf("Failed to create artifact on pre-manifest schema: %v", err)
+	}
+
+	if err := migrator.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+
+	fetchedTrip, err := store.GetTrip(ctx, trip.ID)
+	if err != nil {
+		t.Fatalf("GetTrip after migrating forward failed: %v", err)
+	}
+	if fetchedTrip.ID != trip.ID {
+		t.Errorf("Expected trip %s to survive the migration, got %s", trip.ID, fetchedTrip.ID)
+	}
+
+	fetchedArtifact, err := store.GetArtifactByID(ctx, artifact.ID)
+	if err != nil {
+		t.Fatalf("GetArtifactByID after migrating forward failed: %v", err)
+	}
+	if fetchedArtifact.Filename != artifact.Filename {
+		t.Errorf("Expected artifact filename %s to survive the migration, got %s", artifact.Filename, fetchedArtifact.Filename)
+	}
+
+	// The blob/artifact_manifest tables 0008 adds should now exist and be usable.
+	if _, err := store.GetOrCreateBlob(ctx, "deadbeef", 4, "image/jpeg", "de/deadbeef"); err != nil {
+		t.Errorf("GetOrCreateBlob failed after migrating forward: %v", err)
+	}
+}
@@ -2,8 +2,10 @@ package data
 
 import (
 	"context"
+	"time"
 
 	"navallist/internal/data/models"
+	"navallist/internal/data/syncstore"
 )
 
 // MockStore is a mock implementation of Store for testing.
@@ -13,6 +15,7 @@ type MockStore struct {
 	GetOrCreateTripFunc          func(ctx context.Context, adkSessionID, userID, captainName, tripType string) (*models.Trip, error)
 	GetTripFunc                  func(ctx context.Context, tripID string) (*models.Trip, error)
 	ListUserTripsFunc            func(ctx context.Context, userID string) ([]models.Trip, error)
+	ListActiveTripsFunc          func(ctx context.Context) ([]models.Trip, error)
 	UpdateTripStatusFunc         func(ctx context.Context, tripID string, status string) error
 	UpdateTripTypeFunc           func(ctx context.Context, tripID string, tripType string) error
 	DeleteTripFunc               func(ctx context.Context, tripID string) error
@@ -20,15 +23,52 @@ type MockStore struct {
 	UpdateItemFunc               func(ctx context.Context, tripID, itemName string, isChecked bool, location string, photoArtifactID string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error)
 	AddItemPhotoFunc             func(ctx context.Context, tripID, itemName string, photoArtifactID string) (*models.ChecklistItem, error)
 	CreateArtifactFunc           func(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error)
+	CreateExternalArtifactFunc   func(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error)
+	ConfirmArtifactFunc          func(ctx context.Context, id string) (*models.Artifact, error)
+	MarkArtifactOrphanFunc       func(ctx context.Context, id string) error
+	ListPendingArtifactsFunc     func(ctx context.Context, olderThan time.Time) ([]models.Artifact, error)
 	GetArtifactFunc              func(ctx context.Context, filename string) (*models.Artifact, error)
 	GetArtifactByIDFunc          func(ctx context.Context, id string) (*models.Artifact, error)
+	ListArtifactsByTripFunc      func(ctx context.Context, tripID string) ([]models.Artifact, error)
+	GetArtifactSizedFunc         func(ctx context.Context, id string, size int) ([]byte, string, error)
+	SaveArtifactExifFunc         func(ctx context.Context, exif models.ArtifactExif) error
+	FindArtifactByHashFunc       func(ctx context.Context, contentHash string) (*models.Artifact, error)
+	FindArtifactsNearFunc        func(ctx context.Context, tripID string, lat, lon, radiusMeters float64) ([]models.Artifact, error)
 	FindUserByNameFunc           func(ctx context.Context, name string) (*models.User, error)
+	FindUserByEmailFunc          func(ctx context.Context, email string) (*models.User, error)
+	GetOrCreateUserByEmailFunc   func(ctx context.Context, email string) (*models.User, error)
+	CreateEmailVerificationFunc  func(ctx context.Context, email, code string, ttl time.Duration) error
+	ConsumeEmailVerificationFunc func(ctx context.Context, email, code string) error
+	ClaimGuestContributionsFunc  func(ctx context.Context, guestName, userID string) (int, error)
+	SaveRefreshTokenFunc         func(ctx context.Context, token, userID string, ttl time.Duration) error
+	ConsumeRefreshTokenFunc      func(ctx context.Context, token string) (string, error)
 	GetActiveCrewNamesFunc       func(ctx context.Context, tripID string) ([]string, error)
-	FindCrewMemberFunc           func(ctx context.Context, tripID, query string) (string, error)
+	FindCrewMemberFunc           func(ctx context.Context, tripID, query string) ([]models.CrewMatch, error)
 	GetTripIDBySessionIDFunc     func(ctx context.Context, sessionID string) (string, error)
 	AddTripCrewFunc              func(ctx context.Context, tripID, userID, displayName string) error
+	IsTripCrewFunc               func(ctx context.Context, tripID, userID string) (bool, error)
 	UpdateTripMetadataFunc       func(ctx context.Context, adkSessionID string, boatName *string, captainName *string) (*models.Trip, error)
 	UpdateItemWithAssignmentFunc func(ctx context.Context, tripID, itemName string, isChecked bool, location, photoID, currentUserID, assignedToName string) (*models.ChecklistItem, bool, error)
+	UpdateItemsBatchFunc         func(ctx context.Context, tripID, currentUserID string, updates []BatchItemUpdate) ([]BatchItemResult, error)
+	CreateUploadSessionFunc      func(ctx context.Context, tripID, itemName, filename, mimeType string, totalSize int64) (*models.ArtifactUpload, error)
+	GetUploadSessionFunc         func(ctx context.Context, uploadID string) (*models.ArtifactUpload, error)
+	AppendUploadChunkFunc        func(ctx context.Context, uploadID string, chunk []byte) (*models.ArtifactUpload, error)
+	FinalizeUploadFunc           func(ctx context.Context, uploadID string) (*models.ArtifactUpload, error)
+	ApplyMutationsFunc           func(ctx context.Context, tripID string, userID *string, muts []ItemMutation) ([]MutationResult, error)
+	GetChangedItemsFunc          func(ctx context.Context, tripID string, sinceVersion int64) ([]models.ChecklistItem, error)
+	CreateAgentRunFunc           func(ctx context.Context, tripID, userID, sessionID, transport string) (*models.AgentRun, error)
+	AppendAgentRunEventFunc      func(ctx context.Context, runID string, seq int64, payload []byte) error
+	FinishAgentRunFunc           func(ctx context.Context, runID string, runErr error) error
+	GetAgentRunEventsSinceFunc   func(ctx context.Context, runID string, sinceSeq int64) ([]models.AgentRunEvent, error)
+	GetOrCreateBlobFunc          func(ctx context.Context, hash string, size int64, mimeType, storagePath string) (*models.Blob, error)
+	GetBlobFunc                  func(ctx context.Context, hash string) (*models.Blob, error)
+	CreateArtifactManifestFunc   func(ctx context.Context, layers []models.Layer, meta map[string]string) (*models.ArtifactManifest, error)
+	GetArtifactManifestFunc      func(ctx context.Context, id string) (*models.ArtifactManifest, error)
+	ApplyOpsFunc                 func(ctx context.Context, tripID string, ops []syncstore.ChecklistOp) ([]models.ChecklistItem, error)
+	GetOpsSinceFunc              func(ctx context.Context, tripID string, sinceLamport int64) ([]syncstore.ChecklistOp, error)
+	EmitServerOpFunc             func(ctx context.Context, tripID, itemName, field string, value interface{}, originUserID string) error
+	RecordAgentEventFunc         func(ctx context.Context, event models.AgentEvent) (*models.AgentEvent, error)
+	GetAgentEventsFunc           func(ctx context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error)
 }
 
 var _ Store = (*MockStore)(nil)
@@ -41,6 +81,10 @@ func (m *MockStore) UpdateItemWithAssignment(ctx context.Context, tripID, itemNa
 	return m.UpdateItemWithAssignmentFunc(ctx, tripID, itemName, isChecked, location, photoID, currentUserID, assignedToName)
 }
 
+func (m *MockStore) UpdateItemsBatch(ctx context.Context, tripID, currentUserID string, updates []BatchItemUpdate) ([]BatchItemResult, error) {
+	return m.UpdateItemsBatchFunc(ctx, tripID, currentUserID, updates)
+}
+
 func (m *MockStore) GetTripIDBySessionID(ctx context.Context, sessionID string) (string, error) {
 	return m.GetTripIDBySessionIDFunc(ctx, sessionID)
 }
@@ -49,11 +93,15 @@ func (m *MockStore) AddTripCrew(ctx context.Context, tripID, userID, displayName
 	return m.AddTripCrewFunc(ctx, tripID, userID, displayName)
 }
 
+func (m *MockStore) IsTripCrew(ctx context.Context, tripID, userID string) (bool, error) {
+	return m.IsTripCrewFunc(ctx, tripID, userID)
+}
+
 func (m *MockStore) FindUserByName(ctx context.Context, name string) (*models.User, error) {
 	return m.FindUserByNameFunc(ctx, name)
 }
 
-func (m *MockStore) FindCrewMember(ctx context.Context, tripID, query string) (string, error) {
+func (m *MockStore) FindCrewMember(ctx context.Context, tripID, query string) ([]models.CrewMatch, error) {
 	return m.FindCrewMemberFunc(ctx, tripID, query)
 }
 
@@ -69,6 +117,34 @@ func (m *MockStore) UpdateUser(ctx context.Context, id, name string) error {
 	return m.UpdateUserFunc(ctx, id, name)
 }
 
+func (m *MockStore) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return m.FindUserByEmailFunc(ctx, email)
+}
+
+func (m *MockStore) GetOrCreateUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return m.GetOrCreateUserByEmailFunc(ctx, email)
+}
+
+func (m *MockStore) CreateEmailVerification(ctx context.Context, email, code string, ttl time.Duration) error {
+	return m.CreateEmailVerificationFunc(ctx, email, code, ttl)
+}
+
+func (m *MockStore) ConsumeEmailVerification(ctx context.Context, email, code string) error {
+	return m.ConsumeEmailVerificationFunc(ctx, email, code)
+}
+
+func (m *MockStore) ClaimGuestContributions(ctx context.Context, guestName, userID string) (int, error) {
+	return m.ClaimGuestContributionsFunc(ctx, guestName, userID)
+}
+
+func (m *MockStore) SaveRefreshToken(ctx context.Context, token, userID string, ttl time.Duration) error {
+	return m.SaveRefreshTokenFunc(ctx, token, userID, ttl)
+}
+
+func (m *MockStore) ConsumeRefreshToken(ctx context.Context, token string) (string, error) {
+	return m.ConsumeRefreshTokenFunc(ctx, token)
+}
+
 func (m *MockStore) GetOrCreateTrip(ctx context.Context, adkSessionID, userID, captainName, tripType string) (*models.Trip, error) {
 	return m.GetOrCreateTripFunc(ctx, adkSessionID, userID, captainName, tripType)
 }
@@ -81,6 +157,10 @@ func (m *MockStore) ListUserTrips(ctx context.Context, userID string) ([]models.
 	return m.ListUserTripsFunc(ctx, userID)
 }
 
+func (m *MockStore) ListActiveTrips(ctx context.Context) ([]models.Trip, error) {
+	return m.ListActiveTripsFunc(ctx)
+}
+
 func (m *MockStore) UpdateTripStatus(ctx context.Context, tripID string, status string) error {
 	return m.UpdateTripStatusFunc(ctx, tripID, status)
 }
@@ -109,6 +189,22 @@ func (m *MockStore) CreateArtifact(ctx context.Context, tripID, filename, mimeTy
 	return m.CreateArtifactFunc(ctx, tripID, filename, mimeType, storagePath)
 }
 
+func (m *MockStore) CreateExternalArtifact(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error) {
+	return m.CreateExternalArtifactFunc(ctx, tripID, filename, mimeType, storagePath)
+}
+
+func (m *MockStore) ConfirmArtifact(ctx context.Context, id string) (*models.Artifact, error) {
+	return m.ConfirmArtifactFunc(ctx, id)
+}
+
+func (m *MockStore) MarkArtifactOrphan(ctx context.Context, id string) error {
+	return m.MarkArtifactOrphanFunc(ctx, id)
+}
+
+func (m *MockStore) ListPendingArtifacts(ctx context.Context, olderThan time.Time) ([]models.Artifact, error) {
+	return m.ListPendingArtifactsFunc(ctx, olderThan)
+}
+
 func (m *MockStore) GetArtifact(ctx context.Context, filename string) (*models.Artifact, error) {
 	return m.GetArtifactFunc(ctx, filename)
 }
@@ -116,3 +212,99 @@ func (m *MockStore) GetArtifact(ctx context.Context, filename string) (*models.A
 func (m *MockStore) GetArtifactByID(ctx context.Context, id string) (*models.Artifact, error) {
 	return m.GetArtifactByIDFunc(ctx, id)
 }
+
+func (m *MockStore) ListArtifactsByTrip(ctx context.Context, tripID string) ([]models.Artifact, error) {
+	return m.ListArtifactsByTripFunc(ctx, tripID)
+}
+
+func (m *MockStore) GetArtifactSized(ctx context.Context, id string, size int) ([]byte, string, error) {
+	return m.GetArtifactSizedFunc(ctx, id, size)
+}
+
+func (m *MockStore) SaveArtifactExif(ctx context.Context, exif models.ArtifactExif) error {
+	return m.SaveArtifactExifFunc(ctx, exif)
+}
+
+func (m *MockStore) FindArtifactByHash(ctx context.Context, contentHash string) (*models.Artifact, error) {
+	return m.FindArtifactByHashFunc(ctx, contentHash)
+}
+
+func (m *MockStore) FindArtifactsNear(ctx context.Context, tripID string, lat, lon, radiusMeters float64) ([]models.Artifact, error) {
+	return m.FindArtifactsNearFunc(ctx, tripID, lat, lon, radiusMeters)
+}
+
+func (m *MockStore) CreateUploadSession(ctx context.Context, tripID, itemName, filename, mimeType string, totalSize int64) (*models.ArtifactUpload, error) {
+	return m.CreateUploadSessionFunc(ctx, tripID, itemName, filename, mimeType, totalSize)
+}
+
+func (m *MockStore) GetUploadSession(ctx context.Context, uploadID string) (*models.ArtifactUpload, error) {
+	return m.GetUploadSessionFunc(ctx, uploadID)
+}
+
+func (m *MockStore) AppendUploadChunk(ctx context.Context, uploadID string, chunk []byte) (*models.ArtifactUpload, error) {
+	return m.AppendUploadChunkFunc(ctx, uploadID, chunk)
+}
+
+func (m *MockStore) FinalizeUpload(ctx context.Context, uploadID string) (*models.ArtifactUpload, error) {
+	return m.FinalizeUploadFunc(ctx, uploadID)
+}
+
+func (m *MockStore) ApplyMutations(ctx context.Context, tripID string, userID *string, muts []ItemMutation) ([]MutationResult, error) {
+	return m.ApplyMutationsFunc(ctx, tripID, userID, muts)
+}
+
+func (m *MockStore) GetChangedItems(ctx context.Context, tripID string, sinceVersion int64) ([]models.ChecklistItem, error) {
+	return m.GetChangedItemsFunc(ctx, tripID, sinceVersion)
+}
+
+func (m *MockStore) ApplyOps(ctx context.Context, tripID string, ops []syncstore.ChecklistOp) ([]models.ChecklistItem, error) {
+	return m.ApplyOpsFunc(ctx, tripID, ops)
+}
+
+func (m *MockStore) GetOpsSince(ctx context.Context, tripID string, sinceLamport int64) ([]syncstore.ChecklistOp, error) {
+	return m.GetOpsSinceFunc(ctx, tripID, sinceLamport)
+}
+
+func (m *MockStore) EmitServerOp(ctx context.Context, tripID, itemName, field string, value interface{}, originUserID string) error {
+	return m.EmitServerOpFunc(ctx, tripID, itemName, field, value, originUserID)
+}
+
+func (m *MockStore) CreateAgentRun(ctx context.Context, tripID, userID, sessionID, transport string) (*models.AgentRun, error) {
+	return m.CreateAgentRunFunc(ctx, tripID, userID, sessionID, transport)
+}
+
+func (m *MockStore) AppendAgentRunEvent(ctx context.Context, runID string, seq int64, payload []byte) error {
+	return m.AppendAgentRunEventFunc(ctx, runID, seq, payload)
+}
+
+func (m *MockStore) FinishAgentRun(ctx context.Context, runID string, runErr error) error {
+	return m.FinishAgentRunFunc(ctx, runID, runErr)
+}
+
+func (m *MockStore) GetAgentRunEventsSince(ctx context.Context, runID string, sinceSeq int64) ([]models.AgentRunEvent, error) {
+	return m.GetAgentRunEventsSinceFunc(ctx, runID, sinceSeq)
+}
+
+func (m *MockStore) RecordAgentEvent(ctx context.Context, event models.AgentEvent) (*models.AgentEvent, error) {
+	return m.RecordAgentEventFunc(ctx, event)
+}
+
+func (m *MockStore) GetAgentEvents(ctx context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error) {
+	return m.GetAgentEventsFunc(ctx, tripID, upToEventID)
+}
+
+func (m *MockStore) GetOrCreateBlob(ctx context.Context, hash string, size int64, mimeType, storagePath string) (*models.Blob, error) {
+	return m.GetOrCreateBlobFunc(ctx, hash, size, mimeType, storagePath)
+}
+
+func (m *MockStore) GetBlob(ctx context.Context, hash string) (*models.Blob, error) {
+	return m.GetBlobFunc(ctx, hash)
+}
+
+func (m *MockStore) CreateArtifactManifest(ctx context.Context, layers []models.Layer, meta map[string]string) (*models.ArtifactManifest, error) {
+	return m.CreateArtifactManifestFunc(ctx, layers, meta)
+}
+
+func (m *MockStore) GetArtifactManifest(ctx context.Context, id string) (*models.ArtifactManifest, error) {
+	return m.GetArtifactManifestFunc(ctx, id)
+}
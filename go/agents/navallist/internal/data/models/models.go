@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"navallist/internal/realtime/pb"
 )
 
 // Trip represents a checklist session.
@@ -28,13 +31,91 @@ type ChecklistItem struct {
 	CountValue          int        `db:"count_value" json:"count_value"`
 	LocationText        *string    `db:"location_text" json:"location_text,omitempty"`
 	Photos              []Artifact `db:"-" json:"photos,omitempty"` // Populated manually
+	// Manifests holds the content-addressed ArtifactManifest for each of
+	// Photos that has one, keyed by nothing in particular - populated
+	// alongside Photos by GetTripReport so a client can resolve export/dedup
+	// info without a second round trip per photo. Photos itself is kept for
+	// RestoreTrip/SnapshotTrip, which still operate on raw artifact rows.
+	Manifests []ArtifactManifest `db:"-" json:"manifests,omitempty"`
 	FlaggedIssue        *string    `db:"flagged_issue" json:"flagged_issue,omitempty"`
 	CompletedByUserID   *string    `db:"completed_by_user_id" json:"completed_by_user_id,omitempty"`
 	CompletedByName     *string    `db:"completed_by_name" json:"completed_by_name,omitempty"`
 	CompletedByUserName *string    `db:"completed_by_user_name" json:"completed_by_user_name,omitempty"`
 	AssignedToUserID    *string    `db:"assigned_to_user_id" json:"assigned_to_user_id,omitempty"`
 	AssignedToName      *string    `db:"assigned_to_name" json:"assigned_to_name,omitempty"`
-	UpdatedAt           time.Time  `db:"updated_at" json:"updated_at"`
+	// Version increments on every write (see upsertChecklistItem), backing
+	// the offline-first sync API's conflict resolution and change feed.
+	Version int64 `db:"version" json:"version"`
+	// VectorClock is the per-field Lamport-clock/last-writer bookkeeping
+	// the CRDT sync path (SQLStore.ApplyOps, internal/data/syncstore) uses
+	// to merge concurrent offline edits. It's internal merge state, not
+	// something a client needs, so it's left out of the JSON response.
+	VectorClock json.RawMessage `db:"vector_clock" json:"-"`
+	// Tombstone marks an item deleted via the CRDT sync path rather than
+	// removed outright, so a delete op still replays consistently on a
+	// peer that reconnects after it was applied elsewhere.
+	Tombstone bool      `db:"tombstone" json:"tombstone,omitempty"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// MarshalBinary encodes the item as a protobuf ChecklistItemDelta, for
+// publishing over the realtime channel when a client negotiated the binary
+// wire format instead of JSON.
+func (c ChecklistItem) MarshalBinary() ([]byte, error) {
+	delta := pb.ChecklistItemDelta{
+		Id:            c.ID,
+		TripId:        c.TripID,
+		Category:      c.Category,
+		Name:          c.Name,
+		IsChecked:     c.IsChecked,
+		UpdatedAtUnix: c.UpdatedAt.Unix(),
+	}
+	if c.LocationText != nil {
+		delta.LocationText = *c.LocationText
+	}
+	if c.CompletedByUserID != nil {
+		delta.CompletedByUserId = *c.CompletedByUserID
+	}
+	if c.CompletedByName != nil {
+		delta.CompletedByName = *c.CompletedByName
+	}
+	if c.AssignedToUserID != nil {
+		delta.AssignedToUserId = *c.AssignedToUserID
+	}
+	if c.AssignedToName != nil {
+		delta.AssignedToName = *c.AssignedToName
+	}
+	return delta.Marshal()
+}
+
+// UnmarshalBinary decodes a protobuf ChecklistItemDelta into the item.
+func (c *ChecklistItem) UnmarshalBinary(data []byte) error {
+	var delta pb.ChecklistItemDelta
+	if err := delta.Unmarshal(data); err != nil {
+		return err
+	}
+	c.ID = delta.Id
+	c.TripID = delta.TripId
+	c.Category = delta.Category
+	c.Name = delta.Name
+	c.IsChecked = delta.IsChecked
+	c.UpdatedAt = time.Unix(delta.UpdatedAtUnix, 0)
+	if delta.LocationText != "" {
+		c.LocationText = &delta.LocationText
+	}
+	if delta.CompletedByUserId != "" {
+		c.CompletedByUserID = &delta.CompletedByUserId
+	}
+	if delta.CompletedByName != "" {
+		c.CompletedByName = &delta.CompletedByName
+	}
+	if delta.AssignedToUserId != "" {
+		c.AssignedToUserID = &delta.AssignedToUserId
+	}
+	if delta.AssignedToName != "" {
+		c.AssignedToName = &delta.AssignedToName
+	}
+	return nil
 }
 
 // Artifact represents a file (photo/audio) stored related to a trip.
@@ -45,7 +126,89 @@ type Artifact struct {
 	Filename        string    `db:"filename" json:"filename"`
 	MimeType        *string   `db:"mime_type" json:"mime_type,omitempty"`
 	StoragePath     string    `db:"storage_path" json:"storage_path"`
-	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	// Status is "confirmed" for artifacts this server wrote the bytes for
+	// itself, or "pending"/"orphan" for one created by the direct-upload
+	// flow (see PresignUploader) before/after internal/storagereconciler
+	// checks whether the client's upload actually landed.
+	Status    string    `db:"status" json:"status"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// MarshalBinary encodes the artifact as a protobuf ArtifactLinked message.
+func (a Artifact) MarshalBinary() ([]byte, error) {
+	linked := pb.ArtifactLinked{
+		Id:          a.ID,
+		Filename:    a.Filename,
+		StoragePath: a.StoragePath,
+	}
+	if a.TripID != nil {
+		linked.TripId = *a.TripID
+	}
+	if a.ChecklistItemID != nil {
+		linked.ChecklistItemId = *a.ChecklistItemID
+	}
+	if a.MimeType != nil {
+		linked.MimeType = *a.MimeType
+	}
+	return linked.Marshal()
+}
+
+// UnmarshalBinary decodes a protobuf ArtifactLinked message into the artifact.
+func (a *Artifact) UnmarshalBinary(data []byte) error {
+	var linked pb.ArtifactLinked
+	if err := linked.Unmarshal(data); err != nil {
+		return err
+	}
+	a.ID = linked.Id
+	a.Filename = linked.Filename
+	a.StoragePath = linked.StoragePath
+	if linked.TripId != "" {
+		a.TripID = &linked.TripId
+	}
+	if linked.ChecklistItemId != "" {
+		a.ChecklistItemID = &linked.ChecklistItemId
+	}
+	if linked.MimeType != "" {
+		a.MimeType = &linked.MimeType
+	}
+	return nil
+}
+
+// ArtifactExif holds the EXIF-derived metadata and hashes captured at
+// upload time, kept separate from the artifact row (and never written into
+// the stored blob) since imaging.Normalize strips GPS/EXIF from the blob
+// for privacy before it ever reaches BlobStorage.
+type ArtifactExif struct {
+	ArtifactID string `db:"artifact_id" json:"artifact_id"`
+	// ContentHash is the SHA-256 of the original upload; Store.FindArtifactByHash
+	// uses it to dedupe byte-identical re-uploads onto the existing artifact.
+	ContentHash string `db:"content_hash" json:"content_hash"`
+	// PHash is a 64-bit perceptual (difference) hash, stored as the
+	// equivalent int64 bit pattern since Postgres has no unsigned type.
+	PHash       *int64     `db:"phash" json:"phash,omitempty"`
+	GPSLat      *float64   `db:"gps_lat" json:"gps_lat,omitempty"`
+	GPSLon      *float64   `db:"gps_lon" json:"gps_lon,omitempty"`
+	CapturedAt  *time.Time `db:"captured_at" json:"captured_at,omitempty"`
+	Orientation int        `db:"orientation" json:"orientation"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ArtifactUpload tracks a tus-style resumable upload in progress: how many
+// bytes of a declared-size file have arrived so far, and the accumulated
+// bytes themselves until FinalizeUpload hands them off to BlobStorage and
+// CreateArtifact turns them into a real Artifact.
+type ArtifactUpload struct {
+	ID           string    `db:"id" json:"id"`
+	TripID       string    `db:"trip_id" json:"trip_id"`
+	ItemName     string    `db:"item_name" json:"item_name"`
+	Filename     string    `db:"filename" json:"filename"`
+	MimeType     *string   `db:"mime_type" json:"mime_type,omitempty"`
+	TotalSize    int64     `db:"total_size" json:"total_size"`
+	ReceivedSize int64     `db:"received_size" json:"received_size"`
+	Data         []byte    `db:"data" json:"-"`
+	Status       string    `db:"status" json:"status"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 }
 
 // UnifiedTrip represents the combined state of a trip, including metadata, items, and agent session state.
@@ -62,5 +225,97 @@ type User struct {
 	GoogleSub string    `db:"google_sub" json:"google_sub"`
 	Name      *string   `db:"name" json:"name,omitempty"`
 	Picture   *string   `db:"picture" json:"picture,omitempty"`
+	IsAdmin   bool      `db:"is_admin" json:"is_admin"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
+
+// Layer is one content-addressed blob referenced by an ArtifactManifest, in
+// the order it should be composed/read.
+type Layer struct {
+	ContentHash string `json:"content_hash"`
+	Size        int64  `json:"size"`
+	MimeType    string `json:"mime_type"`
+}
+
+// Blob is one deduplicated byte sequence stored at Hash's storage path,
+// shared across every manifest layer that references it regardless of
+// which trip uploaded it first.
+type Blob struct {
+	Hash        string    `db:"hash" json:"hash"`
+	Size        int64     `db:"size" json:"size"`
+	MimeType    string    `db:"mime_type" json:"mime_type"`
+	StoragePath string    `db:"storage_path" json:"storage_path"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// ArtifactManifest is a content-addressed reference to one or more Blob
+// layers plus free-form tags (e.g. "trip_id", "checklist_item_id"). Its ID
+// is the hash of its own contents (see manifestID in manifests.go), so
+// uploading the same bytes with the same tags twice - even for different
+// trips - yields the same manifest and shares the same underlying blobs.
+type ArtifactManifest struct {
+	ID        string            `db:"id" json:"id"`
+	Layers    []Layer           `db:"-" json:"layers"`
+	Meta      map[string]string `db:"-" json:"meta,omitempty"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+}
+
+// CrewMatch is one ranked candidate returned by SQLStore.FindCrewMember for
+// a fuzzy name query, e.g. assigning "Tris" to a checklist item. Score is a
+// 0-1 confidence - 1 for an exact case-insensitive match, otherwise the
+// best trigram similarity found across the whole name or any single
+// token - and Source records which of those produced it, so a caller (or
+// the crew/resolve API response) can explain why a candidate ranked where
+// it did.
+type CrewMatch struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Source string  `json:"source"` // "exact", "full_name", or "token"
+}
+
+// AgentRun records one streamed agent interaction
+// (GET /api/trips/{id}/agent/stream), so it can be replayed for debugging
+// and so a client that reconnects mid-stream can resume from AgentRunEvent
+// rows instead of re-running the agent.
+type AgentRun struct {
+	ID        string     `db:"id" json:"id"`
+	TripID    string     `db:"trip_id" json:"trip_id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	SessionID string     `db:"session_id" json:"session_id"`
+	Transport string     `db:"transport" json:"transport"`
+	Status    string     `db:"status" json:"status"`
+	Error     *string    `db:"error" json:"error,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	EndedAt   *time.Time `db:"ended_at" json:"ended_at,omitempty"`
+}
+
+// AgentRunEvent is a single SSE event emitted during an AgentRun, numbered
+// by Seq so a client can resume with a Last-Event-ID after that sequence
+// number.
+type AgentRunEvent struct {
+	RunID     string          `db:"run_id" json:"run_id"`
+	Seq       int64           `db:"seq" json:"seq"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+// AgentEvent records one agent tool-call invocation (update_checklist_items,
+// update_trip_details, get_crew_list, get_checklist_status), recorded by
+// the recordToolCall middleware each handler in NewChecklistAgent is
+// wrapped in. It backs GET /api/trips/{id}/timeline's post-trip debrief
+// feed and POST /api/trips/{id}/replay's what-if reconstruction, so unlike
+// AgentRunEvent (one row per streamed SSE frame) it's keyed by tool call,
+// not by stream.
+type AgentEvent struct {
+	ID         int64           `db:"id" json:"id"`
+	TripID     string          `db:"trip_id" json:"trip_id"`
+	SessionID  string          `db:"session_id" json:"session_id"`
+	UserID     string          `db:"user_id" json:"user_id"`
+	ToolName   string          `db:"tool_name" json:"tool_name"`
+	ArgsJSON   json.RawMessage `db:"args_json" json:"args_json"`
+	ResultJSON json.RawMessage `db:"result_json" json:"result_json,omitempty"`
+	Status     string          `db:"status" json:"status"` // "success" or "error"
+	ErrorClass string          `db:"error_class" json:"error_class,omitempty"`
+	DurationMS int64           `db:"duration_ms" json:"duration_ms"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
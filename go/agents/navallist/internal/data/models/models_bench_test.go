@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// BenchmarkChecklistItemEncoding compares JSON vs protobuf payload size for
+// a 500-item checklist, the scale that motivated the binary wire format.
+func BenchmarkChecklistItemEncoding(b *testing.B) {
+	location := "Port locker"
+	completedBy := "Captain Steve"
+	items := make([]ChecklistItem, 500)
+	for i := range items {
+		items[i] = ChecklistItem{
+			ID:              "item_1",
+			TripID:          "trip_1",
+			Category:        "Safety",
+			Name:            "Flares",
+			IsChecked:       true,
+			LocationText:    &location,
+			CompletedByName: &completedBy,
+			UpdatedAt:       time.Unix(1700000000, 0),
+		}
+	}
+
+	b.Run("JSON", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			payload, err := json.Marshal(items)
+			if err != nil {
+				b.Fatalf("json.Marshal() error = %v", err)
+			}
+			size = len(payload)
+		}
+		b.ReportMetric(float64(size), "bytes/op")
+	})
+
+	b.Run("Protobuf", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			var total int
+			for _, item := range items {
+				payload, err := item.MarshalBinary()
+				if err != nil {
+					b.Fatalf("MarshalBinary() error = %v", err)
+				}
+				total += len(payload)
+			}
+			size = total
+		}
+		b.ReportMetric(float64(size), "bytes/op")
+	})
+}
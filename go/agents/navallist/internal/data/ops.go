@@ -0,0 +1,284 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"navallist/internal/data/models"
+	"navallist/internal/data/syncstore"
+
+	"github.com/charmbracelet/log"
+	"github.com/jmoiron/sqlx"
+)
+
+// ApplyOps merges a batch of offline-authored ChecklistOps into tripID's
+// checklist using syncstore's per-field CRDT merge rules, all inside a
+// single transaction for the whole batch. Unlike ApplyMutations (which resolves a single batch
+// against the live server state by submission order), ApplyOps trusts each
+// op's own Lamport counter, so two peers that went offline independently
+// and each applied a batch of ops still converge on the same final state
+// however their batches are later interleaved.
+func (s *SQLStore) ApplyOps(ctx context.Context, tripID string, ops []syncstore.ChecklistOp) ([]models.ChecklistItem, error) {
+	var realID string
+	if err := s.db.GetContext(ctx, &realID, `SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1`, tripID); err != nil {
+		return nil, fmt.Errorf("failed to resolve trip: %w", err)
+	}
+
+	byItem := make(map[string][]syncstore.ChecklistOp)
+	var order []string
+	for _, op := range ops {
+		if _, ok := byItem[op.ItemName]; !ok {
+			order = append(order, op.ItemName)
+		}
+		byItem[op.ItemName] = append(byItem[op.ItemName], op)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin ops transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	results := make([]models.ChecklistItem, 0, len(order))
+	for _, itemName := range order {
+		itemOps := byItem[itemName]
+		sort.SliceStable(itemOps, func(i, j int) bool { return itemOps[i].Lamport < itemOps[j].Lamport })
+
+		item, err := applyItemOps(ctx, tx, realID, itemName, itemOps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply ops for %q: %w", itemName, err)
+		}
+		results = append(results, *item)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit ops transaction: %w", err)
+	}
+	return results, nil
+}
+
+// applyItemOps merges every op touching one item, in Lamport order, under
+// a row lock, then persists the merged fields and clock in a single
+// UPDATE.
+func applyItemOps(ctx context.Context, tx *sqlx.Tx, tripID, itemName string, ops []syncstore.ChecklistOp) (*models.ChecklistItem, error) {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO checklist_item (trip_id, category, name, version, updated_at)
+		VALUES ($1, 'General', $2, 1, now())
+		ON CONFLICT (trip_id, name) DO NOTHING
+	`, tripID, itemName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure item exists: %w", err)
+	}
+
+	var row struct {
+		ID           string          `db:"id"`
+		IsChecked    bool            `db:"is_checked"`
+		LocationText *string         `db:"location_text"`
+		FlaggedIssue *string         `db:"flagged_issue"`
+		VectorClock  json.RawMessage `db:"vector_clock"`
+	}
+	err = tx.QueryRowxContext(ctx, `
+		SELECT id, is_checked, location_text, flagged_issue, vector_clock
+		FROM checklist_item WHERE trip_id = $1 AND name = $2 FOR UPDATE
+	`, tripID, itemName).StructScan(&row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock item: %w", err)
+	}
+
+	clock := syncstore.NewClock()
+	if len(row.VectorClock) > 0 {
+		if err := json.Unmarshal(row.VectorClock, &clock); err != nil {
+			return nil, fmt.Errorf("failed to decode vector clock: %w", err)
+		}
+	}
+
+	isChecked := row.IsChecked
+	location := ""
+	if row.LocationText != nil {
+		location = *row.LocationText
+	}
+	flaggedIssue := ""
+	if row.FlaggedIssue != nil {
+		flaggedIssue = *row.FlaggedIssue
+	}
+	var photoIDs []string
+
+	for _, op := range ops {
+		switch op.Field {
+		case syncstore.FieldIsChecked:
+			if v, ok := op.Value.(bool); ok && clock.ResolveLWW(syncstore.FieldIsChecked, op) {
+				isChecked = v
+			}
+		case syncstore.FieldLocation:
+			if v, ok := op.Value.(string); ok && clock.ResolveLWW(syncstore.FieldLocation, op) {
+				location = v
+			}
+		case syncstore.FieldFlaggedIssue:
+			if v, ok := op.Value.(string); ok {
+				flaggedIssue = syncstore.MergeFlaggedIssue(flaggedIssue, clock.IsConcurrentWith(op), v)
+			}
+		case syncstore.FieldPhotos:
+			if v, ok := op.Value.(string); ok && v != "" {
+				photoIDs = append(photoIDs, v)
+			}
+		}
+
+		clock = clock.Observe(op)
+
+		if err := insertOp(ctx, tx, tripID, op); err != nil {
+			return nil, err
+		}
+	}
+
+	clockJSON, err := json.Marshal(clock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode vector clock: %w", err)
+	}
+
+	var item models.ChecklistItem
+	err = tx.QueryRowxContext(ctx, `
+		UPDATE checklist_item
+		SET is_checked = $1, location_text = NULLIF($2, ''), flagged_issue = NULLIF($3, ''),
+		    vector_clock = $4, version = version + 1, updated_at = now()
+		WHERE id = $5
+		RETURNING id, trip_id, category, name, item_type, is_checked, count_value,
+		          location_text, flagged_issue, completed_by_user_id, completed_by_name,
+		          assigned_to_user_id, assigned_to_name, version, tombstone, updated_at
+	`, isChecked, location, flaggedIssue, clockJSON, row.ID).StructScan(&item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist merged item: %w", err)
+	}
+
+	for _, photoID := range photoIDs {
+		if _, err := tx.ExecContext(ctx, `UPDATE artifact SET checklist_item_id = $1 WHERE id = $2 AND trip_id = $3`, item.ID, photoID, tripID); err != nil {
+			log.Warn("ApplyOps: failed to link photo", "item", itemName, "photo", photoID, "error", err)
+		}
+	}
+
+	return &item, nil
+}
+
+// insertOp appends op to tripID's op log, the source of truth GetOpsSince
+// replays from.
+func insertOp(ctx context.Context, tx *sqlx.Tx, tripID string, op syncstore.ChecklistOp) error {
+	valueJSON, err := json.Marshal(op.Value)
+	if err != nil {
+		return fmt.Errorf("failed to encode op value: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO checklist_item_op (trip_id, item_name, field, value, origin_user_id, lamport, wall_clock)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, tripID, op.ItemName, op.Field, valueJSON, op.OriginUserID, op.Lamport, op.WallClock)
+	if err != nil {
+		return fmt.Errorf("failed to log op: %w", err)
+	}
+	return nil
+}
+
+// GetOpsSince returns every accepted op for tripID with a Lamport counter
+// greater than sinceLamport, backing GET /api/trips/{id}/ops?since= for a
+// peer reconciling after a stretch offline.
+func (s *SQLStore) GetOpsSince(ctx context.Context, tripID string, sinceLamport int64) ([]syncstore.ChecklistOp, error) {
+	var rows []struct {
+		ItemName     string          `db:"item_name"`
+		Field        string          `db:"field"`
+		Value        json.RawMessage `db:"value"`
+		OriginUserID string          `db:"origin_user_id"`
+		Lamport      int64           `db:"lamport"`
+		WallClock    time.Time       `db:"wall_clock"`
+	}
+	query := `
+		SELECT item_name, field, value, origin_user_id, lamport, wall_clock
+		FROM checklist_item_op
+		WHERE trip_id = (SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1)
+		  AND lamport > $2
+		ORDER BY lamport
+	`
+	if err := s.db.SelectContext(ctx, &rows, query, tripID, sinceLamport); err != nil {
+		return nil, fmt.Errorf("failed to fetch ops: %w", err)
+	}
+
+	ops := make([]syncstore.ChecklistOp, len(rows))
+	for i, r := range rows {
+		var value interface{}
+		if err := json.Unmarshal(r.Value, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode op value: %w", err)
+		}
+		ops[i] = syncstore.ChecklistOp{
+			ItemName:     r.ItemName,
+			Field:        r.Field,
+			Value:        value,
+			OriginUserID: r.OriginUserID,
+			Lamport:      r.Lamport,
+			WallClock:    r.WallClock,
+		}
+	}
+	return ops, nil
+}
+
+// EmitServerOp mints a fresh trip-scoped Lamport counter and appends a
+// single op to the log on behalf of a direct write path (UpdateItem,
+// UpdateItemWithAssignment) rather than ApplyOps' own merge logic - the
+// field's value has already been decided by whichever of those wrote it.
+// It exists so the agent's synchronous edits (see ChecklistTool.UpdateItems)
+// still show up in the CRDT op log an offline peer pulls via GetOpsSince,
+// instead of that peer's GET /ops?since= silently missing them.
+func (s *SQLStore) EmitServerOp(ctx context.Context, tripID, itemName, field string, value interface{}, originUserID string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin op transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// FOR UPDATE on the trip row serializes concurrent EmitServerOp calls for
+	// the same trip (e.g. two agent tool calls in different sessions), so
+	// the MAX(lamport)+1 below can't race another call's read of the same
+	// MAX and mint a duplicate counter - syncstore.Clock's causality tracking
+	// depends on every op's Lamport value actually being unique per trip.
+	var realID string
+	if err := tx.GetContext(ctx, &realID, `SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1 FOR UPDATE`, tripID); err != nil {
+		return fmt.Errorf("failed to resolve trip: %w", err)
+	}
+
+	var lamport int64
+	if err := tx.GetContext(ctx, &lamport, `SELECT COALESCE(MAX(lamport), 0) + 1 FROM checklist_item_op WHERE trip_id = $1`, realID); err != nil {
+		return fmt.Errorf("failed to mint lamport counter: %w", err)
+	}
+
+	op := syncstore.ChecklistOp{
+		ItemName:     itemName,
+		Field:        field,
+		Value:        value,
+		OriginUserID: originUserID,
+		Lamport:      lamport,
+		WallClock:    time.Now(),
+	}
+	if err := insertOp(ctx, tx, realID, op); err != nil {
+		return err
+	}
+
+	var clockJSON json.RawMessage
+	if err := tx.GetContext(ctx, &clockJSON, `SELECT vector_clock FROM checklist_item WHERE trip_id = $1 AND name = $2 FOR UPDATE`, realID, itemName); err != nil {
+		return fmt.Errorf("failed to lock item: %w", err)
+	}
+	clock := syncstore.NewClock()
+	if len(clockJSON) > 0 {
+		if err := json.Unmarshal(clockJSON, &clock); err != nil {
+			return fmt.Errorf("failed to decode vector clock: %w", err)
+		}
+	}
+	clock = clock.Observe(op)
+
+	newClockJSON, err := json.Marshal(clock)
+	if err != nil {
+		return fmt.Errorf("failed to encode vector clock: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE checklist_item SET vector_clock = $1 WHERE trip_id = $2 AND name = $3`, newClockJSON, realID, itemName); err != nil {
+		return fmt.Errorf("failed to persist vector clock: %w", err)
+	}
+
+	return tx.Commit()
+}
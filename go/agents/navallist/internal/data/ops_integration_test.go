@@ -0,0 +1,182 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"navallist/internal/data/syncstore"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestApplyOps_ConcurrentDivergentEditsConverge(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "checklist_item", "trip", "users")
+	defer cleanupData(t, db, "checklist_item", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_ops", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	now := time.Now()
+
+	// Two devices go offline from the same starting point and each record a
+	// flagged issue without having seen the other's edit - both Lamport
+	// counters start at 1, so neither op has observed the other.
+	deviceAOps := []syncstore.ChecklistOp{
+		{ItemName: "Fire Extinguisher", Field: syncstore.FieldFlaggedIssue, Value: "pin is missing", OriginUserID: "deviceA", Lamport: 1, WallClock: now},
+	}
+	deviceBOps := []syncstore.ChecklistOp{
+		{ItemName: "Fire Extinguisher", Field: syncstore.FieldFlaggedIssue, Value: "gauge reads low", OriginUserID: "deviceB", Lamport: 1, WallClock: now.Add(time.Second)},
+	}
+
+	// Apply deviceA's batch first, then deviceB's - in either order the
+	// final merged FlaggedIssue should contain both notes, since they were
+	// concurrent.
+	if _, err := store.ApplyOps(ctx, trip.ID, deviceAOps); err != nil {
+		t.Fatalf("ApplyOps (deviceA) failed: %v", err)
+	}
+	items, err := store.ApplyOps(ctx, trip.ID, deviceBOps)
+	if err != nil {
+		t.Fatalf("ApplyOps (deviceB) failed: %v", err)
+	}
+	if len(items) != 1 || items[0].FlaggedIssue == nil {
+		t.Fatalf("expected one merged item with a flagged issue, got %+v", items)
+	}
+	merged := *items[0].FlaggedIssue
+	if merged != "pin is missing; gauge reads low" {
+		t.Errorf("expected both concurrent notes concatenated, got %q", merged)
+	}
+
+	// A later op from deviceA, now causally aware of deviceB's Lamport
+	// counter, should win the LWW race for IsChecked outright rather than
+	// concatenating.
+	resolve := []syncstore.ChecklistOp{
+		{ItemName: "Fire Extinguisher", Field: syncstore.FieldIsChecked, Value: true, OriginUserID: "deviceA", Lamport: 2, WallClock: now.Add(2 * time.Second)},
+	}
+	items, err = store.ApplyOps(ctx, trip.ID, resolve)
+	if err != nil {
+		t.Fatalf("ApplyOps (resolve) failed: %v", err)
+	}
+	if len(items) != 1 || !items[0].IsChecked {
+		t.Fatalf("expected IsChecked to be true after the causally-later op, got %+v", items)
+	}
+
+	// GetOpsSince should replay every op applied so far, in Lamport order.
+	ops, err := store.GetOpsSince(ctx, trip.ID, 0)
+	if err != nil {
+		t.Fatalf("GetOpsSince failed: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("expected 3 logged ops, got %d: %+v", len(ops), ops)
+	}
+}
+
+func TestEmitServerOp_RecordedForOfflinePeersToPull(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "checklist_item", "trip", "users")
+	defer cleanupData(t, db, "checklist_item", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_ops_emit", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+	if _, err := store.UpdateItem(ctx, trip.ID, "Flares", false, "", "", nil, "", nil, nil); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	if err := store.EmitServerOp(ctx, trip.ID, "Flares", syncstore.FieldIsChecked, true, "agent"); err != nil {
+		t.Fatalf("EmitServerOp failed: %v", err)
+	}
+
+	ops, err := store.GetOpsSince(ctx, trip.ID, 0)
+	if err != nil {
+		t.Fatalf("GetOpsSince failed: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Field != syncstore.FieldIsChecked || ops[0].OriginUserID != "agent" {
+		t.Fatalf("expected the agent's op to be logged, got %+v", ops)
+	}
+}
+
+// TestEmitServerOp_ConcurrentCallsMintUniqueLamports fires EmitServerOp from
+// several goroutines at once for the same trip and asserts every minted
+// Lamport counter came out distinct - a FOR UPDATE-less MAX(lamport)+1 read
+// would let two of these race and mint the same value, which would silently
+// break Clock.IsConcurrentWith's uniqueness assumption for this trip.
+func TestEmitServerOp_ConcurrentCallsMintUniqueLamports(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "checklist_item", "trip", "users")
+	defer cleanupData(t, db, "checklist_item", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_ops_concurrent", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+	if _, err := store.UpdateItem(ctx, trip.ID, "Flares", false, "", "", nil, "", nil, nil); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.EmitServerOp(ctx, trip.ID, "Flares", syncstore.FieldIsChecked, i%2 == 0, "agent")
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("EmitServerOp failed: %v", err)
+		}
+	}
+
+	ops, err := store.GetOpsSince(ctx, trip.ID, 0)
+	if err != nil {
+		t.Fatalf("GetOpsSince failed: %v", err)
+	}
+	if len(ops) != callers {
+		t.Fatalf("expected %d logged ops, got %d: %+v", callers, len(ops), ops)
+	}
+	seen := make(map[int64]bool, callers)
+	for _, op := range ops {
+		if seen[op.Lamport] {
+			t.Fatalf("duplicate lamport counter %d minted across concurrent EmitServerOp calls: %+v", op.Lamport, ops)
+		}
+		seen[op.Lamport] = true
+	}
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRefreshTokenInvalid is returned by ConsumeRefreshToken when the token
+// doesn't exist or has already expired.
+var ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+
+// SaveRefreshToken persists a refresh token issued to userID, valid for ttl.
+func (s *SQLStore) SaveRefreshToken(ctx context.Context, token, userID string, ttl time.Duration) error {
+	query := `
+		INSERT INTO refresh_token (token, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, query, token, userID, now.Add(ttl), now)
+	return err
+}
+
+// ConsumeRefreshToken looks up the user a refresh token was issued to and
+// deletes it so it cannot be replayed, returning ErrRefreshTokenInvalid if
+// the token is unknown or expired.
+func (s *SQLStore) ConsumeRefreshToken(ctx context.Context, token string) (string, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row struct {
+		UserID    string    `db:"user_id"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	err = tx.GetContext(ctx, &row, `SELECT user_id, expires_at FROM refresh_token WHERE token = $1 FOR UPDATE`, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrRefreshTokenInvalid
+		}
+		return "", err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM refresh_token WHERE token = $1`, token); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	if time.Now().After(row.ExpiresAt) {
+		return "", ErrRefreshTokenInvalid
+	}
+	return row.UserID, nil
+}
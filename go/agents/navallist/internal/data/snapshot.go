@@ -0,0 +1,212 @@
+package data
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"navallist/internal/data/models"
+)
+
+// snapshotManifest is the JSON document stored as manifest.json at the root
+// of a trip snapshot archive.
+type snapshotManifest struct {
+	Trip      models.Trip            `json:"trip"`
+	Items     []models.ChecklistItem `json:"items"`
+	Artifacts []models.Artifact      `json:"artifacts"`
+}
+
+// SnapshotTrip bundles a trip's row, checklist items, and artifacts
+// (including the artifact bytes pulled from storage) into a single
+// tar+gzip archive suitable for backup or local-to-cloud migration.
+func SnapshotTrip(ctx context.Context, store Store, storage BlobStorage, tripID string) ([]byte, error) {
+	trip, err := store.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to load trip: %w", err)
+	}
+
+	items, err := store.GetTripReport(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to load checklist items: %w", err)
+	}
+
+	// GetTripReport already embeds photos per item, but artifacts not yet
+	// linked to an item (or trip-level artifacts) need a separate fetch.
+	artifacts, err := store.ListArtifactsByTrip(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to load artifacts: %w", err)
+	}
+
+	manifest := snapshotManifest{Trip: *trip, Items: items, Artifacts: artifacts}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: failed to marshal manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	if err := writeTarFile(tw, "manifest.json", manifestJSON); err != nil {
+		return nil, err
+	}
+
+	for _, art := range artifacts {
+		blob, err := storage.Load(ctx, art.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: failed to load artifact %s from storage: %w", art.ID, err)
+		}
+		if err := writeTarFile(tw, path.Join("blobs", art.ID), blob); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("snapshot: failed to close tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("snapshot: failed to close gzip writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RestoreTrip unpacks a snapshot archive produced by SnapshotTrip into a
+// brand-new trip: a new trip ID is minted, artifacts are re-uploaded to the
+// current storage backend, and checklist items are re-applied through the
+// same UPSERT path the live app uses. Re-running a partial restore reuses
+// the same restored trip and upserts its items again, but records a fresh
+// artifact row for every bundled blob on each run.
+func RestoreTrip(ctx context.Context, store Store, storage BlobStorage, blob []byte) error {
+	manifest, blobs, err := readSnapshot(blob)
+	if err != nil {
+		return err
+	}
+
+	userID := ""
+	if manifest.Trip.UserID != nil {
+		userID = *manifest.Trip.UserID
+	}
+	captainName := ""
+	if manifest.Trip.CaptainName != nil {
+		captainName = *manifest.Trip.CaptainName
+	}
+
+	// GetOrCreateTrip mints a fresh trip row keyed by a new ADK session ID,
+	// which is how the rest of the app creates trip UUIDs.
+	newTrip, err := store.GetOrCreateTrip(ctx, "restored_"+manifest.Trip.ID, userID, captainName, manifest.Trip.TripType)
+	if err != nil {
+		return fmt.Errorf("restore: failed to create trip: %w", err)
+	}
+
+	// Re-upload artifact bytes first so checklist items can reference the
+	// freshly minted artifact IDs when linking photos.
+	artifactIDMap := make(map[string]string, len(manifest.Artifacts))
+	for _, art := range manifest.Artifacts {
+		raw, ok := blobs[art.ID]
+		if !ok {
+			continue // manifest referenced a blob that wasn't bundled; skip it
+		}
+
+		mimeType := ""
+		if art.MimeType != nil {
+			mimeType = *art.MimeType
+		}
+
+		storagePath, err := storage.Save(ctx, art.Filename, raw, mimeType)
+		if err != nil {
+			return fmt.Errorf("restore: failed to re-upload artifact %s: %w", art.ID, err)
+		}
+
+		newArt, err := store.CreateArtifact(ctx, newTrip.ID, art.Filename, mimeType, storagePath)
+		if err != nil {
+			return fmt.Errorf("restore: failed to create artifact record for %s: %w", art.ID, err)
+		}
+		artifactIDMap[art.ID] = newArt.ID
+	}
+
+	for _, item := range manifest.Items {
+		location := ""
+		if item.LocationText != nil {
+			location = *item.LocationText
+		}
+		completedByName := ""
+		if item.CompletedByName != nil {
+			completedByName = *item.CompletedByName
+		}
+
+		if _, err := store.UpdateItem(ctx, newTrip.ID, item.Name, item.IsChecked, location, "", item.CompletedByUserID, completedByName, item.AssignedToUserID, item.AssignedToName); err != nil {
+			return fmt.Errorf("restore: failed to restore item %s: %w", item.Name, err)
+		}
+
+		for _, photo := range item.Photos {
+			newArtifactID, ok := artifactIDMap[photo.ID]
+			if !ok {
+				continue
+			}
+			if _, err := store.AddItemPhoto(ctx, newTrip.ID, item.Name, newArtifactID); err != nil {
+				return fmt.Errorf("restore: failed to relink photo for item %s: %w", item.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, contents []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(contents)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("snapshot: failed to write tar header for %s: %w", name, err)
+	}
+	if _, err := tw.Write(contents); err != nil {
+		return fmt.Errorf("snapshot: failed to write tar contents for %s: %w", name, err)
+	}
+	return nil
+}
+
+func readSnapshot(blob []byte) (snapshotManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(blob))
+	if err != nil {
+		return snapshotManifest{}, nil, fmt.Errorf("restore: failed to open gzip stream: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	var manifest snapshotManifest
+	blobs := make(map[string][]byte)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return snapshotManifest{}, nil, fmt.Errorf("restore: failed to read tar entry: %w", err)
+		}
+
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return snapshotManifest{}, nil, fmt.Errorf("restore: failed to read %s: %w", hdr.Name, err)
+		}
+
+		switch {
+		case hdr.Name == "manifest.json":
+			if err := json.Unmarshal(contents, &manifest); err != nil {
+				return snapshotManifest{}, nil, fmt.Errorf("restore: failed to parse manifest: %w", err)
+			}
+		case path.Dir(hdr.Name) == "blobs":
+			blobs[path.Base(hdr.Name)] = contents
+		}
+	}
+
+	return manifest, blobs, nil
+}
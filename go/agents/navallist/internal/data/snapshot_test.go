@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"navallist/internal/data/models"
+)
+
+func TestSnapshotAndRestoreTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "snapshot-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	storage := NewDiskStorage(tmpDir)
+	ctx := context.Background()
+
+	photoPath, err := storage.Save(ctx, "photo.jpg", []byte("fake jpeg bytes"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	captain := "Captain Steve"
+	mimeType := "image/jpeg"
+	originalTrip := models.Trip{ID: "trip_1", CaptainName: &captain, TripType: "Departing"}
+	originalArtifact := models.Artifact{ID: "art_1", Filename: "photo.jpg", MimeType: &mimeType, StoragePath: photoPath}
+	originalItem := models.ChecklistItem{
+		TripID:    "trip_1",
+		Name:      "Engine Oil",
+		IsChecked: true,
+		Photos:    []models.Artifact{originalArtifact},
+	}
+
+	var restoredTripID string
+	var restoredArtifactStoragePath string
+
+	store := &MockStore{
+		GetTripFunc: func(_ context.Context, tripID string) (*models.Trip, error) {
+			return &originalTrip, nil
+		},
+		GetTripReportFunc: func(_ context.Context, tripID string) ([]models.ChecklistItem, error) {
+			return []models.ChecklistItem{originalItem}, nil
+		},
+		ListArtifactsByTripFunc: func(_ context.Context, tripID string) ([]models.Artifact, error) {
+			return []models.Artifact{originalArtifact}, nil
+		},
+		GetOrCreateTripFunc: func(_ context.Context, adkSessionID, userID, captainName, tripType string) (*models.Trip, error) {
+			restoredTripID = "new_trip_1"
+			return &models.Trip{ID: restoredTripID, CaptainName: &captainName, TripType: tripType}, nil
+		},
+		CreateArtifactFunc: func(_ context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error) {
+			restoredArtifactStoragePath = storagePath
+			return &models.Artifact{ID: "new_art_1", TripID: &tripID, Filename: filename, StoragePath: storagePath}, nil
+		},
+		UpdateItemFunc: func(_ context.Context, tripID, itemName string, isChecked bool, location string, photoArtifactID string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error) {
+			return &models.ChecklistItem{TripID: tripID, Name: itemName, IsChecked: isChecked}, nil
+		},
+		AddItemPhotoFunc: func(_ context.Context, tripID, itemName, photoArtifactID string) (*models.ChecklistItem, error) {
+			if photoArtifactID != "new_art_1" {
+				t.Errorf("AddItemPhoto() artifactID = %q, want new_art_1", photoArtifactID)
+			}
+			return &models.ChecklistItem{TripID: tripID, Name: itemName}, nil
+		},
+	}
+
+	blob, err := SnapshotTrip(ctx, store, storage, "trip_1")
+	if err != nil {
+		t.Fatalf("SnapshotTrip() error = %v", err)
+	}
+	if len(blob) == 0 {
+		t.Fatalf("SnapshotTrip() returned empty archive")
+	}
+
+	if err := RestoreTrip(ctx, store, storage, blob); err != nil {
+		t.Fatalf("RestoreTrip() error = %v", err)
+	}
+
+	if restoredTripID == "" {
+		t.Errorf("RestoreTrip() did not create a new trip")
+	}
+	if restoredArtifactStoragePath == "" {
+		t.Errorf("RestoreTrip() did not re-upload the artifact")
+	}
+}
@@ -2,24 +2,101 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
+
+	"navallist/internal/auth"
 )
 
 // BlobStorage defines the interface for saving and retrieving files.
+// Implementations are selected at startup via NAVALLIST_STORAGE_KIND, and the
+// path they hand back is persisted verbatim in artifact.storage_path, so it
+// must be self-describing enough for any backend to load it later (e.g. a
+// "s3://bucket/key" or "webdav://host/path" URI rather than a bare filename).
 type BlobStorage interface {
 	// Save stores the data and returns a reference path (or URL) to it.
 	Save(ctx context.Context, filename string, data []byte, contentType string) (string, error)
+	// SaveStream is like Save but reads from r incrementally instead of
+	// requiring the caller to hold the whole file in memory first, so large
+	// uploads don't have to be fully buffered before being written out.
+	// Returns the reference path and the number of bytes written.
+	SaveStream(ctx context.Context, filename string, r io.Reader, contentType string) (path string, size int64, err error)
 	// Load retrieves the data given the reference path.
 	Load(ctx context.Context, path string) ([]byte, error)
+	// Delete removes the data at the given reference path.
+	Delete(ctx context.Context, path string) error
 	// GetPublicURL returns a public URL for the file if supported.
 	GetPublicURL(path string) string
+	// SignedURL returns a time-limited URL for retrieving path, expiring
+	// after ttl and bound to opts so a leaked URL can't be replayed against
+	// a different artifact or trip. S3 hands back a presigned URL pointing
+	// straight at the bucket; DiskStorage signs an HMAC token redeemed by
+	// this server's own /artifacts/signed/{token} handler. WebDAV has no
+	// native signing mechanism and returns an error.
+	SignedURL(ctx context.Context, path string, ttl time.Duration, opts SignedURLOptions) (url string, expiresAt time.Time, err error)
+	// Exists reports whether path is actually present in the backend,
+	// without fetching its bytes - used by internal/storagereconciler to
+	// confirm a PresignUploader direct upload landed.
+	Exists(ctx context.Context, path string) (bool, error)
+}
+
+// PresignUploader is implemented by BlobStorage backends that can hand a
+// client a URL to PUT an object's bytes to directly, bypassing this server
+// for the upload itself (see POST /api/artifacts/presign). DiskStorage and
+// WebDAVStorage have no such mechanism and don't implement it.
+type PresignUploader interface {
+	// SignedPutURL returns a presigned PUT URL for filename valid for ttl,
+	// plus the storage_path reference the object will be reachable at once
+	// the client's upload completes.
+	SignedPutURL(ctx context.Context, filename, contentType string, ttl time.Duration) (path, url string, expiresAt time.Time, err error)
+}
+
+// SignedURLOptions binds a signed URL to the artifact/trip it was issued
+// for. DiskStorage encodes these into its token so a forged or replayed URL
+// can't be used to reach a different artifact; S3 doesn't need them beyond
+// the path itself since AWS's presigning already scopes the URL to one key.
+type SignedURLOptions struct {
+	ArtifactID string
+	TripID     string
+	UserID     string
+}
+
+// SignedURLClaim is recovered from a token minted by DiskStorage.SignedURL.
+type SignedURLClaim struct {
+	Path       string
+	ArtifactID string
+	TripID     string
+	UserID     string
+}
+
+// SignedURLVerifier is implemented by BlobStorage backends whose SignedURL
+// redeems its own token (currently just DiskStorage) rather than handing
+// back a URL a cloud provider serves directly.
+type SignedURLVerifier interface {
+	VerifySignedURL(token string) (SignedURLClaim, error)
+}
+
+// ContentTypeReader is implemented by BlobStorage backends that can recover
+// the content type a file was Saved with directly from the backend, without
+// a caller needing to already have it on hand (e.g. from an artifact's DB
+// row). Only DiskStorage implements it, reading a small sidecar file it
+// writes alongside the blob since the filesystem has no such metadata
+// slot; the S3, GCS and Azure backends do not implement it.
+type ContentTypeReader interface {
+	ContentType(ctx context.Context, path string) (string, error)
 }
 
 // DiskStorage implements BlobStorage using the local filesystem.
 type DiskStorage struct {
 	BaseDir string
+
+	// SignSecret signs the tokens minted by SignedURL. SignedURL and
+	// VerifySignedURL both return an error if it's empty.
+	SignSecret []byte
 }
 
 // NewDiskStorage creates a new DiskStorage with the given base directory.
@@ -31,7 +108,7 @@ func NewDiskStorage(baseDir string) *DiskStorage {
 }
 
 // Save stores the data to the local disk.
-func (s *DiskStorage) Save(_ context.Context, filename string, data []byte, _ string) (string, error) {
+func (s *DiskStorage) Save(_ context.Context, filename string, data []byte, contentType string) (string, error) {
 	filePath := filepath.Join(s.BaseDir, filename)
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to write to disk: %w", err)
@@ -39,17 +116,148 @@ func (s *DiskStorage) Save(_ context.Context, filename string, data []byte, _ st
 	// Return absolute path so os.ReadFile works
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return filePath, nil // Fallback to relative
+		absPath = filePath // Fallback to relative
+	}
+	if err := writeContentTypeSidecar(absPath, contentType); err != nil {
+		return "", err
 	}
 	return absPath, nil
 }
 
+// SaveStream streams r to disk without buffering it in memory first.
+func (s *DiskStorage) SaveStream(_ context.Context, filename string, r io.Reader, contentType string) (string, int64, error) {
+	filePath := filepath.Join(s.BaseDir, filename)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create file on disk: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	size, err := io.Copy(f, r)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write to disk: %w", err)
+	}
+
+	// Return absolute path so os.ReadFile works
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		absPath = filePath // Fallback to relative
+	}
+	if err := writeContentTypeSidecar(absPath, contentType); err != nil {
+		return "", 0, err
+	}
+	return absPath, size, nil
+}
+
 // Load retrieves the data from the local disk.
 func (s *DiskStorage) Load(_ context.Context, path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+// Delete removes the file from the local disk.
+func (s *DiskStorage) Delete(_ context.Context, path string) error {
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete from disk: %w", err)
+	}
+	_ = os.Remove(contentTypeSidecarPath(path)) // best-effort; a missing sidecar is not an error
+	return nil
+}
+
+// ContentType returns the content type path was Saved/SaveStream'd with, or
+// "" if no sidecar was written for it (e.g. a file that predates this
+// field, or one written with an empty contentType).
+func (s *DiskStorage) ContentType(_ context.Context, path string) (string, error) {
+	b, err := os.ReadFile(contentTypeSidecarPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read content-type sidecar: %w", err)
+	}
+	return string(b), nil
+}
+
+// contentTypeSidecarPath is where DiskStorage records path's content type,
+// since plain files on disk have no metadata slot for it the way S3/GCS/Azure
+// objects do.
+func contentTypeSidecarPath(path string) string {
+	return path + ".contenttype"
+}
+
+// writeContentTypeSidecar records contentType for path, skipping the write
+// entirely if the caller didn't supply one rather than persisting an empty
+// sidecar.
+func writeContentTypeSidecar(path, contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	if err := os.WriteFile(contentTypeSidecarPath(path), []byte(contentType), 0644); err != nil {
+		return fmt.Errorf("failed to write content-type sidecar: %w", err)
+	}
+	return nil
+}
+
 // GetPublicURL for local disk returns an empty string as it is not supported.
 func (s *DiskStorage) GetPublicURL(_ string) string {
 	return ""
 }
+
+// Exists reports whether path is present on disk.
+func (s *DiskStorage) Exists(_ context.Context, path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("disk storage: failed to stat %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// signedArtifactURLRoute is the handler path a disk-backed SignedURL points
+// at; it must match the route registered in internal/server/routes.go.
+const signedArtifactURLRoute = "/artifacts/signed/"
+
+// SignedURL mints an HMAC-signed token for path via internal/auth and
+// returns a URL pointing at this server's own /artifacts/signed/{token}
+// handler, which verifies the token with VerifySignedURL before serving it.
+func (s *DiskStorage) SignedURL(_ context.Context, path string, ttl time.Duration, opts SignedURLOptions) (string, time.Time, error) {
+	if len(s.SignSecret) == 0 {
+		return "", time.Time{}, fmt.Errorf("disk storage: signed URLs require a SignSecret")
+	}
+
+	claim, err := json.Marshal(SignedURLClaim{
+		Path:       path,
+		ArtifactID: opts.ArtifactID,
+		TripID:     opts.TripID,
+		UserID:     opts.UserID,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("disk storage: failed to encode signed url claim: %w", err)
+	}
+
+	token, err := auth.SignToken(s.SignSecret, string(claim), ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("disk storage: failed to sign url: %w", err)
+	}
+
+	return signedArtifactURLRoute + token, time.Now().Add(ttl), nil
+}
+
+// VerifySignedURL validates a token minted by SignedURL and recovers the
+// claim it was bound to.
+func (s *DiskStorage) VerifySignedURL(token string) (SignedURLClaim, error) {
+	if len(s.SignSecret) == 0 {
+		return SignedURLClaim{}, fmt.Errorf("disk storage: signed URLs require a SignSecret")
+	}
+
+	raw, err := auth.VerifyToken(s.SignSecret, token)
+	if err != nil {
+		return SignedURLClaim{}, err
+	}
+
+	var claim SignedURLClaim
+	if err := json.Unmarshal([]byte(raw), &claim); err != nil {
+		return SignedURLClaim{}, fmt.Errorf("disk storage: malformed signed url claim: %w", err)
+	}
+	return claim, nil
+}
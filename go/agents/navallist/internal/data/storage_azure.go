@@ -0,0 +1,234 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+// AzureStorage implements BlobStorage against an Azure Blob Storage
+// container, using a shared-key credential for both requests and SAS
+// signing.
+type AzureStorage struct {
+	client    *azblob.Client
+	cred      *azblob.SharedKeyCredential
+	container string
+
+	// EncryptionScope, if set, requests the named encryption scope for
+	// every object this backend writes. Leaving it empty uses the
+	// container's default (Microsoft-managed key or account-level CMK).
+	EncryptionScope string
+}
+
+// NewAzureStorage creates a new AzureStorage bound to the given container,
+// authenticating with an account shared key (the Azure equivalent of S3's
+// access/secret key pair).
+func NewAzureStorage(accountName, accountKey, containerName string) (*AzureStorage, error) {
+	if containerName == "" {
+		return nil, fmt.Errorf("azure storage: container is required")
+	}
+
+	cred, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("azure storage: failed to build shared key credential: %w", err)
+	}
+
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+	client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azure storage: failed to create client: %w", err)
+	}
+
+	return &AzureStorage{client: client, cred: cred, container: containerName}, nil
+}
+
+// Save uploads the data and returns a "azblob://container/blob" reference.
+func (s *AzureStorage) Save(ctx context.Context, filename string, data []byte, contentType string) (string, error) {
+	_, err := s.client.UploadBuffer(ctx, s.container, filename, data, &azblob.UploadBufferOptions{
+		HTTPHeaders:     blobHTTPHeaders(contentType),
+		EncryptionScope: emptyToNil(s.EncryptionScope),
+	})
+	if err != nil {
+		return "", fmt.Errorf("azure storage: failed to upload blob: %w", err)
+	}
+	return s.path(filename), nil
+}
+
+// SaveStream uploads r without requiring the caller to buffer it first, and
+// returns a "azblob://container/blob" reference and the number of bytes
+// written.
+func (s *AzureStorage) SaveStream(ctx context.Context, filename string, r io.Reader, contentType string) (string, int64, error) {
+	counting := &countingReader{r: r}
+	_, err := s.client.UploadStream(ctx, s.container, filename, counting, &azblob.UploadStreamOptions{
+		HTTPHeaders:     blobHTTPHeaders(contentType),
+		EncryptionScope: emptyToNil(s.EncryptionScope),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("azure storage: failed to upload blob: %w", err)
+	}
+	return s.path(filename), counting.n, nil
+}
+
+// Load retrieves the blob referenced by a "azblob://container/blob" path.
+func (s *AzureStorage) Load(ctx context.Context, path string) ([]byte, error) {
+	_, key, err := parseAzurePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.DownloadStream(ctx, s.container, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azure storage: failed to download blob: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return nil, fmt.Errorf("azure storage: failed to read blob body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Delete removes the blob referenced by a "azblob://container/blob" path.
+func (s *AzureStorage) Delete(ctx context.Context, path string) error {
+	_, key, err := parseAzurePath(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.client.DeleteBlob(ctx, s.container, key, nil); err != nil {
+		return fmt.Errorf("azure storage: failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// GetPublicURL returns a SAS-signed GET URL valid for an hour, or an empty
+// string if signing fails (callers fall back to Load in that case).
+func (s *AzureStorage) GetPublicURL(path string) string {
+	url, _, err := s.SignedURL(context.Background(), path, time.Hour, SignedURLOptions{})
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// SignedURL returns a SAS-signed GET URL valid for ttl. opts is unused: like
+// S3/GCS, the signed URL already scopes access to the one container/blob it
+// was minted for.
+func (s *AzureStorage) SignedURL(_ context.Context, path string, ttl time.Duration, _ SignedURLOptions) (string, time.Time, error) {
+	return s.signedURL(path, ttl, sas.BlobPermissions{Read: true})
+}
+
+// Exists reports whether the blob referenced by a "azblob://container/blob"
+// path is present, via a GetProperties call rather than fetching its bytes.
+func (s *AzureStorage) Exists(ctx context.Context, path string) (bool, error) {
+	_, key, err := parseAzurePath(path)
+	if err != nil {
+		return false, err
+	}
+
+	blobClient := s.client.ServiceClient().NewContainerClient(s.container).NewBlobClient(key)
+	if _, err := blobClient.GetProperties(ctx, nil); err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("azure storage: failed to get blob properties: %w", err)
+	}
+	return true, nil
+}
+
+// SignedPutURL returns a SAS-signed PUT URL for filename valid for ttl,
+// plus the "azblob://container/blob" reference the object will be reachable
+// at once the client's direct upload completes.
+func (s *AzureStorage) SignedPutURL(_ context.Context, filename, _ string, ttl time.Duration) (string, string, time.Time, error) {
+	url, expiresAt, err := s.signedURL(s.path(filename), ttl, sas.BlobPermissions{Write: true, Create: true})
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return s.path(filename), url, expiresAt, nil
+}
+
+// EnsureBucket creates the backend's container if it doesn't already exist,
+// so a fresh deployment pointed at an empty storage account doesn't fail
+// its first upload. It's a no-op (not an error) if the container is already
+// there.
+func (s *AzureStorage) EnsureBucket(ctx context.Context) error {
+	containerClient := s.client.ServiceClient().NewContainerClient(s.container)
+	if _, err := containerClient.GetProperties(ctx, nil); err == nil {
+		return nil
+	} else if !bloberror.HasCode(err, bloberror.ContainerNotFound) {
+		return fmt.Errorf("azure storage: failed to check container %q: %w", s.container, err)
+	}
+
+	if _, err := containerClient.Create(ctx, nil); err != nil {
+		if bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+			return nil
+		}
+		return fmt.Errorf("azure storage: failed to create container %q: %w", s.container, err)
+	}
+	return nil
+}
+
+// signedURL mints a SAS URL for path scoped to perms, shared by SignedURL
+// and SignedPutURL.
+func (s *AzureStorage) signedURL(path string, ttl time.Duration, perms sas.BlobPermissions) (string, time.Time, error) {
+	_, key, err := parseAzurePath(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	values := sas.BlobSignatureValues{
+		Protocol:      sas.ProtocolHTTPS,
+		ExpiryTime:    expiresAt,
+		ContainerName: s.container,
+		BlobName:      key,
+		Permissions:   perms.String(),
+	}
+	qp, err := values.SignWithSharedKey(s.cred)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("azure storage: failed to sign url: %w", err)
+	}
+
+	blobURL := fmt.Sprintf("%s%s/%s", s.client.ServiceClient().URL(), s.container, key)
+	return blobURL + "?" + qp.Encode(), expiresAt, nil
+}
+
+func (s *AzureStorage) path(filename string) string {
+	return fmt.Sprintf("azblob://%s/%s", s.container, filename)
+}
+
+// parseAzurePath splits a "azblob://container/blob" reference into its parts.
+func parseAzurePath(path string) (containerName, key string, err error) {
+	trimmed := strings.TrimPrefix(path, "azblob://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("azure storage: invalid path %q", path)
+	}
+	return parts[0], parts[1], nil
+}
+
+// blobHTTPHeaders builds the HTTPHeaders value carrying contentType, broken
+// out since both Save and SaveStream need it.
+func blobHTTPHeaders(contentType string) *azblob.BlobHTTPHeaders {
+	return &azblob.BlobHTTPHeaders{BlobContentType: to.Ptr(contentType)}
+}
+
+// emptyToNil turns an empty encryption scope into a nil pointer, so the
+// Azure SDK falls back to the container's default instead of requesting a
+// scope named "".
+func emptyToNil(scope string) *string {
+	if scope == "" {
+		return nil
+	}
+	return &scope
+}
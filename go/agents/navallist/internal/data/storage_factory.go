@@ -0,0 +1,112 @@
+package data
+
+import (
+	"context"
+	"fmt"
+)
+
+// StorageConfig carries the backend-specific settings needed to construct a
+// BlobStorage. Only the fields relevant to the selected Kind are used.
+type StorageConfig struct {
+	Kind string // "disk" (default), "s3", "gcs", "azure", or "webdav"
+
+	DiskBaseDir string
+
+	S3Bucket    string
+	S3Region    string
+	S3Endpoint  string
+	S3AccessKey string
+	S3SecretKey string
+	// S3SSEAlgorithm/S3SSEKMSKeyID request server-side encryption on every
+	// object S3Storage writes; see S3Storage.SSEAlgorithm.
+	S3SSEAlgorithm string
+	S3SSEKMSKeyID  string
+
+	GCSBucket string
+	// GCSSignServiceAccount/GCSSignPrivateKey are only needed to mint signed
+	// URLs (see GCSStorage.SignedURL); GCS reads/writes themselves use
+	// Application Default Credentials regardless.
+	GCSSignServiceAccount string
+	GCSSignPrivateKey     string
+	// GCSKMSKeyName requests customer-managed encryption on every object
+	// GCSStorage writes; see GCSStorage.KMSKeyName.
+	GCSKMSKeyName string
+	// GCSProjectID is only needed for EnsureBucket to create GCSBucket if
+	// it doesn't already exist; see GCSStorage.ProjectID.
+	GCSProjectID string
+
+	AzureAccountName string
+	AzureAccountKey  string
+	AzureContainer   string
+	// AzureEncryptionScope requests the named encryption scope for every
+	// object AzureStorage writes; see AzureStorage.EncryptionScope.
+	AzureEncryptionScope string
+
+	WebDAVBaseURL  string
+	WebDAVUsername string
+	WebDAVPassword string
+
+	// SignedURLSecret signs the tokens DiskStorage.SignedURL mints; it plays
+	// no role for the s3/gcs/azure/webdav backends. Callers typically reuse
+	// the same secret that signs session cookies and connect tokens.
+	SignedURLSecret []byte
+}
+
+// cloudBucketEnsurer is implemented by the cloud BlobStorage backends that
+// need their bucket/container created on first use (S3Storage, GCSStorage,
+// AzureStorage); DiskStorage/WebDAVStorage have nothing analogous to
+// provision, so NewStorage only calls this for backends that implement it.
+type cloudBucketEnsurer interface {
+	EnsureBucket(ctx context.Context) error
+}
+
+// NewStorage constructs the BlobStorage backend selected by cfg.Kind. For
+// the cloud backends (s3/gcs/azure), it also calls EnsureBucket so a fresh
+// deployment pointed at an empty bucket/container provisions it instead of
+// failing its first upload.
+func NewStorage(ctx context.Context, cfg StorageConfig) (BlobStorage, error) {
+	var storage BlobStorage
+
+	switch cfg.Kind {
+	case "", "disk":
+		ds := NewDiskStorage(cfg.DiskBaseDir)
+		ds.SignSecret = cfg.SignedURLSecret
+		return ds, nil
+	case "s3":
+		s3s, err := NewS3Storage(ctx, cfg.S3Bucket, cfg.S3Region, cfg.S3Endpoint, cfg.S3AccessKey, cfg.S3SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		s3s.SSEAlgorithm = cfg.S3SSEAlgorithm
+		s3s.SSEKMSKeyID = cfg.S3SSEKMSKeyID
+		storage = s3s
+	case "gcs":
+		gs, err := NewGCSStorage(ctx, cfg.GCSBucket)
+		if err != nil {
+			return nil, err
+		}
+		gs.SignServiceAccount = cfg.GCSSignServiceAccount
+		gs.SignPrivateKey = []byte(cfg.GCSSignPrivateKey)
+		gs.KMSKeyName = cfg.GCSKMSKeyName
+		gs.ProjectID = cfg.GCSProjectID
+		storage = gs
+	case "azure":
+		as, err := NewAzureStorage(cfg.AzureAccountName, cfg.AzureAccountKey, cfg.AzureContainer)
+		if err != nil {
+			return nil, err
+		}
+		as.EncryptionScope = cfg.AzureEncryptionScope
+		storage = as
+	case "webdav":
+		return NewWebDAVStorage(cfg.WebDAVBaseURL, cfg.WebDAVUsername, cfg.WebDAVPassword), nil
+	default:
+		return nil, fmt.Errorf("unknown storage kind %q", cfg.Kind)
+	}
+
+	if ensurer, ok := storage.(cloudBucketEnsurer); ok {
+		if err := ensurer.EnsureBucket(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return storage, nil
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestNewStorage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "storage-factory-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		cfg     StorageConfig
+		wantErr bool
+	}{
+		{
+			name: "Disk default",
+			cfg:  StorageConfig{DiskBaseDir: tmpDir},
+		},
+		{
+			name: "Explicit disk",
+			cfg:  StorageConfig{Kind: "disk", DiskBaseDir: tmpDir},
+		},
+		{
+			name: "WebDAV",
+			cfg:  StorageConfig{Kind: "webdav", WebDAVBaseURL: "https://example.com/dav"},
+		},
+		{
+			name:    "Unknown kind",
+			cfg:     StorageConfig{Kind: "tape"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := NewStorage(ctx, tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewStorage() error = %v", err)
+			}
+			if storage == nil {
+				t.Fatalf("expected non-nil storage")
+			}
+		})
+	}
+}
+
+func TestNewStorage_ThreadsSignedURLSecretForDisk(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "storage-factory-secret-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	storage, err := NewStorage(context.Background(), StorageConfig{
+		Kind:            "disk",
+		DiskBaseDir:     tmpDir,
+		SignedURLSecret: []byte("factory-secret"),
+	})
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+
+	ds, ok := storage.(*DiskStorage)
+	if !ok {
+		t.Fatalf("expected *DiskStorage, got %T", storage)
+	}
+	if string(ds.SignSecret) != "factory-secret" {
+		t.Errorf("Expected SignSecret to be threaded from cfg, got %q", ds.SignSecret)
+	}
+}
@@ -0,0 +1,232 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCSStorage implements BlobStorage against a Google Cloud Storage bucket.
+type GCSStorage struct {
+	client *storage.Client
+	bucket string
+
+	// SignServiceAccount/SignPrivateKey are required to mint a SignedURL:
+	// GCS V4 signing happens client-side against a service account's
+	// private key rather than through an API call, so unlike S3Storage
+	// there's no way to derive them from the client's ambient credentials
+	// alone if those credentials came from the metadata server instead of a
+	// JSON key file.
+	SignServiceAccount string
+	SignPrivateKey     []byte
+
+	// KMSKeyName, if set, requests customer-managed encryption on every
+	// object this backend writes (projects/P/locations/L/keyRings/R/cryptoKeys/K).
+	// Leaving it empty uses GCS's default server-side (Google-managed) encryption.
+	KMSKeyName string
+
+	// ProjectID is only needed by EnsureBucket, which must name a project
+	// to bill a newly created bucket to; Save/Load/Delete don't need it
+	// since they operate on an already-named bucket.
+	ProjectID string
+}
+
+// NewGCSStorage creates a new GCSStorage bound to the given bucket, using
+// Application Default Credentials (a service account JSON file pointed to
+// by GOOGLE_APPLICATION_CREDENTIALS, or the metadata server on GCE/GKE).
+func NewGCSStorage(ctx context.Context, bucket string) (*GCSStorage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("gcs storage: bucket is required")
+	}
+
+	client, err := storage.NewClient(ctx, option.WithScopes(storage.ScopeReadWrite))
+	if err != nil {
+		return nil, fmt.Errorf("gcs storage: failed to create client: %w", err)
+	}
+
+	return &GCSStorage{client: client, bucket: bucket}, nil
+}
+
+// Save uploads the data and returns a "gs://bucket/key" reference.
+func (s *GCSStorage) Save(ctx context.Context, filename string, data []byte, contentType string) (string, error) {
+	w := s.object(filename).NewWriter(ctx)
+	w.ContentType = contentType
+	w.KMSKeyName = s.KMSKeyName
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("gcs storage: failed to write object: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("gcs storage: failed to finalize object: %w", err)
+	}
+	return s.path(filename), nil
+}
+
+// SaveStream uploads r without requiring the caller to buffer it first, and
+// returns a "gs://bucket/key" reference and the number of bytes written.
+func (s *GCSStorage) SaveStream(ctx context.Context, filename string, r io.Reader, contentType string) (string, int64, error) {
+	w := s.object(filename).NewWriter(ctx)
+	w.ContentType = contentType
+	w.KMSKeyName = s.KMSKeyName
+	n, err := io.Copy(w, r)
+	if err != nil {
+		_ = w.Close()
+		return "", 0, fmt.Errorf("gcs storage: failed to write object: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", 0, fmt.Errorf("gcs storage: failed to finalize object: %w", err)
+	}
+	return s.path(filename), n, nil
+}
+
+// Load retrieves the object referenced by a "gs://bucket/key" path.
+func (s *GCSStorage) Load(ctx context.Context, path string) ([]byte, error) {
+	bucket, key, err := parseGCSPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.client.Bucket(bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcs storage: failed to read object: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	return io.ReadAll(r)
+}
+
+// Delete removes the object referenced by a "gs://bucket/key" path.
+func (s *GCSStorage) Delete(ctx context.Context, path string) error {
+	bucket, key, err := parseGCSPath(path)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Bucket(bucket).Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("gcs storage: failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// GetPublicURL returns a signed GET URL valid for an hour, or an empty
+// string if signing fails (callers fall back to Load in that case).
+func (s *GCSStorage) GetPublicURL(path string) string {
+	url, _, err := s.SignedURL(context.Background(), path, time.Hour, SignedURLOptions{})
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// SignedURL returns a V4 signed GET URL valid for ttl. opts is unused: like
+// S3, the signed URL already scopes access to the one bucket/key it was
+// minted for. Requires SignServiceAccount/SignPrivateKey to be set.
+func (s *GCSStorage) SignedURL(_ context.Context, path string, ttl time.Duration, _ SignedURLOptions) (string, time.Time, error) {
+	if len(s.SignPrivateKey) == 0 {
+		return "", time.Time{}, fmt.Errorf("gcs storage: signed URLs require SignServiceAccount/SignPrivateKey")
+	}
+
+	_, key, err := parseGCSPath(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	url, err := storage.SignedURL(s.bucket, key, &storage.SignedURLOptions{
+		GoogleAccessID: s.SignServiceAccount,
+		PrivateKey:     s.SignPrivateKey,
+		Method:         "GET",
+		Expires:        expiresAt,
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("gcs storage: failed to sign url: %w", err)
+	}
+	return url, expiresAt, nil
+}
+
+// Exists reports whether the object referenced by a "gs://bucket/key" path
+// is present, via an Attrs call rather than fetching its bytes.
+func (s *GCSStorage) Exists(ctx context.Context, path string) (bool, error) {
+	bucket, key, err := parseGCSPath(path)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.client.Bucket(bucket).Object(key).Attrs(ctx); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("gcs storage: failed to stat object: %w", err)
+	}
+	return true, nil
+}
+
+// SignedPutURL returns a presigned V4 PUT URL for filename valid for ttl,
+// plus the "gs://bucket/key" reference the object will be reachable at once
+// the client's direct upload completes. Requires
+// SignServiceAccount/SignPrivateKey to be set.
+func (s *GCSStorage) SignedPutURL(_ context.Context, filename, contentType string, ttl time.Duration) (string, string, time.Time, error) {
+	if len(s.SignPrivateKey) == 0 {
+		return "", "", time.Time{}, fmt.Errorf("gcs storage: signed URLs require SignServiceAccount/SignPrivateKey")
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	url, err := storage.SignedURL(s.bucket, filename, &storage.SignedURLOptions{
+		GoogleAccessID: s.SignServiceAccount,
+		PrivateKey:     s.SignPrivateKey,
+		Method:         "PUT",
+		Expires:        expiresAt,
+		ContentType:    contentType,
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("gcs storage: failed to sign put url: %w", err)
+	}
+	return s.path(filename), url, expiresAt, nil
+}
+
+// EnsureBucket creates the backend's bucket if it doesn't already exist, so
+// a fresh deployment pointed at an empty project doesn't fail its first
+// upload. Requires ProjectID to be set; it's a no-op (not an error) if the
+// bucket is already there.
+func (s *GCSStorage) EnsureBucket(ctx context.Context) error {
+	bucket := s.client.Bucket(s.bucket)
+	if _, err := bucket.Attrs(ctx); err == nil {
+		return nil
+	} else if !errors.Is(err, storage.ErrBucketNotExist) {
+		return fmt.Errorf("gcs storage: failed to check bucket %q: %w", s.bucket, err)
+	}
+
+	if s.ProjectID == "" {
+		return fmt.Errorf("gcs storage: ProjectID is required to create bucket %q", s.bucket)
+	}
+	if err := bucket.Create(ctx, s.ProjectID, nil); err != nil {
+		return fmt.Errorf("gcs storage: failed to create bucket %q: %w", s.bucket, err)
+	}
+	return nil
+}
+
+func (s *GCSStorage) object(key string) *storage.ObjectHandle {
+	return s.client.Bucket(s.bucket).Object(key)
+}
+
+func (s *GCSStorage) path(filename string) string {
+	return fmt.Sprintf("gs://%s/%s", s.bucket, filename)
+}
+
+// parseGCSPath splits a "gs://bucket/key" reference into its parts.
+func parseGCSPath(path string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(path, "gs://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("gcs storage: invalid path %q", path)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,267 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Storage implements BlobStorage against an S3-compatible object store
+// (AWS S3 or a MinIO deployment reached via a custom endpoint).
+type S3Storage struct {
+	client *s3.Client
+	bucket string
+
+	// SSEAlgorithm, if set ("AES256" or "aws:kms"), requests server-side
+	// encryption on every object this backend writes. SSEKMSKeyID names the
+	// CMK to use when SSEAlgorithm is "aws:kms"; leaving it empty lets AWS
+	// use the account's default KMS key for S3.
+	SSEAlgorithm string
+	SSEKMSKeyID  string
+}
+
+// NewS3Storage creates a new S3Storage bound to the given bucket. If endpoint
+// is non-empty, the client is pointed at it instead of AWS (e.g. MinIO).
+func NewS3Storage(ctx context.Context, bucket, region, endpoint, accessKey, secretKey string) (*S3Storage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 storage: bucket is required")
+	}
+
+	var optFns []func(*config.LoadOptions) error
+	if region != "" {
+		optFns = append(optFns, config.WithRegion(region))
+	}
+	if accessKey != "" {
+		optFns = append(optFns, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: failed to load aws config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true // required by MinIO and most self-hosted S3 gateways
+		}
+	})
+
+	return &S3Storage{client: client, bucket: bucket}, nil
+}
+
+// Save uploads the data and returns a "s3://bucket/key" reference.
+func (s *S3Storage) Save(ctx context.Context, filename string, data []byte, contentType string) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(filename),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	}
+	s.applySSE(input)
+	_, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("s3 storage: failed to put object: %w", err)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, filename), nil
+}
+
+// SaveStream uploads r without requiring the caller to buffer it first,
+// using the S3 transfer manager so bodies of unknown length are split into
+// multipart parts as needed, and returns a "s3://bucket/key" reference.
+func (s *S3Storage) SaveStream(ctx context.Context, filename string, r io.Reader, contentType string) (string, int64, error) {
+	counting := &countingReader{r: r}
+	uploader := manager.NewUploader(s.client)
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(filename),
+		Body:        counting,
+		ContentType: aws.String(contentType),
+	}
+	s.applySSE(input)
+	_, err := uploader.Upload(ctx, input)
+	if err != nil {
+		return "", 0, fmt.Errorf("s3 storage: failed to upload object: %w", err)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, filename), counting.n, nil
+}
+
+// applySSE sets input's server-side encryption fields from SSEAlgorithm/
+// SSEKMSKeyID, if SSEAlgorithm is set.
+func (s *S3Storage) applySSE(input *s3.PutObjectInput) {
+	if s.SSEAlgorithm == "" {
+		return
+	}
+	input.ServerSideEncryption = types.ServerSideEncryption(s.SSEAlgorithm)
+	if s.SSEKMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.SSEKMSKeyID)
+	}
+}
+
+// countingReader wraps an io.Reader to track how many bytes have been read
+// through it, for backends whose streaming upload APIs don't hand the
+// caller a final size.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Load retrieves the object referenced by a "s3://bucket/key" path.
+func (s *S3Storage) Load(ctx context.Context, path string) ([]byte, error) {
+	bucket, key, err := parseS3Path(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: failed to get object: %w", err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	return io.ReadAll(out.Body)
+}
+
+// Delete removes the object referenced by a "s3://bucket/key" path.
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	bucket, key, err := parseS3Path(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("s3 storage: failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// GetPublicURL returns a presigned GET URL valid for an hour, or an empty
+// string if presigning fails (callers fall back to Load in that case).
+func (s *S3Storage) GetPublicURL(path string) string {
+	url, _, err := s.SignedURL(context.Background(), path, time.Hour, SignedURLOptions{})
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// SignedURL returns a presigned GET URL valid for ttl. opts is unused: AWS's
+// presigned URL already scopes access to the one bucket/key it was minted
+// for, so there's no separate claim to bind.
+func (s *S3Storage) SignedURL(ctx context.Context, path string, ttl time.Duration, _ SignedURLOptions) (string, time.Time, error) {
+	bucket, key, err := parseS3Path(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	presignClient := s3.NewPresignClient(s.client)
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("s3 storage: failed to presign url: %w", err)
+	}
+	return req.URL, time.Now().Add(ttl), nil
+}
+
+// Exists reports whether the object referenced by a "s3://bucket/key" path
+// is present, via a HeadObject call rather than fetching its bytes.
+func (s *S3Storage) Exists(ctx context.Context, path string) (bool, error) {
+	bucket, key, err := parseS3Path(path)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return false, nil
+		}
+		return false, fmt.Errorf("s3 storage: failed to head object: %w", err)
+	}
+	return true, nil
+}
+
+// SignedPutURL returns a presigned PUT URL for filename valid for ttl, plus
+// the "s3://bucket/key" reference the object will be reachable at once the
+// client's direct upload completes.
+func (s *S3Storage) SignedPutURL(ctx context.Context, filename, contentType string, ttl time.Duration) (string, string, time.Time, error) {
+	presignClient := s3.NewPresignClient(s.client)
+	req, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(filename),
+		ContentType: aws.String(contentType),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("s3 storage: failed to presign put url: %w", err)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, filename), req.URL, time.Now().Add(ttl), nil
+}
+
+// EnsureBucket creates the backend's bucket if it doesn't already exist, so
+// a fresh deployment pointed at an empty account doesn't fail its first
+// upload. It's a no-op (not an error) if the bucket is already there.
+func (s *S3Storage) EnsureBucket(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucket)})
+	if err == nil {
+		return nil
+	}
+	var notFound *types.NotFound
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("s3 storage: failed to check bucket %q: %w", s.bucket, err)
+	}
+
+	if _, err := s.client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(s.bucket)}); err != nil {
+		var alreadyOwned *types.BucketAlreadyOwnedByYou
+		if errors.As(err, &alreadyOwned) {
+			return nil
+		}
+		return fmt.Errorf("s3 storage: failed to create bucket %q: %w", s.bucket, err)
+	}
+	return nil
+}
+
+// parseS3Path splits a "s3://bucket/key" reference into its parts.
+func parseS3Path(path string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(path, "s3://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("s3 storage: invalid path %q", path)
+	}
+	return parts[0], parts[1], nil
+}
@@ -1,9 +1,11 @@
 package data
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -53,3 +55,84 @@ func TestDiskStorage(t *testing.T) {
 		t.Errorf("Expected empty URL for DiskStorage, got %q", url)
 	}
 }
+
+func TestDiskStorage_ContentType(t *testing.T) {
+	ds := NewDiskStorage(t.TempDir())
+	ctx := context.Background()
+
+	path, err := ds.Save(ctx, "photo.jpg", []byte("fake jpeg"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	ct, err := ds.ContentType(ctx, path)
+	if err != nil {
+		t.Fatalf("ContentType failed: %v", err)
+	}
+	if ct != "image/jpeg" {
+		t.Errorf("Expected %q, got %q", "image/jpeg", ct)
+	}
+
+	streamPath, _, err := ds.SaveStream(ctx, "clip.mp4", bytes.NewReader([]byte("fake mp4")), "video/mp4")
+	if err != nil {
+		t.Fatalf("SaveStream failed: %v", err)
+	}
+	if ct, err := ds.ContentType(ctx, streamPath); err != nil || ct != "video/mp4" {
+		t.Errorf("Expected %q, got %q (err %v)", "video/mp4", ct, err)
+	}
+
+	if err := ds.Delete(ctx, path); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if ct, err := ds.ContentType(ctx, path); err != nil || ct != "" {
+		t.Errorf("Expected empty content type after Delete, got %q (err %v)", ct, err)
+	}
+
+	noSidecarPath, err := ds.Save(ctx, "no-type.bin", []byte("data"), "")
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if ct, err := ds.ContentType(ctx, noSidecarPath); err != nil || ct != "" {
+		t.Errorf("Expected empty content type when none was saved, got %q (err %v)", ct, err)
+	}
+}
+
+func TestDiskStorage_SignedURL(t *testing.T) {
+	ds := NewDiskStorage(t.TempDir())
+	ctx := context.Background()
+
+	t.Run("Requires a secret", func(t *testing.T) {
+		if _, _, err := ds.SignedURL(ctx, "/tmp/photo.jpg", time.Minute, SignedURLOptions{}); err == nil {
+			t.Error("Expected an error when SignSecret is unset")
+		}
+	})
+
+	ds.SignSecret = []byte("test-secret")
+
+	url, expiresAt, err := ds.SignedURL(ctx, "/tmp/photo.jpg", time.Minute, SignedURLOptions{ArtifactID: "art_1", TripID: "trip_1"})
+	if err != nil {
+		t.Fatalf("SignedURL failed: %v", err)
+	}
+	if expiresAt.Before(time.Now()) {
+		t.Errorf("Expected expiresAt in the future, got %v", expiresAt)
+	}
+
+	token := url[len(signedArtifactURLRoute):]
+	claim, err := ds.VerifySignedURL(token)
+	if err != nil {
+		t.Fatalf("VerifySignedURL failed: %v", err)
+	}
+	if claim.Path != "/tmp/photo.jpg" || claim.ArtifactID != "art_1" || claim.TripID != "trip_1" {
+		t.Errorf("Unexpected claim: %+v", claim)
+	}
+
+	if _, err := ds.VerifySignedURL("tampered." + token); err == nil {
+		t.Error("Expected a tampered token to fail verification")
+	}
+
+	other := NewDiskStorage(t.TempDir())
+	other.SignSecret = []byte("different-secret")
+	if _, err := other.VerifySignedURL(token); err == nil {
+		t.Error("Expected a token signed with a different secret to fail verification")
+	}
+}
@@ -0,0 +1,199 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebDAVStorage implements BlobStorage against any RFC 4918 WebDAV server
+// (e.g. NextCloud), using a plain net/http client.
+type WebDAVStorage struct {
+	BaseURL  string
+	Username string
+	Password string
+	client   *http.Client
+}
+
+// NewWebDAVStorage creates a new WebDAVStorage pointed at baseURL (e.g.
+// "https://cloud.example.com/remote.php/dav/files/navallist").
+func NewWebDAVStorage(baseURL, username, password string) *WebDAVStorage {
+	return &WebDAVStorage{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Username: username,
+		Password: password,
+		client:   &http.Client{},
+	}
+}
+
+// Save uploads the data via PUT and returns a "webdav://host/path" reference.
+func (s *WebDAVStorage) Save(ctx context.Context, filename string, data []byte, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.BaseURL+"/"+filename, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("webdav storage: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.authenticate(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("webdav storage: PUT failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf("webdav storage: PUT returned status %d", resp.StatusCode)
+	}
+
+	return toWebDAVPath(s.BaseURL, filename), nil
+}
+
+// SaveStream uploads r via a chunked-transfer-encoded PUT (Go's http.Client
+// omits Content-Length when the body has no known size) and returns a
+// "webdav://host/path" reference.
+func (s *WebDAVStorage) SaveStream(ctx context.Context, filename string, r io.Reader, contentType string) (string, int64, error) {
+	counting := &countingReader{r: r}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.BaseURL+"/"+filename, counting)
+	if err != nil {
+		return "", 0, fmt.Errorf("webdav storage: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.authenticate(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("webdav storage: PUT failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return "", 0, fmt.Errorf("webdav storage: PUT returned status %d", resp.StatusCode)
+	}
+
+	return toWebDAVPath(s.BaseURL, filename), counting.n, nil
+}
+
+// Load retrieves the object referenced by a "webdav://host/path" reference.
+func (s *WebDAVStorage) Load(ctx context.Context, path string) ([]byte, error) {
+	url, err := s.resolveURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("webdav storage: failed to build request: %w", err)
+	}
+	s.authenticate(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webdav storage: GET failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webdav storage: GET returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes the object referenced by a "webdav://host/path" reference.
+func (s *WebDAVStorage) Delete(ctx context.Context, path string) error {
+	url, err := s.resolveURL(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("webdav storage: failed to build request: %w", err)
+	}
+	s.authenticate(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webdav storage: DELETE failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("webdav storage: DELETE returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetPublicURL for WebDAV returns an empty string; NextCloud-style servers
+// have no notion of a public presigned URL without a separate share API.
+func (s *WebDAVStorage) GetPublicURL(_ string) string {
+	return ""
+}
+
+// SignedURL is not supported: WebDAV has no native URL-signing mechanism.
+func (s *WebDAVStorage) SignedURL(_ context.Context, _ string, _ time.Duration, _ SignedURLOptions) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("webdav storage: signed URLs are not supported")
+}
+
+// Exists reports whether the object referenced by a "webdav://host/path"
+// reference is present, via a HEAD request.
+func (s *WebDAVStorage) Exists(ctx context.Context, path string) (bool, error) {
+	url, err := s.resolveURL(path)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("webdav storage: failed to build request: %w", err)
+	}
+	s.authenticate(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("webdav storage: HEAD failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("webdav storage: HEAD returned status %d", resp.StatusCode)
+	}
+	return true, nil
+}
+
+func (s *WebDAVStorage) authenticate(req *http.Request) {
+	if s.Username != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+}
+
+// resolveURL turns a "webdav://host/path" reference back into the HTTPS URL
+// used to reach it, since the base scheme/host served at save time may have
+// been HTTP or HTTPS.
+func (s *WebDAVStorage) resolveURL(path string) (string, error) {
+	if !strings.HasPrefix(path, "webdav://") {
+		return "", fmt.Errorf("webdav storage: invalid path %q", path)
+	}
+	rest := strings.TrimPrefix(path, "webdav://")
+	idx := strings.Index(rest, "/")
+	if idx == -1 {
+		return "", fmt.Errorf("webdav storage: invalid path %q", path)
+	}
+	return s.BaseURL + rest[idx:], nil
+}
+
+// toWebDAVPath builds the "webdav://host/path" reference persisted in storage_path.
+func toWebDAVPath(baseURL, filename string) string {
+	host := baseURL
+	if idx := strings.Index(baseURL, "://"); idx != -1 {
+		host = baseURL[idx+len("://"):]
+	}
+	return fmt.Sprintf("webdav://%s/%s", host, filename)
+}
@@ -3,7 +3,10 @@ package data
 import (
 	"context"
 	"errors"
+	"time"
+
 	"navallist/internal/data/models"
+	"navallist/internal/data/syncstore"
 )
 
 var ErrNotFound = errors.New("not found")
@@ -14,16 +17,42 @@ type Store interface {
 	// User operations
 	GetUser(ctx context.Context, id string) (*models.User, error)
 	FindUserByName(ctx context.Context, name string) (*models.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetOrCreateUserByEmail(ctx context.Context, email string) (*models.User, error)
 	UpdateUser(ctx context.Context, id, name string) error
 
+	// Email verification operations, backing the guest-to-user claim flow.
+	CreateEmailVerification(ctx context.Context, email, code string, ttl time.Duration) error
+	ConsumeEmailVerification(ctx context.Context, email, code string) error
+	ClaimGuestContributions(ctx context.Context, guestName, userID string) (int, error)
+
+	// Refresh token operations, backing internal/auth's session cookie flow.
+	SaveRefreshToken(ctx context.Context, token, userID string, ttl time.Duration) error
+	ConsumeRefreshToken(ctx context.Context, token string) (string, error)
+
 	// Trip operations
 	GetOrCreateTrip(ctx context.Context, adkSessionID, userID, captainName, tripType string) (*models.Trip, error)
 	GetTripIDBySessionID(ctx context.Context, sessionID string) (string, error)
 	GetTrip(ctx context.Context, tripID string) (*models.Trip, error)
 	AddTripCrew(ctx context.Context, tripID, userID, displayName string) error
+	// IsTripCrew reports whether tripID exists and, when userID is non-empty,
+	// whether that user is part of its crew (owner or AddTripCrew-recorded
+	// member). Trips are also joinable anonymously by anyone who has the
+	// link (see GetOrCreateTrip), so an empty userID only checks existence -
+	// this gates realtime channel subscriptions against a bogus trip ID, not
+	// against genuine crew members.
+	IsTripCrew(ctx context.Context, tripID, userID string) (bool, error)
 	GetActiveCrewNames(ctx context.Context, tripID string) ([]string, error)
-	FindCrewMember(ctx context.Context, tripID, query string) (string, error)
+	// FindCrewMember ranks this trip's known crew names against query and
+	// returns every candidate scoring at or above DefaultCrewMatchThreshold,
+	// sorted by confidence descending; an empty slice means no confident
+	// match was found.
+	FindCrewMember(ctx context.Context, tripID, query string) ([]models.CrewMatch, error)
 	ListUserTrips(ctx context.Context, userID string) ([]models.Trip, error)
+	// ListActiveTrips returns every trip not yet marked "completed", for
+	// operator tooling like `navallist realtime diag` that needs to probe
+	// presence without a specific user in hand.
+	ListActiveTrips(ctx context.Context) ([]models.Trip, error)
 	UpdateTripStatus(ctx context.Context, tripID string, status string) error
 	UpdateTripType(ctx context.Context, tripID string, tripType string) error
 	DeleteTrip(ctx context.Context, tripID string) error
@@ -33,10 +62,103 @@ type Store interface {
 	// Checklist operations
 	UpdateItem(ctx context.Context, tripID, itemName string, isChecked bool, location string, photoArtifactID string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error)
 	UpdateItemWithAssignment(ctx context.Context, tripID, itemName string, isChecked bool, location, photoID, currentUserID, assignedToName string) (*models.ChecklistItem, bool, error)
+	// UpdateItemsBatch applies every update in one transaction, so a caller
+	// canceling mid-call (e.g. an HTTP client disconnecting during
+	// ChecklistTool.UpdateItems) rolls back the whole batch instead of
+	// leaving it partially applied.
+	UpdateItemsBatch(ctx context.Context, tripID, currentUserID string, updates []BatchItemUpdate) ([]BatchItemResult, error)
 	AddItemPhoto(ctx context.Context, tripID, itemName string, photoArtifactID string) (*models.ChecklistItem, error)
+	// ApplyMutations merges a batch of offline-queued client edits into
+	// tripID's checklist, applying per-field last-writer-wins/union-merge
+	// rules (see ItemMutation), and reports each mutation's outcome.
+	ApplyMutations(ctx context.Context, tripID string, userID *string, muts []ItemMutation) ([]MutationResult, error)
+	// GetChangedItems returns tripID's checklist items with a version
+	// greater than sinceVersion, backing GET /api/trips/{id}/changes for a
+	// device reconnecting after being offline.
+	GetChangedItems(ctx context.Context, tripID string, sinceVersion int64) ([]models.ChecklistItem, error)
+	// ApplyOps merges a batch of offline-authored ChecklistOps into
+	// tripID's checklist using per-field CRDT merge rules (see
+	// syncstore.Clock). Unlike ApplyMutations, which resolves one batch
+	// against the live server state by submission order, ApplyOps trusts
+	// each op's own Lamport counter, so two peers that went offline
+	// independently still converge however their batches interleave.
+	ApplyOps(ctx context.Context, tripID string, ops []syncstore.ChecklistOp) ([]models.ChecklistItem, error)
+	// GetOpsSince returns every accepted op for tripID with a Lamport
+	// counter greater than sinceLamport, backing
+	// GET /api/trips/{id}/ops?since= for a peer reconciling after a
+	// stretch offline.
+	GetOpsSince(ctx context.Context, tripID string, sinceLamport int64) ([]syncstore.ChecklistOp, error)
+	// EmitServerOp mints a fresh Lamport counter and logs a single op on
+	// behalf of a direct write path (UpdateItem, UpdateItemWithAssignment),
+	// so an offline peer pulling GetOpsSince still sees it.
+	EmitServerOp(ctx context.Context, tripID, itemName, field string, value interface{}, originUserID string) error
 
 	// Artifact operations
 	CreateArtifact(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error)
+	// CreateExternalArtifact records a "pending" artifact for a client's
+	// direct upload to a PresignUploader-minted URL, confirmed or orphaned
+	// later by internal/storagereconciler.
+	CreateExternalArtifact(ctx context.Context, tripID, filename, mimeType, storagePath string) (*models.Artifact, error)
+	// ConfirmArtifact marks a pending artifact "confirmed".
+	ConfirmArtifact(ctx context.Context, id string) (*models.Artifact, error)
+	// MarkArtifactOrphan marks a pending artifact "orphan".
+	MarkArtifactOrphan(ctx context.Context, id string) error
+	// ListPendingArtifacts returns pending artifacts older than olderThan
+	// for the reconciler to check.
+	ListPendingArtifacts(ctx context.Context, olderThan time.Time) ([]models.Artifact, error)
 	GetArtifact(ctx context.Context, filename string) (*models.Artifact, error)
 	GetArtifactByID(ctx context.Context, id string) (*models.Artifact, error)
+	ListArtifactsByTrip(ctx context.Context, tripID string) ([]models.Artifact, error)
+	// GetArtifactSized returns artifact id resized to fit size on its
+	// longest edge (see artifact_thumbnails.go), generating and caching the
+	// derived image on first request for that (id, size) pair. size must be
+	// one of the allow-listed thumbnail sizes, and ErrNotFound is returned
+	// if the artifact itself doesn't exist.
+	GetArtifactSized(ctx context.Context, id string, size int) (data []byte, contentType string, err error)
+	// SaveArtifactExif persists the EXIF-derived metadata and hashes
+	// extracted from an upload, linked to an artifact CreateArtifact
+	// already wrote.
+	SaveArtifactExif(ctx context.Context, exif models.ArtifactExif) error
+	// FindArtifactByHash looks up an artifact by its original upload's
+	// content hash, returning ErrNotFound if no artifact has that hash yet.
+	FindArtifactByHash(ctx context.Context, contentHash string) (*models.Artifact, error)
+	// FindArtifactsNear returns every artifact on tripID with a GPS tag
+	// within radiusMeters of (lat, lon), backing the
+	// GET /api/trips/{id}/photos/near "photos near this waypoint" view.
+	FindArtifactsNear(ctx context.Context, tripID string, lat, lon, radiusMeters float64) ([]models.Artifact, error)
+
+	// Content-addressed blob/manifest operations (see manifests.go),
+	// deduplicating identical uploads across trips and letting
+	// GetArtifact resolve either a manifest ID or a raw blob hash.
+	GetOrCreateBlob(ctx context.Context, hash string, size int64, mimeType, storagePath string) (*models.Blob, error)
+	GetBlob(ctx context.Context, hash string) (*models.Blob, error)
+	CreateArtifactManifest(ctx context.Context, layers []models.Layer, meta map[string]string) (*models.ArtifactManifest, error)
+	GetArtifactManifest(ctx context.Context, id string) (*models.ArtifactManifest, error)
+
+	// Upload session operations, backing the tus-style resumable upload
+	// protocol for large photo/video uploads.
+	CreateUploadSession(ctx context.Context, tripID, itemName, filename, mimeType string, totalSize int64) (*models.ArtifactUpload, error)
+	GetUploadSession(ctx context.Context, uploadID string) (*models.ArtifactUpload, error)
+	// AppendUploadChunk appends chunk to the session and advances its
+	// received size, returning the session's state after the append.
+	AppendUploadChunk(ctx context.Context, uploadID string, chunk []byte) (*models.ArtifactUpload, error)
+	// FinalizeUpload marks a fully-received session complete, enforcing the
+	// trip's total-size upload quota transactionally, and returns it with
+	// the accumulated bytes so the caller can hand them to BlobStorage.
+	// Calling it again on an already-complete session is a no-op.
+	FinalizeUpload(ctx context.Context, uploadID string) (*models.ArtifactUpload, error)
+
+	// Agent run operations, backing GET /api/trips/{id}/agent/stream's
+	// replay/debugging log and Last-Event-ID resume support.
+	CreateAgentRun(ctx context.Context, tripID, userID, sessionID, transport string) (*models.AgentRun, error)
+	AppendAgentRunEvent(ctx context.Context, runID string, seq int64, payload []byte) error
+	FinishAgentRun(ctx context.Context, runID string, runErr error) error
+	GetAgentRunEventsSince(ctx context.Context, runID string, sinceSeq int64) ([]models.AgentRunEvent, error)
+
+	// RecordAgentEvent persists one agent tool-call invocation for
+	// GET /api/trips/{id}/timeline and POST /api/trips/{id}/replay.
+	RecordAgentEvent(ctx context.Context, event models.AgentEvent) (*models.AgentEvent, error)
+	// GetAgentEvents returns tripID's recorded agent tool-call events, in
+	// creation order, optionally stopping at upToEventID (0 means "all").
+	GetAgentEvents(ctx context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error)
 }
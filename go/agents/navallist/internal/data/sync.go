@@ -0,0 +1,224 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
+)
+
+// ItemMutation is one client-side edit to a checklist item, queued while a
+// device was offline and submitted in a batch to ApplyMutations via
+// POST /api/trips/{id}/sync. Scalar fields are pointers so a mutation can
+// touch only the fields the client actually changed - nil means "not part
+// of this edit", not "clear it".
+type ItemMutation struct {
+	ItemName         string   `json:"item_name"`
+	DeviceID         string   `json:"device_id"`
+	ClientSeq        int64    `json:"client_seq"`
+	BaseVersion      int64    `json:"base_version"`
+	IsChecked        *bool    `json:"is_checked,omitempty"`
+	Value            *string  `json:"value,omitempty"`
+	Location         *string  `json:"location,omitempty"`
+	PhotoArtifactIDs []string `json:"photo_artifact_ids,omitempty"`
+	AssignedToUserID *string  `json:"assigned_to_user_id,omitempty"`
+	AssignedToName   *string  `json:"assigned_to_name,omitempty"`
+}
+
+// MutationStatus reports what ApplyMutations did with one ItemMutation.
+type MutationStatus string
+
+const (
+	// MutationAccepted means at least one field this mutation set (or a
+	// photo it attached) survived into the item's final merged state.
+	MutationAccepted MutationStatus = "accepted"
+	// MutationSuperseded means every scalar field this mutation set was
+	// overwritten by a later mutation in the same batch - the edit lost
+	// the last-writer-wins race, but nothing failed.
+	MutationSuperseded MutationStatus = "superseded"
+	// MutationRejected means the mutation's item could not be written at all.
+	MutationRejected MutationStatus = "rejected"
+)
+
+// MutationResult reports the outcome of one ItemMutation, in the same
+// order as the batch ApplyMutations was given, plus the item's post-merge
+// state so the client can reconcile its local store against what the
+// server actually persisted.
+type MutationResult struct {
+	DeviceID  string                `json:"device_id"`
+	ClientSeq int64                 `json:"client_seq"`
+	Status    MutationStatus        `json:"status"`
+	Error     string                `json:"error,omitempty"`
+	Item      *models.ChecklistItem `json:"item,omitempty"`
+}
+
+// resolvedItem is the per-field last-writer-wins result of merging every
+// mutation in a batch that touched one checklist item. The *Setter fields
+// record which mutation index (into the original batch) last set that
+// field, so ApplyMutations can tell whether a given mutation's edit
+// survived (accepted) or was clobbered by a later one in the same batch
+// (superseded).
+type resolvedItem struct {
+	isChecked            bool
+	isCheckedSetter      int
+	location             string
+	locationSetter       int
+	assignedToUserID     *string
+	assignedToIDSetter   int
+	assignedToName       *string
+	assignedToNameSetter int
+	// photoArtifactIDs unions every photo ID any mutation in the batch
+	// attached - linking an artifact to an item is already additive (see
+	// AddItemPhoto), so there's no last-writer-wins race to resolve here.
+	photoArtifactIDs map[string]bool
+}
+
+// resolveItemMutations computes, for each item name touched, the final
+// scalar values a batch of mutations resolves to. Scalar fields are
+// last-writer-wins by submission order (muts' slice order); Value takes
+// precedence over Location when both are set, mirroring UpdateItem's
+// existing text/number-vs-location handling.
+func resolveItemMutations(muts []ItemMutation) map[string]*resolvedItem {
+	byItem := make(map[string]*resolvedItem)
+
+	for i, m := range muts {
+		r, ok := byItem[m.ItemName]
+		if !ok {
+			r = &resolvedItem{isCheckedSetter: -1, locationSetter: -1, assignedToIDSetter: -1, assignedToNameSetter: -1}
+			byItem[m.ItemName] = r
+		}
+
+		if m.IsChecked != nil {
+			r.isChecked = *m.IsChecked
+			r.isCheckedSetter = i
+		}
+		if m.Value != nil {
+			r.location = *m.Value
+			r.locationSetter = i
+		} else if m.Location != nil {
+			r.location = *m.Location
+			r.locationSetter = i
+		}
+		if m.AssignedToUserID != nil {
+			r.assignedToUserID = m.AssignedToUserID
+			r.assignedToIDSetter = i
+		}
+		if m.AssignedToName != nil {
+			r.assignedToName = m.AssignedToName
+			r.assignedToNameSetter = i
+		}
+		for _, id := range m.PhotoArtifactIDs {
+			if r.photoArtifactIDs == nil {
+				r.photoArtifactIDs = make(map[string]bool)
+			}
+			r.photoArtifactIDs[id] = true
+		}
+	}
+
+	return byItem
+}
+
+// mutationWonAField reports whether mutation index i (describing m) is
+// the one that actually contributed to resolvedItem r's final state -
+// either by winning a scalar field outright or by attaching a photo,
+// which always survives via union-merge.
+func mutationWonAField(i int, m ItemMutation, r *resolvedItem) bool {
+	if m.IsChecked != nil && r.isCheckedSetter == i {
+		return true
+	}
+	if (m.Value != nil || m.Location != nil) && r.locationSetter == i {
+		return true
+	}
+	if m.AssignedToUserID != nil && r.assignedToIDSetter == i {
+		return true
+	}
+	if m.AssignedToName != nil && r.assignedToNameSetter == i {
+		return true
+	}
+	return len(m.PhotoArtifactIDs) > 0
+}
+
+// ApplyMutations merges a batch of client-queued offline edits into
+// tripID's checklist, one UPSERT per item touched, inside a single
+// transaction. userID, if set, is recorded as who completed any item a
+// mutation in this batch checks, matching UpdateItem's existing
+// completed_by_user_id semantics.
+func (s *SQLStore) ApplyMutations(ctx context.Context, tripID string, userID *string, muts []ItemMutation) ([]MutationResult, error) {
+	results := make([]MutationResult, len(muts))
+	for i, m := range muts {
+		results[i] = MutationResult{DeviceID: m.DeviceID, ClientSeq: m.ClientSeq}
+	}
+
+	resolved := resolveItemMutations(muts)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin sync transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for itemName, r := range resolved {
+		var userToRecord *string
+		if r.isChecked {
+			userToRecord = userID
+		}
+
+		item, err := upsertChecklistItem(ctx, tx, tripID, itemName, r.isChecked, r.location, userToRecord, nil, r.assignedToUserID, r.assignedToName)
+		if err != nil {
+			for i, m := range muts {
+				if m.ItemName == itemName {
+					results[i].Status = MutationRejected
+					results[i].Error = err.Error()
+				}
+			}
+			continue
+		}
+
+		for photoID := range r.photoArtifactIDs {
+			if _, err := tx.ExecContext(ctx, `UPDATE artifact SET checklist_item_id = $1 WHERE id = $2 AND trip_id = $3`, item.ID, photoID, tripID); err != nil {
+				log.Warn("ApplyMutations: failed to link photo", "item", itemName, "photo", photoID, "error", err)
+			}
+		}
+
+		for i, m := range muts {
+			if m.ItemName != itemName {
+				continue
+			}
+			results[i].Item = item
+			if mutationWonAField(i, m, r) {
+				results[i].Status = MutationAccepted
+			} else {
+				results[i].Status = MutationSuperseded
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sync transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetChangedItems returns tripID's checklist items with a version greater
+// than sinceVersion, backing GET /api/trips/{id}/changes for a device
+// reconnecting after being offline to catch up incrementally instead of
+// re-fetching the whole report.
+func (s *SQLStore) GetChangedItems(ctx context.Context, tripID string, sinceVersion int64) ([]models.ChecklistItem, error) {
+	var items []models.ChecklistItem
+	query := `
+		SELECT id, trip_id, category, name, item_type, is_checked, count_value,
+		       location_text, flagged_issue, completed_by_user_id, completed_by_name,
+		       assigned_to_user_id, assigned_to_name, version, updated_at
+		FROM checklist_item
+		WHERE trip_id = (SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1)
+		  AND version > $2
+		ORDER BY version
+	`
+	if err := s.db.SelectContext(ctx, &items, query, tripID, sinceVersion); err != nil {
+		return nil, fmt.Errorf("failed to fetch changed items: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,73 @@
+//go:build integration
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestApplyMutationsAndGetChangedItems(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "artifact", "checklist_item", "trip", "users")
+	defer cleanupData(t, db, "artifact", "checklist_item", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_sync", "", "Captain", "Leisure")
+	if err != nil {
+		t.Fatalf("Failed to create trip: %v", err)
+	}
+
+	trueVal, falseVal := true, false
+	locA, locB := "Locker A", "Locker B"
+
+	muts := []ItemMutation{
+		{ItemName: "Life Jackets", DeviceID: "phoneA", ClientSeq: 1, IsChecked: &falseVal, Location: &locA},
+		{ItemName: "Life Jackets", DeviceID: "phoneB", ClientSeq: 1, IsChecked: &trueVal, Location: &locB},
+	}
+
+	results, err := store.ApplyMutations(ctx, trip.ID, nil, muts)
+	if err != nil {
+		t.Fatalf("ApplyMutations failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Status != MutationSuperseded {
+		t.Errorf("Expected phoneA's mutation to be superseded by phoneB's, got %s", results[0].Status)
+	}
+	if results[1].Status != MutationAccepted {
+		t.Errorf("Expected phoneB's mutation to be accepted, got %s", results[1].Status)
+	}
+	if results[1].Item == nil || !results[1].Item.IsChecked || results[1].Item.LocationText == nil || *results[1].Item.LocationText != locB {
+		t.Fatalf("Expected the merged item to reflect phoneB's edit, got %+v", results[1].Item)
+	}
+	baseVersion := results[1].Item.Version
+
+	changed, err := store.GetChangedItems(ctx, trip.ID, 0)
+	if err != nil {
+		t.Fatalf("GetChangedItems failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Name != "Life Jackets" {
+		t.Fatalf("Expected Life Jackets to show up as changed, got %+v", changed)
+	}
+
+	// A device that already saw baseVersion sees no further changes yet.
+	caughtUp, err := store.GetChangedItems(ctx, trip.ID, baseVersion)
+	if err != nil {
+		t.Fatalf("GetChangedItems failed: %v", err)
+	}
+	if len(caughtUp) != 0 {
+		t.Errorf("Expected no changes past the current version, got %+v", caughtUp)
+	}
+}
@@ -0,0 +1,75 @@
+package data
+
+import "testing"
+
+func TestResolveItemMutations_LastWriterWinsPerField(t *testing.T) {
+	trueVal := true
+	locA, locB := "Locker A", "Locker B"
+	nameA := "Alex"
+
+	muts := []ItemMutation{
+		{ItemName: "Anchor", Location: &locA},
+		{ItemName: "Anchor", IsChecked: &trueVal},
+		{ItemName: "Anchor", Location: &locB},
+		{ItemName: "Anchor", AssignedToName: &nameA},
+	}
+
+	resolved := resolveItemMutations(muts)
+	r, ok := resolved["Anchor"]
+	if !ok {
+		t.Fatalf("Expected a resolved entry for Anchor")
+	}
+	if !r.isChecked || r.isCheckedSetter != 1 {
+		t.Errorf("Expected mutation 1 to have set is_checked, got setter=%d checked=%v", r.isCheckedSetter, r.isChecked)
+	}
+	if r.location != locB || r.locationSetter != 2 {
+		t.Errorf("Expected mutation 2's location to win, got %q (setter %d)", r.location, r.locationSetter)
+	}
+	if r.assignedToName == nil || *r.assignedToName != nameA || r.assignedToNameSetter != 3 {
+		t.Errorf("Expected mutation 3 to set the assignment, got %+v", r.assignedToName)
+	}
+
+	if !mutationWonAField(2, muts[2], r) {
+		t.Error("Expected the winning location mutation to have won a field")
+	}
+	if mutationWonAField(0, muts[0], r) {
+		t.Error("Expected the superseded location mutation to not have won a field")
+	}
+}
+
+func TestResolveItemMutations_PhotoIDsUnionAcrossMutations(t *testing.T) {
+	muts := []ItemMutation{
+		{ItemName: "Flares", PhotoArtifactIDs: []string{"art_1"}},
+		{ItemName: "Flares", PhotoArtifactIDs: []string{"art_2", "art_1"}},
+	}
+
+	resolved := resolveItemMutations(muts)
+	r := resolved["Flares"]
+	if len(r.photoArtifactIDs) != 2 || !r.photoArtifactIDs["art_1"] || !r.photoArtifactIDs["art_2"] {
+		t.Errorf("Expected the union of both mutations' photo IDs, got %v", r.photoArtifactIDs)
+	}
+
+	// Both mutations attached a photo, so both "win a field" even though
+	// neither touched a scalar - union-merge never supersedes a photo add.
+	for i, m := range muts {
+		if !mutationWonAField(i, m, r) {
+			t.Errorf("Expected mutation %d to have won via its photo attachment", i)
+		}
+	}
+}
+
+func TestResolveItemMutations_AssignedToUserIDDoesNotClearOnNilLaterMutation(t *testing.T) {
+	uid := "user_1"
+	muts := []ItemMutation{
+		{ItemName: "Radio", AssignedToUserID: &uid},
+		{ItemName: "Radio", IsChecked: boolPtr(true)},
+	}
+
+	resolved := resolveItemMutations(muts)
+	r := resolved["Radio"]
+	if r.assignedToUserID == nil || *r.assignedToUserID != uid {
+		t.Errorf("Expected the earlier assignment to survive since the later mutation didn't touch it, got %+v", r.assignedToUserID)
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,148 @@
+// Package syncstore implements the merge semantics for navallist's
+// offline-first checklist sync protocol: a per-field CRDT that lets two
+// boats editing the same trip while disconnected reconcile without a
+// central arbiter. Causality is tracked with a Lamport counter per field
+// rather than wall-clock time alone, since devices drift and wall-clock is
+// only used to break ties between genuinely concurrent writes.
+//
+// The actual persistence (reading/writing checklist_item and the
+// checklist_item_op log) lives in data.SQLStore.ApplyOps; this package
+// only decides who wins.
+package syncstore
+
+import "time"
+
+// Field names a ChecklistOp can target. These match the subset of
+// checklist_item's columns ApplyOps knows how to merge.
+const (
+	FieldIsChecked    = "is_checked"
+	FieldLocation     = "location"
+	FieldPhotos       = "photos"
+	FieldFlaggedIssue = "flagged_issue"
+)
+
+// ChecklistOp is one field-level edit, authored offline by OriginUserID and
+// tagged with that device's Lamport counter at the time it was made. Value
+// is field-specific: a bool for IsChecked, a string for Location/
+// FlaggedIssue, an artifact ID for Photos.
+type ChecklistOp struct {
+	ItemName     string      `json:"item_name"`
+	Field        string      `json:"field"`
+	Value        interface{} `json:"value"`
+	OriginUserID string      `json:"origin_user_id"`
+	Lamport      int64       `json:"lamport"`
+	WallClock    time.Time   `json:"wall_clock"`
+}
+
+// FieldStamp records which op currently owns a field, so a later op can be
+// compared against it under the (Lamport, WallClock, OriginUserID) LWW
+// tie-break IsChecked and Location use.
+type FieldStamp struct {
+	Lamport      int64     `json:"lamport"`
+	WallClock    time.Time `json:"wall_clock"`
+	OriginUserID string    `json:"origin_user_id"`
+}
+
+// Clock is the vector-clock/field-stamp bookkeeping ApplyOps persists per
+// checklist_item in its vector_clock jsonb column. Counters is the
+// Lamport vector clock proper (each user's highest observed counter);
+// Fields additionally remembers which op last won each LWW field, so a
+// later op can be compared against it without re-reading the whole op log.
+type Clock struct {
+	Counters map[string]int64      `json:"counters"`
+	Fields   map[string]FieldStamp `json:"fields"`
+}
+
+// NewClock returns an empty Clock, suitable for a checklist item that has
+// never received an op.
+func NewClock() Clock {
+	return Clock{Counters: map[string]int64{}, Fields: map[string]FieldStamp{}}
+}
+
+// Observe folds op into clock: a Lamport clock only ever moves forward, so
+// the author's counter is bumped up to at least op.Lamport, and - for LWW
+// fields - op is recorded as the field's current writer if it wins.
+func (c Clock) Observe(op ChecklistOp) Clock {
+	next := Clock{Counters: map[string]int64{}, Fields: map[string]FieldStamp{}}
+	for user, lamport := range c.Counters {
+		next.Counters[user] = lamport
+	}
+	for field, stamp := range c.Fields {
+		next.Fields[field] = stamp
+	}
+
+	if op.Lamport > next.Counters[op.OriginUserID] {
+		next.Counters[op.OriginUserID] = op.Lamport
+	}
+
+	if op.Field == FieldIsChecked || op.Field == FieldLocation {
+		incoming := FieldStamp{Lamport: op.Lamport, WallClock: op.WallClock, OriginUserID: op.OriginUserID}
+		if current, ok := next.Fields[op.Field]; !ok || precedes(current, incoming) {
+			next.Fields[op.Field] = incoming
+		}
+	}
+
+	return next
+}
+
+// ResolveLWW reports whether op should overwrite field's current value,
+// under the (Lamport, WallClock, OriginUserID) tie-break: higher Lamport
+// wins, then later WallClock, then the lexicographically greater user ID -
+// an arbitrary but deterministic tie-break so every replica converges on
+// the same winner regardless of replay order.
+func (c Clock) ResolveLWW(field string, op ChecklistOp) bool {
+	current, ok := c.Fields[field]
+	if !ok {
+		return true
+	}
+	incoming := FieldStamp{Lamport: op.Lamport, WallClock: op.WallClock, OriginUserID: op.OriginUserID}
+	return precedes(current, incoming)
+}
+
+// precedes reports whether a happened-before b under the LWW tie-break.
+func precedes(a, b FieldStamp) bool {
+	if a.Lamport != b.Lamport {
+		return a.Lamport < b.Lamport
+	}
+	if !a.WallClock.Equal(b.WallClock) {
+		return a.WallClock.Before(b.WallClock)
+	}
+	return a.OriginUserID < b.OriginUserID
+}
+
+// IsConcurrentWith reports whether op was authored without its device
+// having observed some other user's write already folded into clock - a
+// genuine Lamport-clock concurrency, not just a same-instant tie. A
+// correctly incrementing Lamport clock only ever issues op.Lamport greater
+// than every counter its author had observed, so op.Lamport <= another
+// user's recorded counter means that write hadn't reached the author yet.
+// This drives FlaggedIssue's concatenate-on-conflict behavior; IsChecked
+// and Location don't need it since LWW already resolves every case.
+func (c Clock) IsConcurrentWith(op ChecklistOp) bool {
+	for user, lamport := range c.Counters {
+		if user == op.OriginUserID {
+			continue
+		}
+		if op.Lamport <= lamport {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeFlaggedIssue resolves a concurrent write to FlaggedIssue: a
+// happened-after op simply replaces the existing text (LWW), but two
+// concurrent edits are both kept, concatenated, so neither crew member's
+// safety note is silently dropped.
+func MergeFlaggedIssue(existing string, concurrent bool, incoming string) string {
+	if incoming == "" {
+		return existing
+	}
+	if existing == "" || existing == incoming {
+		return incoming
+	}
+	if concurrent {
+		return existing + "; " + incoming
+	}
+	return incoming
+}
@@ -0,0 +1,87 @@
+package syncstore
+
+import (
+	"testing"
+	"time"
+)
+
+func op(item, field string, value interface{}, user string, lamport int64, wall time.Time) ChecklistOp {
+	return ChecklistOp{ItemName: item, Field: field, Value: value, OriginUserID: user, Lamport: lamport, WallClock: wall}
+}
+
+func TestClock_ResolveLWW_HigherLamportWins(t *testing.T) {
+	base := time.Now()
+	clock := NewClock()
+
+	a := op("Anchor", FieldLocation, "Locker A", "deviceA", 1, base)
+	if !clock.ResolveLWW(FieldLocation, a) {
+		t.Fatal("expected the first op to win against an empty clock")
+	}
+	clock = clock.Observe(a)
+
+	b := op("Anchor", FieldLocation, "Locker B", "deviceB", 2, base.Add(time.Second))
+	if !clock.ResolveLWW(FieldLocation, b) {
+		t.Error("expected the higher-Lamport op to win")
+	}
+	clock = clock.Observe(b)
+
+	stale := op("Anchor", FieldLocation, "Locker C", "deviceA", 1, base.Add(2*time.Second))
+	if clock.ResolveLWW(FieldLocation, stale) {
+		t.Error("expected a lower-Lamport op to lose even with a later wall clock")
+	}
+}
+
+func TestClock_ResolveLWW_TiesBreakOnWallClockThenUserID(t *testing.T) {
+	base := time.Now()
+	clock := NewClock()
+	clock = clock.Observe(op("Anchor", FieldIsChecked, true, "deviceA", 5, base))
+
+	earlier := op("Anchor", FieldIsChecked, false, "deviceB", 5, base.Add(-time.Second))
+	if clock.ResolveLWW(FieldIsChecked, earlier) {
+		t.Error("expected an earlier wall clock at the same Lamport to lose")
+	}
+
+	later := op("Anchor", FieldIsChecked, false, "deviceB", 5, base.Add(time.Second))
+	if !clock.ResolveLWW(FieldIsChecked, later) {
+		t.Error("expected a later wall clock at the same Lamport to win")
+	}
+}
+
+func TestClock_IsConcurrentWith(t *testing.T) {
+	clock := NewClock()
+	clock = clock.Observe(op("Anchor", FieldFlaggedIssue, "rust on shackle", "deviceA", 3, time.Now()))
+
+	concurrent := op("Anchor", FieldFlaggedIssue, "line looks frayed", "deviceB", 2, time.Now())
+	if !clock.IsConcurrentWith(concurrent) {
+		t.Error("expected an op whose Lamport hasn't caught up to deviceA's to be concurrent")
+	}
+
+	causal := op("Anchor", FieldFlaggedIssue, "confirmed fixed", "deviceB", 4, time.Now())
+	if clock.IsConcurrentWith(causal) {
+		t.Error("expected an op with a higher Lamport than every other device to not be concurrent")
+	}
+}
+
+func TestMergeFlaggedIssue(t *testing.T) {
+	cases := []struct {
+		name       string
+		existing   string
+		concurrent bool
+		incoming   string
+		want       string
+	}{
+		{"empty existing takes incoming", "", false, "frayed line", "frayed line"},
+		{"empty incoming keeps existing", "rust", false, "", "rust"},
+		{"identical text is a no-op", "rust", true, "rust", "rust"},
+		{"causal write replaces", "rust", false, "fixed", "fixed"},
+		{"concurrent writes concatenate", "rust on shackle", true, "frayed line", "rust on shackle; frayed line"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := MergeFlaggedIssue(c.existing, c.concurrent, c.incoming)
+			if got != c.want {
+				t.Errorf("MergeFlaggedIssue(%q, %v, %q) = %q, want %q", c.existing, c.concurrent, c.incoming, got, c.want)
+			}
+		})
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
 )
 
 // UpdateTripMetadata updates the trip's metadata (Boat Name, Captain) via ADK Session ID.
@@ -119,13 +122,32 @@ func (s *SQLStore) GetActiveCrewNames(ctx context.Context, tripID string) ([]str
 	return names, nil
 }
 
-// FindCrewMember attempts to find a crew member by name using fuzzy matching.
-func (s *SQLStore) FindCrewMember(ctx context.Context, tripID, query string) (string, error) {
+// DefaultCrewMatchThreshold is the minimum CrewMatch.Score FindCrewMember
+// requires before reporting a candidate as a confident match. Below this,
+// FindCrewMember returns no candidates at all, rather than risk assigning
+// an item to the wrong person, so callers like UpdateItemWithAssignment can
+// fall through to their own fallback (e.g. the current user).
+const DefaultCrewMatchThreshold = 0.55
+
+// crewMatchRow mirrors one row of FindCrewMember's ranking query.
+type crewMatchRow struct {
+	Name       string  `db:"name"`
+	NameScore  float64 `db:"name_score"`
+	TokenScore float64 `db:"token_score"`
+}
+
+// FindCrewMember ranks every known crew name on this trip against query,
+// combining whole-name trigram similarity with the best single-token
+// similarity (so "Tris" scores higher against "Tristan Hussain" than
+// against "Chris Miller", even though both share a trigram with "Tris").
+// Candidates scoring below DefaultCrewMatchThreshold are dropped entirely;
+// the rest come back sorted by Score descending.
+func (s *SQLStore) FindCrewMember(ctx context.Context, tripID, query string) ([]models.CrewMatch, error) {
 	// 1. Get real trip ID
 	var realID string
 	err := s.db.GetContext(ctx, &realID, `SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1`, tripID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	sqlQuery := `
@@ -144,37 +166,48 @@ func (s *SQLStore) FindCrewMember(ctx context.Context, tripID, query string) (st
 			UNION
 			SELECT u.name FROM trip t JOIN users u ON t.user_id = u.id WHERE t.id = $1 AND u.name IS NOT NULL
 		)
-		SELECT name
-		FROM crew_names
-		WHERE name <> '' AND (
-			LOWER(name) = LOWER($2)
-			OR LOWER(name) LIKE '%' || LOWER($2) || '%'
-		)
-		ORDER BY 
-			CASE 
-				WHEN LOWER(name) = LOWER($2) THEN 0 
-				ELSE 1
-			END ASC,
-			name ASC
-		LIMIT 1
+		SELECT
+			cn.name,
+			similarity(LOWER(cn.name), LOWER($2)) AS name_score,
+			COALESCE((
+				SELECT MAX(word_similarity(LOWER(qtoken), LOWER(token)))
+				FROM unnest(string_to_array(cn.name, ' ')) AS token
+				CROSS JOIN unnest(string_to_array($2, ' ')) AS qtoken
+			), 0) AS token_score
+		FROM crew_names cn
+		WHERE cn.name <> ''
+		ORDER BY GREATEST(name_score, token_score) DESC, levenshtein(LOWER(cn.name), LOWER($2)) ASC, cn.name ASC
 	`
-	var name string
-	err = s.db.GetContext(ctx, &name, sqlQuery, realID, query)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil
+	var rows []crewMatchRow
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, realID, query); err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.CrewMatch, 0, len(rows))
+	for _, row := range rows {
+		score, source := row.NameScore, "full_name"
+		if row.TokenScore > score {
+			score, source = row.TokenScore, "token"
 		}
-		return "", err
+		if strings.EqualFold(row.Name, query) {
+			score, source = 1, "exact"
+		}
+		if score < DefaultCrewMatchThreshold {
+			continue
+		}
+		matches = append(matches, models.CrewMatch{Name: row.Name, Score: score, Source: source})
 	}
-	return name, nil
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches, nil
 }
 
 // GetTripReport retrieves all checklist items for a trip to generate a report.
 func (s *SQLStore) GetTripReport(ctx context.Context, tripID string) ([]models.ChecklistItem, error) {
 	var items []models.ChecklistItem
 	query := `
-		SELECT ci.id, ci.trip_id, ci.category, ci.name, ci.item_type, ci.is_checked, ci.count_value, 
-		       ci.location_text, ci.flagged_issue, ci.completed_by_user_id, ci.completed_by_name, ci.updated_at,
+		SELECT ci.id, ci.trip_id, ci.category, ci.name, ci.item_type, ci.is_checked, ci.count_value,
+		       ci.location_text, ci.flagged_issue, ci.completed_by_user_id, ci.completed_by_name, ci.version, ci.updated_at,
 		       ci.assigned_to_user_id, ci.assigned_to_name,
 		       u.name as completed_by_user_name
 		FROM checklist_item ci
@@ -209,11 +242,56 @@ func (s *SQLStore) GetTripReport(ctx context.Context, tripID string) ([]models.C
 				}
 			}
 		}
+
+		if err := s.hydrateManifests(ctx, realTripID, items); err != nil {
+			log.Error("Failed to hydrate artifact manifests for trip report", "trip_id", realTripID, "error", err)
+		}
 	}
 
 	return items, nil
 }
 
+// hydrateManifests sets each item's Manifests field to the content-addressed
+// ArtifactManifest rows that reference one of its Photos, by matching on the
+// photo's ArtifactExif content hash. A photo uploaded before manifests
+// existed, or whose manifest was never recorded, simply has no Manifests -
+// this is additive to Photos, not a replacement for it.
+func (s *SQLStore) hydrateManifests(ctx context.Context, tripID string, items []models.ChecklistItem) error {
+	var rows []struct {
+		manifestRow
+		ChecklistItemID string `db:"checklist_item_id"`
+	}
+	query := `
+		SELECT am.id, am.layers, am.meta, am.created_at, a.checklist_item_id
+		FROM artifact a
+		JOIN artifacts_exif e ON e.artifact_id = a.id
+		JOIN artifact_manifest am ON am.layers @> jsonb_build_array(jsonb_build_object('content_hash', e.content_hash))
+		WHERE a.trip_id = $1 AND a.checklist_item_id IS NOT NULL
+	`
+	if err := s.db.SelectContext(ctx, &rows, query, tripID); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	manifestsByItem := make(map[string][]models.ArtifactManifest)
+	for _, row := range rows {
+		manifest, err := rowToManifest(row.manifestRow)
+		if err != nil {
+			return err
+		}
+		manifestsByItem[row.ChecklistItemID] = append(manifestsByItem[row.ChecklistItemID], *manifest)
+	}
+
+	for i := range items {
+		if manifests, ok := manifestsByItem[items[i].ID]; ok {
+			items[i].Manifests = manifests
+		}
+	}
+	return nil
+}
+
 // GetTripIDBySessionID retrieves the internal trip ID for a given ADK session ID.
 func (s *SQLStore) GetTripIDBySessionID(ctx context.Context, sessionID string) (string, error) {
 	var id string
@@ -237,6 +315,36 @@ func (s *SQLStore) AddTripCrew(ctx context.Context, tripID, userID, displayName
 	return err
 }
 
+// IsTripCrew reports whether tripID exists and, when userID is non-empty,
+// whether that user owns the trip or was recorded as crew via AddTripCrew.
+func (s *SQLStore) IsTripCrew(ctx context.Context, tripID, userID string) (bool, error) {
+	var realID string
+	err := s.db.GetContext(ctx, &realID, `SELECT id FROM trip WHERE id = $1 OR adk_session_id = $1 LIMIT 1`, tripID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up trip: %w", err)
+	}
+
+	if userID == "" {
+		return true, nil
+	}
+
+	var isCrew bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM trip WHERE id = $1 AND user_id = $2
+			UNION
+			SELECT 1 FROM trip_crew WHERE trip_id = $1 AND user_id = $2
+		)
+	`
+	if err := s.db.GetContext(ctx, &isCrew, query, realID, userID); err != nil {
+		return false, fmt.Errorf("failed to check trip crew membership: %w", err)
+	}
+	return isCrew, nil
+}
+
 // GetTrip retrieves the trip details.
 func (s *SQLStore) GetTrip(ctx context.Context, tripID string) (*models.Trip, error) {
 	var trip models.Trip
@@ -260,6 +368,17 @@ func (s *SQLStore) ListUserTrips(ctx context.Context, userID string) ([]models.T
 	return trips, nil
 }
 
+// ListActiveTrips returns every trip not yet marked "completed".
+func (s *SQLStore) ListActiveTrips(ctx context.Context) ([]models.Trip, error) {
+	var trips []models.Trip
+	query := `SELECT * FROM trip WHERE status <> 'completed' ORDER BY created_at DESC`
+	err := s.db.SelectContext(ctx, &trips, query)
+	if err != nil {
+		return nil, err
+	}
+	return trips, nil
+}
+
 // GetOrCreateTrip ensures a trip exists for the given ADK session ID and user.
 func (s *SQLStore) GetOrCreateTrip(ctx context.Context, adkSessionID, userID, captainName, tripType string) (*models.Trip, error) {
 	var trip models.Trip
@@ -297,43 +416,61 @@ func (s *SQLStore) GetOrCreateTrip(ctx context.Context, adkSessionID, userID, ca
 	return &trip, nil
 }
 
-// UpdateItemWithAssignment performs a higher-level update including resolving fuzzy matches for assignments.
-func (s *SQLStore) UpdateItemWithAssignment(ctx context.Context, tripID, itemName string, isChecked bool, location, photoID, currentUserID, assignedToName string) (*models.ChecklistItem, bool, error) {
-	var assignedToUserID *string
-	var finalAssignedName *string
-	matchFound := true
-
-	if assignedToName != "" {
-		// 1. Try DB Fuzzy Match
-		match, _ := s.FindCrewMember(ctx, tripID, assignedToName)
-
-		// 2. Fallback to current user if name matches
-		currentUserName := ""
-		if !strings.HasPrefix(currentUserID, "guest_") {
-			u, err := s.GetUser(ctx, currentUserID)
-			if err == nil && u != nil && u.Name != nil {
-				currentUserName = *u.Name
-			}
-		}
+// resolveAssignment resolves assignedToName against the trip's crew (fuzzy
+// match, falling back to the current user) shared by UpdateItemWithAssignment
+// and UpdateItemsBatch. It's read-only, so unlike the upsert itself it
+// doesn't need to run inside a caller's transaction to stay consistent.
+func (s *SQLStore) resolveAssignment(ctx context.Context, tripID, currentUserID, assignedToName string) (assignedToUserID, finalAssignedName *string, matchFound bool, err error) {
+	if assignedToName == "" {
+		return nil, nil, true, nil
+	}
+	matchFound = true
+
+	// 1. Try DB Fuzzy Match. Only the top-ranked candidate above
+	// DefaultCrewMatchThreshold is used here; ambiguous candidates are
+	// exposed to callers that want them via FindCrewMember directly
+	// (see handlers.TripsHandler.ResolveCrewMember).
+	match := ""
+	if matches, err := s.FindCrewMember(ctx, tripID, assignedToName); err == nil && len(matches) > 0 {
+		match = matches[0].Name
+	}
 
-		if match == "" && currentUserName != "" && strings.EqualFold(assignedToName, currentUserName) {
-			match = currentUserName
+	// 2. Fallback to current user if name matches
+	currentUserName := ""
+	if !strings.HasPrefix(currentUserID, "guest_") {
+		u, err := s.GetUser(ctx, currentUserID)
+		if err == nil && u != nil && u.Name != nil {
+			currentUserName = *u.Name
 		}
+	}
 
-		if match != "" {
-			finalAssignedName = &match
-			// If it matches a registered user, try to get their ID
-			regUser, err := s.FindUserByName(ctx, match)
-			if err == nil && regUser != nil {
-				assignedToUserID = &regUser.ID
-			} else if strings.EqualFold(match, currentUserName) && !strings.HasPrefix(currentUserID, "guest_") {
-				assignedToUserID = &currentUserID
-			}
-		} else {
-			// Assign by name only (for new/guest users)
-			finalAssignedName = &assignedToName
-			matchFound = false
+	if match == "" && currentUserName != "" && strings.EqualFold(assignedToName, currentUserName) {
+		match = currentUserName
+	}
+
+	if match != "" {
+		finalAssignedName = &match
+		// If it matches a registered user, try to get their ID
+		regUser, err := s.FindUserByName(ctx, match)
+		if err == nil && regUser != nil {
+			assignedToUserID = &regUser.ID
+		} else if strings.EqualFold(match, currentUserName) && !strings.HasPrefix(currentUserID, "guest_") {
+			assignedToUserID = &currentUserID
 		}
+	} else {
+		// Assign by name only (for new/guest users)
+		finalAssignedName = &assignedToName
+		matchFound = false
+	}
+
+	return assignedToUserID, finalAssignedName, matchFound, nil
+}
+
+// UpdateItemWithAssignment performs a higher-level update including resolving fuzzy matches for assignments.
+func (s *SQLStore) UpdateItemWithAssignment(ctx context.Context, tripID, itemName string, isChecked bool, location, photoID, currentUserID, assignedToName string) (*models.ChecklistItem, bool, error) {
+	assignedToUserID, finalAssignedName, matchFound, err := s.resolveAssignment(ctx, tripID, currentUserID, assignedToName)
+	if err != nil {
+		return nil, false, err
 	}
 
 	var uidPtr *string
@@ -344,3 +481,63 @@ func (s *SQLStore) UpdateItemWithAssignment(ctx context.Context, tripID, itemNam
 	item, err := s.UpdateItem(ctx, tripID, itemName, isChecked, location, photoID, uidPtr, currentUserID, assignedToUserID, finalAssignedName)
 	return item, matchFound, err
 }
+
+// BatchItemUpdate is one element of a ChecklistTool.UpdateItems call, applied
+// to the database by UpdateItemsBatch.
+type BatchItemUpdate struct {
+	ItemName       string
+	IsChecked      bool
+	Location       string
+	AssignedToName string
+}
+
+// BatchItemResult pairs one BatchItemUpdate's resolved item with whether its
+// assignment matched a known crew member, so a caller can still report the
+// same per-item warnings UpdateItemWithAssignment does.
+type BatchItemResult struct {
+	Item       *models.ChecklistItem
+	MatchFound bool
+}
+
+// UpdateItemsBatch applies every update in updates inside a single
+// transaction, so a caller canceling mid-batch (e.g. an HTTP client
+// disconnecting partway through ChecklistTool.UpdateItems) rolls back every
+// item in the batch instead of leaving it partially applied. Assignment
+// resolution itself (resolveAssignment) is read-only and runs outside the
+// transaction; only the upserts it feeds are transactional.
+func (s *SQLStore) UpdateItemsBatch(ctx context.Context, tripID, currentUserID string, updates []BatchItemUpdate) ([]BatchItemResult, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch update transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var uidPtr *string
+	if currentUserID != "" {
+		uidPtr = &currentUserID
+	}
+
+	results := make([]BatchItemResult, 0, len(updates))
+	for _, u := range updates {
+		assignedToUserID, finalAssignedName, matchFound, err := s.resolveAssignment(ctx, tripID, currentUserID, u.AssignedToName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve assignment for %q: %w", u.ItemName, err)
+		}
+
+		var userToRecord *string
+		if u.IsChecked {
+			userToRecord = uidPtr
+		}
+
+		item, err := upsertChecklistItem(ctx, tx, tripID, u.ItemName, u.IsChecked, u.Location, userToRecord, uidPtr, assignedToUserID, finalAssignedName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %q: %w", u.ItemName, err)
+		}
+		results = append(results, BatchItemResult{Item: item, MatchFound: matchFound})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit batch update: %w", err)
+	}
+	return results, nil
+}
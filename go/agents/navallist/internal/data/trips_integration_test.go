@@ -74,3 +74,51 @@ func TestTripOperations(t *testing.T) {
 		t.Error("Expected error getting deleted trip, got nil")
 	}
 }
+
+func TestFindCrewMember(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	store := NewSQLStore(db)
+	ctx := context.Background()
+
+	cleanupData(t, db, "checklist_item", "trip_crew", "trip", "users")
+	defer cleanupData(t, db, "checklist_item", "trip_crew", "trip", "users")
+
+	trip, err := store.GetOrCreateTrip(ctx, "session_find_crew", "", "Tristan Hussain", "Leisure")
+	if err != nil {
+		t.Fatalf("GetOrCreateTrip failed: %v", err)
+	}
+	assignee := "Chris Miller"
+	if _, err := store.UpdateItem(ctx, trip.ID, "Check lines", false, "", "", nil, "", nil, &assignee); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+
+	matches, err := store.FindCrewMember(ctx, trip.ID, "Tris")
+	if err != nil {
+		t.Fatalf("FindCrewMember failed: %v", err)
+	}
+	if len(matches) == 0 || matches[0].Name != "Tristan Hussain" {
+		t.Fatalf("Expected top match 'Tristan Hussain', got %+v", matches)
+	}
+	if matches[0].Score < DefaultCrewMatchThreshold {
+		t.Errorf("Expected top match score >= %v, got %v", DefaultCrewMatchThreshold, matches[0].Score)
+	}
+	for _, m := range matches {
+		if m.Name == "Chris Miller" {
+			t.Errorf("Expected 'Chris Miller' to rank below 'Tristan Hussain' for query 'Tris', got %+v", matches)
+		}
+	}
+
+	noMatch, err := store.FindCrewMember(ctx, trip.ID, "Zzyzx")
+	if err != nil {
+		t.Fatalf("FindCrewMember failed: %v", err)
+	}
+	if len(noMatch) != 0 {
+		t.Errorf("Expected no confident match for 'Zzyzx', got %+v", noMatch)
+	}
+}
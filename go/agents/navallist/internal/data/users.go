@@ -2,6 +2,8 @@ package data
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"navallist/internal/data/models"
 )
@@ -34,3 +36,35 @@ func (s *SQLStore) UpdateUser(ctx context.Context, id, name string) error {
 	_, err := s.db.ExecContext(ctx, query, name, id)
 	return err
 }
+
+// FindUserByEmail retrieves a user by their email address.
+func (s *SQLStore) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user := &models.User{}
+	query := `SELECT * FROM users WHERE LOWER(email) = LOWER($1) LIMIT 1`
+	err := s.db.GetContext(ctx, user, query, email)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetOrCreateUserByEmail finds the user with the given email, creating one
+// (with no Google identity) if this is their first verified sign-in.
+func (s *SQLStore) GetOrCreateUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.FindUserByEmail(ctx, email)
+	if err == nil {
+		return user, nil
+	}
+
+	insertQuery := `
+		INSERT INTO users (email, google_sub, created_at)
+		VALUES ($1, '', $2)
+		ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+		RETURNING *
+	`
+	var created models.User
+	if err := s.db.GetContext(ctx, &created, insertQuery, email, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to create user for email %s: %w", email, err)
+	}
+	return &created, nil
+}
@@ -0,0 +1,54 @@
+// Package imaging extracts EXIF metadata from uploaded photos and computes
+// the hashes TripsHandler.UploadItemPhoto uses to dedupe re-uploads and to
+// prefill a checklist item's location from a photo's GPS tag.
+package imaging
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// Metadata is what ExtractMetadata pulls out of a photo's EXIF tags. Any
+// field may be nil/unset if the photo has no EXIF data, or the specific tag
+// wasn't present.
+type Metadata struct {
+	GPSLat     *float64
+	GPSLon     *float64
+	CapturedAt *time.Time
+	// Orientation is the EXIF Orientation tag value, defaulting to 1 (no
+	// rotation needed) when the photo has no EXIF data or no such tag.
+	Orientation int
+}
+
+// ExtractMetadata reads the GPS, capture time, and orientation EXIF tags
+// out of a photo's bytes. It returns a Metadata with only Orientation set
+// (to 1), not an error, for images with no EXIF block at all - the common
+// case for anything that
+// didn't come straight off a camera.
+func ExtractMetadata(data []byte) Metadata {
+	meta := Metadata{Orientation: 1}
+
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return meta
+	}
+
+	if lat, lon, err := x.LatLong(); err == nil {
+		meta.GPSLat = &lat
+		meta.GPSLon = &lon
+	}
+
+	if dt, err := x.DateTime(); err == nil {
+		meta.CapturedAt = &dt
+	}
+
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if o, err := tag.Int(0); err == nil {
+			meta.Orientation = o
+		}
+	}
+
+	return meta
+}
@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+)
+
+// ContentHash returns the SHA-256 digest of data, hex-encoded. Two uploads
+// of byte-identical photos hash the same, which is what
+// TripsHandler.UploadItemPhoto uses via Store.FindArtifactByHash to dedupe
+// re-uploads onto the existing artifact instead of storing a duplicate.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// dHashW and dHashH are the width/height (in pixels, after greyscale
+// downscale) the difference hash is computed over. dHashH*(dHashW-1) must
+// equal 64 so the result fits the uint64 PerceptualHash returns.
+const (
+	dHashW = 9
+	dHashH = 8
+)
+
+// PerceptualHash computes a 64-bit difference hash (dHash) of the decoded
+// image: downscale to a 9x8 greyscale grid, then set one bit per pixel that
+// is brighter than its right-hand neighbor. Unlike ContentHash, two photos
+// of the same subject with different compression or resizing land a small
+// Hamming distance apart instead of needing to match byte-for-byte.
+func PerceptualHash(data []byte) (uint64, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("imaging: failed to decode jpeg for perceptual hash: %w", err)
+	}
+
+	gray := downscaleGray(img, dHashW, dHashH)
+
+	var hash uint64
+	for y := 0; y < dHashH; y++ {
+		for x := 0; x < dHashW-1; x++ {
+			bit := uint64(0)
+			if gray[y*dHashW+x] > gray[y*dHashW+x+1] {
+				bit = 1
+			}
+			hash = hash<<1 | bit
+		}
+	}
+	return hash, nil
+}
+
+// downscaleGray nearest-neighbor-samples img down to a w x h greyscale
+// grid, returned row-major.
+func downscaleGray(img image.Image, w, h int) []uint8 {
+	b := img.Bounds()
+	out := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*b.Dx()/w
+			out[y*w+x] = color.GrayModel.Convert(img.At(sx, sy)).(color.Gray).Y
+		}
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// testJPEG renders a small two-tone image (left half black, right half
+// white) so orientation changes and the perceptual hash are observable.
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.Black)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode test jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestContentHashIsStableAndSensitive(t *testing.T) {
+	a := testJPEG(t, 16, 16)
+	b := testJPEG(t, 16, 16)
+	if ContentHash(a) != ContentHash(b) {
+		t.Error("Expected identical bytes to produce the same content hash")
+	}
+	if ContentHash(a) == ContentHash([]byte("not the same photo")) {
+		t.Error("Expected different bytes to produce different content hashes")
+	}
+}
+
+func TestPerceptualHashDetectsBrightnessEdge(t *testing.T) {
+	img := testJPEG(t, 18, 16)
+	hash, err := PerceptualHash(img)
+	if err != nil {
+		t.Fatalf("PerceptualHash() error = %v", err)
+	}
+	if hash == 0 {
+		t.Error("Expected a non-zero hash for a half-black, half-white image")
+	}
+}
+
+func TestPerceptualHashErrorsOnNonJPEG(t *testing.T) {
+	if _, err := PerceptualHash([]byte("not a jpeg")); err == nil {
+		t.Error("Expected an error decoding non-JPEG data")
+	}
+}
+
+func TestExtractMetadataNoExif(t *testing.T) {
+	meta := ExtractMetadata(testJPEG(t, 8, 8))
+	if meta.Orientation != 1 {
+		t.Errorf("Orientation = %d, want 1 for a photo with no EXIF block", meta.Orientation)
+	}
+	if meta.GPSLat != nil || meta.GPSLon != nil {
+		t.Error("Expected no GPS tags for a photo with no EXIF block")
+	}
+}
+
+func TestNormalizeRotatesAndReencodes(t *testing.T) {
+	original := testJPEG(t, 20, 10)
+
+	normalized, err := Normalize(original, 6) // 90deg CW
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(normalized))
+	if err != nil {
+		t.Fatalf("Failed to decode normalized output: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() != 10 || b.Dy() != 20 {
+		t.Errorf("Expected rotated dimensions 10x20, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestNormalizeFallsBackOnUndecodableInput(t *testing.T) {
+	original := []byte("not a jpeg")
+	out, err := Normalize(original, 1)
+	if err == nil {
+		t.Error("Expected an error for undecodable input")
+	}
+	if !bytes.Equal(out, original) {
+		t.Error("Expected Normalize to return the original bytes unchanged on decode failure")
+	}
+}
+
+func TestResizeShrinksToFitLongestEdge(t *testing.T) {
+	original := testJPEG(t, 400, 200)
+
+	resized, err := Resize(original, 100)
+	if err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(resized))
+	if err != nil {
+		t.Fatalf("Failed to decode resized output: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() != 100 || b.Dy() != 50 {
+		t.Errorf("Expected resized dimensions 100x50, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeDoesNotUpscale(t *testing.T) {
+	original := testJPEG(t, 40, 20)
+
+	resized, err := Resize(original, 1024)
+	if err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(resized))
+	if err != nil {
+		t.Fatalf("Failed to decode resized output: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() != 40 || b.Dy() != 20 {
+		t.Errorf("Expected original dimensions 40x20 preserved, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestResizeErrorsOnUndecodableInput(t *testing.T) {
+	if _, err := Resize([]byte("not a jpeg"), 128); err == nil {
+		t.Error("Expected an error decoding non-image data")
+	}
+}
@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+)
+
+// Normalize re-encodes a JPEG with its EXIF orientation baked into the
+// pixels and every EXIF tag - GPS included - dropped, so the bytes written
+// to BlobStorage never carry location data even though ExtractMetadata
+// already pulled what navallist needs out of the original upload.
+//
+// If data can't be decoded as a JPEG (e.g. PNG, or a corrupt file), it's
+// returned unchanged along with an error the caller can log; there's no
+// EXIF to strip from something we can't parse as an image in the first
+// place.
+func Normalize(data []byte, orientation int) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, fmt.Errorf("imaging: failed to decode jpeg, storing original: %w", err)
+	}
+
+	rotated := applyOrientation(img, orientation)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, rotated, &jpeg.Options{Quality: 90}); err != nil {
+		return data, fmt.Errorf("imaging: failed to re-encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// applyOrientation rotates img according to the EXIF Orientation tag (1-8;
+// see the EXIF spec). Only the rotations real cameras actually produce (1,
+// 3, 6, 8 - no mirrored variants) are handled; anything else is returned
+// unchanged.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 3:
+		return rotate180(img)
+	case 6:
+		return rotate90CW(img)
+	case 8:
+		return rotate90CCW(img)
+	default:
+		return img
+	}
+}
+
+func rotate90CW(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.Y-1-y, x, src.At(x, y))
+		}
+	}
+	return dst
+}
+
+func rotate90CCW(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(y, b.Max.X-1-x, src.At(x, y))
+		}
+	}
+	return dst
+}
+
+func rotate180(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.X-1-x, b.Max.Y-1-y, src.At(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,66 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// Resize decodes data (JPEG/PNG/GIF), downsizes it to fit maxEdge on its
+// longest side while preserving aspect ratio, and re-encodes the result as
+// JPEG. It's a no-op re-encode (not an error) if the source is already no
+// larger than maxEdge on both edges, so requesting a large thumbnail size
+// for a small photo doesn't upscale it.
+func Resize(data []byte, maxEdge int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: failed to decode image: %w", err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("imaging: source image has zero dimension")
+	}
+
+	scale := 1.0
+	if w > maxEdge || h > maxEdge {
+		if w >= h {
+			scale = float64(maxEdge) / float64(w)
+		} else {
+			scale = float64(maxEdge) / float64(h)
+		}
+	}
+	dstW, dstH := int(float64(w)*scale), int(float64(h)*scale)
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resizeNearest(src, dstW, dstH), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("imaging: failed to encode resized image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeNearest nearest-neighbor-samples src down (or up) to w x h, the
+// same sampling approach downscaleGray (hash.go) uses for the perceptual
+// hash grid, just over the full-color image instead of a greyscale one.
+func resizeNearest(src image.Image, w, h int) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*b.Dx()/w
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
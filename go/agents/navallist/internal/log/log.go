@@ -0,0 +1,66 @@
+// Package log provides a context-scoped wrapper around charmbracelet/log,
+// so a single log line can carry the user, session, trip, and request IDs
+// it was produced under without every call site having to thread them
+// through by hand.
+package log
+
+import (
+	"context"
+	"maps"
+
+	"navallist/internal/data"
+
+	"github.com/charmbracelet/log"
+)
+
+type contextKey string
+
+const fieldsContextKey contextKey = "log_fields"
+
+// WithFields returns a copy of ctx carrying keyvals merged into whatever
+// fields ctx already had, so a handler can add (say) trip_id once it's
+// resolved the trip without losing the user_id/request_id a middleware
+// upstream already set.
+func WithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	fields := fieldsFromContext(ctx)
+	merged := make(map[string]interface{}, len(fields)+len(keyvals)/2)
+	maps.Copy(merged, fields)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		merged[key] = keyvals[i+1]
+	}
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+func fieldsFromContext(ctx context.Context) map[string]interface{} {
+	fields, _ := ctx.Value(fieldsContextKey).(map[string]interface{})
+	return fields
+}
+
+// FromContext returns the package-default logger with every field
+// WithFields has accumulated on ctx attached, plus user_id/guest_name if
+// AuthMiddleware or ConnectTokenMiddleware set them on ctx, so every log
+// line it produces can be grep'd by user_id, adk_session_id, trip_id, or
+// request_id alongside whatever fields the call site adds itself.
+func FromContext(ctx context.Context) *log.Logger {
+	fields := fieldsFromContext(ctx)
+
+	keyvals := make([]interface{}, 0, len(fields)*2+4)
+	for k, v := range fields {
+		keyvals = append(keyvals, k, v)
+	}
+	if userID := data.GetUserID(ctx); userID != "" {
+		keyvals = append(keyvals, "user_id", userID)
+	}
+	if guestName := data.GetGuestName(ctx); guestName != "" {
+		keyvals = append(keyvals, "guest_name", guestName)
+	}
+
+	if len(keyvals) == 0 {
+		return log.Default()
+	}
+	return log.Default().With(keyvals...)
+}
@@ -0,0 +1,78 @@
+// Package mailer provides a pluggable interface for sending outbound email,
+// used by the email-verification login flow.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+
+	"github.com/charmbracelet/log"
+)
+
+// Mailer sends a single email. Implementations should treat to/subject/body
+// as already fully formed; callers are responsible for templating.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Config carries the backend-specific settings needed to construct a Mailer.
+type Config struct {
+	Kind string // "dev" (default) or "smtp"
+
+	FromAddress string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// New constructs the Mailer backend selected by cfg.Kind.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Kind {
+	case "", "dev":
+		return &DevMailer{}, nil
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.FromAddress), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer kind %q", cfg.Kind)
+	}
+}
+
+// DevMailer logs emails to stdout instead of sending them, for local
+// development where no SMTP relay is configured.
+type DevMailer struct{}
+
+// Send implements Mailer by logging the email in full.
+func (m *DevMailer) Send(_ context.Context, to, subject, body string) error {
+	log.Info("mailer: dev send", "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+// SMTPMailer sends mail through a configured SMTP relay.
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer creates an SMTPMailer targeting the given relay.
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+// Send implements Mailer by dialing the relay and sending a plain-text message.
+func (m *SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	addr := m.host + ":" + m.port
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", m.from, to, subject, body)
+	return smtp.SendMail(addr, auth, m.from, []string{to}, []byte(msg))
+}
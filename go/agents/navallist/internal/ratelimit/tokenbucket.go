@@ -0,0 +1,68 @@
+// Package ratelimit provides a simple in-memory token bucket limiter, used
+// to cap how fast a single caller can open a resource (currently the
+// per-trip agent SSE and WebSocket streams) without starving other callers sharing the
+// same backing pool.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket tracks one key's remaining tokens as of lastRefill.
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Limiter is a keyed token bucket: each key gets its own bucket of
+// Burst tokens that refills at RatePerSec tokens/second, capped at Burst.
+// The zero value is not usable; construct one with NewLimiter.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	ratePerSec float64
+	burst      float64
+}
+
+// NewLimiter creates a Limiter allowing burst immediate calls per key, then
+// refilling at ratePerSec tokens/second thereafter.
+func NewLimiter(ratePerSec float64, burst int) *Limiter {
+	return &Limiter{
+		buckets:    make(map[string]*bucket),
+		ratePerSec: ratePerSec,
+		burst:      float64(burst),
+	}
+}
+
+// Allow reports whether key may proceed right now, consuming one token from
+// its bucket if so. Safe for concurrent use.
+func (l *Limiter) Allow(key string) bool {
+	return l.AllowAt(key, time.Now())
+}
+
+// AllowAt is Allow with an injectable clock, so tests can exercise refill
+// behavior without sleeping.
+func (l *Limiter) AllowAt(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, lastRefill: now}
+		l.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.lastRefill).Seconds()
+		if elapsed > 0 {
+			b.tokens = min(l.burst, b.tokens+elapsed*l.ratePerSec)
+			b.lastRefill = now
+		}
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_AllowsUpToBurstThenBlocks(t *testing.T) {
+	l := NewLimiter(1, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !l.AllowAt("user1:trip1", now) {
+			t.Fatalf("expected call %d within burst to be allowed", i)
+		}
+	}
+	if l.AllowAt("user1:trip1", now) {
+		t.Error("expected the 4th immediate call to be blocked")
+	}
+}
+
+func TestLimiter_RefillsOverTime(t *testing.T) {
+	l := NewLimiter(1, 1)
+	now := time.Now()
+
+	if !l.AllowAt("user1:trip1", now) {
+		t.Fatal("expected the first call to be allowed")
+	}
+	if l.AllowAt("user1:trip1", now) {
+		t.Error("expected an immediate second call to be blocked")
+	}
+	if !l.AllowAt("user1:trip1", now.Add(time.Second)) {
+		t.Error("expected a call one second later to be allowed after refill")
+	}
+}
+
+func TestLimiter_KeysAreIndependent(t *testing.T) {
+	l := NewLimiter(1, 1)
+	now := time.Now()
+
+	if !l.AllowAt("user1:trip1", now) {
+		t.Fatal("expected user1's call to be allowed")
+	}
+	if !l.AllowAt("user2:trip1", now) {
+		t.Error("expected user2's independent bucket to be allowed")
+	}
+}
@@ -0,0 +1,74 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// Broker abstracts the pub/sub transport ListenToDB, PublishTripEvent, and
+// HandleRPC publish through, decoupling them from any one backend.
+// CentrifugeBroker - wrapping the Service's own Node - is the default a
+// Service built by New uses; NATSBroker routes the same traffic through
+// NATS instead, and ChannelBroker is an in-memory double for tests.
+type Broker interface {
+	Publish(channel string, payload []byte) error
+	// Presence returns who is currently present on channel, in whatever
+	// terms the underlying transport tracks that - real subscriber state
+	// for CentrifugeBroker, or explicit Join/Leave bookkeeping for brokers
+	// (NATSBroker, ChannelBroker) with no native presence concept.
+	Presence(channel string) ([]ClientInfo, error)
+	Close() error
+}
+
+// ClientInfo describes one client present on a channel, as reported by a
+// Broker's Presence.
+type ClientInfo struct {
+	ClientID string          `json:"client_id"`
+	UserID   string          `json:"user_id"`
+	Info     json.RawMessage `json:"info,omitempty"`
+}
+
+// CentrifugeBroker publishes and reads presence through a *centrifuge.Node,
+// so the channel/presence state it reports is exactly what connected
+// clients see. It's the Broker every Service uses unless WithBroker (or
+// the Broker field) overrides it.
+type CentrifugeBroker struct {
+	Node *centrifuge.Node
+}
+
+var _ Broker = (*CentrifugeBroker)(nil)
+
+// NewCentrifugeBroker wraps node as a Broker.
+func NewCentrifugeBroker(node *centrifuge.Node) *CentrifugeBroker {
+	return &CentrifugeBroker{Node: node}
+}
+
+func (b *CentrifugeBroker) Publish(channel string, payload []byte) error {
+	_, err := b.Node.Publish(channel, payload)
+	return err
+}
+
+func (b *CentrifugeBroker) Presence(channel string) ([]ClientInfo, error) {
+	res, err := b.Node.Presence(channel)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ClientInfo, 0, len(res.Presence))
+	for clientID, info := range res.Presence {
+		entries = append(entries, ClientInfo{
+			ClientID: clientID,
+			UserID:   info.UserID,
+			Info:     json.RawMessage(info.Info),
+		})
+	}
+	return entries, nil
+}
+
+// Close shuts the wrapped Node down. Service.Close already does this for
+// its own Node, so it only calls a Broker's Close when an explicit,
+// non-default Broker was configured.
+func (b *CentrifugeBroker) Close() error {
+	return b.Node.Shutdown(context.Background())
+}
@@ -0,0 +1,80 @@
+package realtime
+
+import "sync"
+
+// ChannelBroker is an in-memory Broker for tests: Publish records each
+// payload instead of sending it anywhere, and Presence is driven by
+// explicit Join/Leave calls rather than real client connections. Use
+// Published to assert on what a test published without standing up a
+// Centrifuge node.
+type ChannelBroker struct {
+	mu        sync.Mutex
+	published map[string][][]byte
+	presence  map[string]map[string]ClientInfo
+	closed    bool
+}
+
+var _ Broker = (*ChannelBroker)(nil)
+
+// NewChannelBroker returns an empty ChannelBroker.
+func NewChannelBroker() *ChannelBroker {
+	return &ChannelBroker{
+		published: make(map[string][][]byte),
+		presence:  make(map[string]map[string]ClientInfo),
+	}
+}
+
+func (b *ChannelBroker) Publish(channel string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published[channel] = append(b.published[channel], payload)
+	return nil
+}
+
+func (b *ChannelBroker) Presence(channel string) ([]ClientInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := make([]ClientInfo, 0, len(b.presence[channel]))
+	for _, info := range b.presence[channel] {
+		entries = append(entries, info)
+	}
+	return entries, nil
+}
+
+func (b *ChannelBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Join records info as present on channel, for tests exercising Presence.
+func (b *ChannelBroker) Join(channel string, info ClientInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.presence[channel] == nil {
+		b.presence[channel] = make(map[string]ClientInfo)
+	}
+	b.presence[channel][info.ClientID] = info
+}
+
+// Leave removes clientID's presence from channel.
+func (b *ChannelBroker) Leave(channel, clientID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.presence[channel], clientID)
+}
+
+// Published returns every payload Publish recorded for channel, in order.
+func (b *ChannelBroker) Published(channel string) [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]byte(nil), b.published[channel]...)
+}
+
+// Closed reports whether Close has been called.
+func (b *ChannelBroker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
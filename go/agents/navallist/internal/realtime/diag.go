@@ -0,0 +1,47 @@
+package realtime
+
+import (
+	"context"
+	"sort"
+)
+
+// ChannelStat summarizes one trip's live presence, as reported by the
+// configured PresenceManager - shared across every replica when
+// NAVALLIST_RT_ENGINE=redis, so Diag gives the same answer no matter which
+// replica it's run against.
+type ChannelStat struct {
+	Channel    string
+	NumClients int
+	NumUsers   int
+}
+
+// Diag reports presence stats for every currently-active trip's channel,
+// backing the `navallist realtime diag` operator command.
+func (s *Service) Diag(ctx context.Context) ([]ChannelStat, error) {
+	trips, err := s.Store.ListActiveTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]ChannelStat, 0, len(trips))
+	for _, trip := range trips {
+		channel := s.tripChannel(trip.ID)
+
+		presenceStats, err := s.Node.PresenceStats(channel)
+		if err != nil {
+			continue
+		}
+		if presenceStats.NumClients == 0 {
+			continue
+		}
+
+		stats = append(stats, ChannelStat{
+			Channel:    channel,
+			NumClients: presenceStats.NumClients,
+			NumUsers:   presenceStats.NumUsers,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Channel < stats[j].Channel })
+	return stats, nil
+}
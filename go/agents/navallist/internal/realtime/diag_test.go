@@ -0,0 +1,59 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+func newTestNode(t *testing.T) *centrifuge.Node {
+	t.Helper()
+	node, err := centrifuge.New(centrifuge.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if err := applyEngine(node, EngineConfig{}); err != nil {
+		t.Fatalf("Failed to apply memory engine: %v", err)
+	}
+	if err := node.Run(); err != nil {
+		t.Fatalf("Failed to run node: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = node.Shutdown(context.Background())
+	})
+	return node
+}
+
+func TestDiagSkipsChannelsWithNoClients(t *testing.T) {
+	mockStore := &data.MockStore{
+		ListActiveTripsFunc: func(_ context.Context) ([]models.Trip, error) {
+			return []models.Trip{{ID: "trip_1"}, {ID: "trip_2"}}, nil
+		},
+	}
+	service := &Service{Store: mockStore, Node: newTestNode(t)}
+
+	stats, err := service.Diag(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("Expected no stats for channels with no connected clients, got %v", stats)
+	}
+}
+
+func TestDiagPropagatesStoreError(t *testing.T) {
+	mockStore := &data.MockStore{
+		ListActiveTripsFunc: func(_ context.Context) ([]models.Trip, error) {
+			return nil, context.DeadlineExceeded
+		},
+	}
+	service := &Service{Store: mockStore, Node: newTestNode(t)}
+
+	if _, err := service.Diag(context.Background()); err == nil {
+		t.Fatal("Expected error to propagate from Store.ListActiveTrips, got nil")
+	}
+}
@@ -0,0 +1,141 @@
+package realtime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// EngineConfig selects and configures the Centrifuge broker/presence-manager
+// pair a Service's Node runs on. The in-process default only works for a
+// single navallist replica, since presence, history, and published events
+// never leave that process; "redis" shares all three through Redis so every
+// replica behind the load balancer sees the same channel state.
+type EngineConfig struct {
+	Kind string // "memory" (default) or "redis"
+
+	// RedisAddrs configures one independent Redis shard per address,
+	// consistent-hash sharding channels across them. Ignored if
+	// RedisClusterAddrs is set.
+	RedisAddrs []string
+	// RedisClusterAddrs, if set, configures a single shard backed by a
+	// Redis Cluster spanning these addresses instead of RedisAddrs' plain
+	// sharding.
+	RedisClusterAddrs []string
+	RedisPassword     string
+
+	// HistorySize/HistoryTTL bound the replay buffer a reconnecting client
+	// uses to catch up on channel history it missed while disconnected.
+	HistorySize int
+	HistoryTTL  time.Duration
+}
+
+// DefaultHistorySize and DefaultHistoryTTL are used when cfg.HistorySize or
+// cfg.HistoryTTL are left at their zero value.
+const (
+	DefaultHistorySize = 100
+	DefaultHistoryTTL  = 5 * time.Minute
+)
+
+// applyEngine wires node's broker and presence manager according to
+// cfg.Kind. It must be called before node.Run().
+func applyEngine(node *centrifuge.Node, cfg EngineConfig) error {
+	switch cfg.Kind {
+	case "", "memory":
+		broker, err := centrifuge.NewMemoryBroker(node, centrifuge.MemoryBrokerConfig{})
+		if err != nil {
+			return fmt.Errorf("realtime: failed to create memory broker: %w", err)
+		}
+		node.SetBroker(broker)
+
+		presenceManager, err := centrifuge.NewMemoryPresenceManager(node, centrifuge.MemoryPresenceManagerConfig{})
+		if err != nil {
+			return fmt.Errorf("realtime: failed to create memory presence manager: %w", err)
+		}
+		node.SetPresenceManager(presenceManager)
+		return nil
+
+	case "redis":
+		shards, err := redisShards(node, cfg)
+		if err != nil {
+			return err
+		}
+
+		broker, err := centrifuge.NewRedisBroker(node, centrifuge.RedisBrokerConfig{Shards: shards})
+		if err != nil {
+			return fmt.Errorf("realtime: failed to create redis broker: %w", err)
+		}
+		node.SetBroker(broker)
+
+		presenceManager, err := centrifuge.NewRedisPresenceManager(node, centrifuge.RedisPresenceManagerConfig{Shards: shards})
+		if err != nil {
+			return fmt.Errorf("realtime: failed to create redis presence manager: %w", err)
+		}
+		node.SetPresenceManager(presenceManager)
+		return nil
+
+	default:
+		return fmt.Errorf("realtime: unknown engine kind %q", cfg.Kind)
+	}
+}
+
+// redisShards builds the RedisShards cfg.RedisAddrs/RedisClusterAddrs
+// describe, for use by both the broker and the presence manager.
+func redisShards(node *centrifuge.Node, cfg EngineConfig) ([]*centrifuge.RedisShard, error) {
+	if len(cfg.RedisClusterAddrs) > 0 {
+		shard, err := centrifuge.NewRedisShard(node, centrifuge.RedisShardConfig{
+			ClusterAddrs: cfg.RedisClusterAddrs,
+			Password:     cfg.RedisPassword,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("realtime: failed to create redis cluster shard: %w", err)
+		}
+		return []*centrifuge.RedisShard{shard}, nil
+	}
+
+	if len(cfg.RedisAddrs) == 0 {
+		return nil, fmt.Errorf("realtime: redis engine requires NAVALLIST_RT_REDIS_ADDRS or NAVALLIST_RT_REDIS_CLUSTER_ADDRS")
+	}
+
+	shards := make([]*centrifuge.RedisShard, 0, len(cfg.RedisAddrs))
+	for _, addr := range cfg.RedisAddrs {
+		shard, err := centrifuge.NewRedisShard(node, centrifuge.RedisShardConfig{
+			Address:  addr,
+			Password: cfg.RedisPassword,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("realtime: failed to create redis shard %q: %w", addr, err)
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
+// subscribeOptions returns the base SubscribeOptions (history, presence)
+// every trip channel subscription should use.
+func (s *Service) subscribeOptions(initialPresence []byte) centrifuge.SubscribeOptions {
+	return centrifuge.SubscribeOptions{
+		EmitPresence:   true,
+		EmitJoinLeave:  true,
+		PushJoinLeave:  true,
+		Data:           initialPresence,
+		EnableRecovery: true,
+		HistorySize:    s.historySize(),
+		HistoryTTL:     s.historyTTL(),
+	}
+}
+
+func (s *Service) historySize() int {
+	if s.Engine.HistorySize > 0 {
+		return s.Engine.HistorySize
+	}
+	return DefaultHistorySize
+}
+
+func (s *Service) historyTTL() time.Duration {
+	if s.Engine.HistoryTTL > 0 {
+		return s.Engine.HistoryTTL
+	}
+	return DefaultHistoryTTL
+}
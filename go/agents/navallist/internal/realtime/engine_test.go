@@ -0,0 +1,38 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisShardsRequiresAddresses(t *testing.T) {
+	_, err := redisShards(nil, EngineConfig{Kind: "redis"})
+	if err == nil {
+		t.Fatal("Expected error when neither RedisAddrs nor RedisClusterAddrs is set, got nil")
+	}
+}
+
+func TestApplyEngineUnknownKind(t *testing.T) {
+	err := applyEngine(nil, EngineConfig{Kind: "nats"})
+	if err == nil {
+		t.Fatal("Expected error for unknown engine kind, got nil")
+	}
+}
+
+func TestHistorySizeAndTTLDefaults(t *testing.T) {
+	s := &Service{}
+	if got := s.historySize(); got != DefaultHistorySize {
+		t.Errorf("historySize() = %v, want %v", got, DefaultHistorySize)
+	}
+	if got := s.historyTTL(); got != DefaultHistoryTTL {
+		t.Errorf("historyTTL() = %v, want %v", got, DefaultHistoryTTL)
+	}
+
+	s = &Service{Engine: EngineConfig{HistorySize: 42, HistoryTTL: time.Minute}}
+	if got := s.historySize(); got != 42 {
+		t.Errorf("historySize() = %v, want 42", got)
+	}
+	if got := s.historyTTL(); got != time.Minute {
+		t.Errorf("historyTTL() = %v, want %v", got, time.Minute)
+	}
+}
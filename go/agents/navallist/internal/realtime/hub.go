@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"navallist/internal/data"
 
@@ -14,12 +16,43 @@ import (
 
 // Service handles real-time communication via Centrifuge.
 type Service struct {
-	Node  *centrifuge.Node
-	Store data.Store
+	Node   *centrifuge.Node
+	Store  data.Store
+	Engine EngineConfig
+
+	// Broker, Logger, ChannelPrefix, ReconnectBackoff, NotificationChannel,
+	// and EventFilter are normally set via the matching With* Option passed
+	// to New; see broker(), logger(), channelPrefix(), reconnectBackoff(),
+	// and notificationChannel() in options.go for their zero-value defaults.
+	Broker              Broker
+	Logger              *log.Logger
+	ChannelPrefix       string
+	ReconnectBackoff    time.Duration
+	NotificationChannel string
+	EventFilter         func(DBEvent) bool
+
+	// channelProtocol tracks, per channel, whether subscribers asked for the
+	// binary protobuf wire format ("protobuf") instead of the JSON default.
+	// A channel is shared by every subscriber, so this is last-subscriber-wins:
+	// good enough while checklist channels are single-trip and single-format
+	// per deployment, but mixed-protocol subscribers on one channel would
+	// need per-client fanout instead.
+	channelProtocol sync.Map
+}
+
+// protocolFor returns the negotiated wire protocol for a channel ("json" by default).
+func (s *Service) protocolFor(channel string) string {
+	if v, ok := s.channelProtocol.Load(channel); ok {
+		return v.(string)
+	}
+	return "json"
 }
 
-// NewService initializes a new real-time service with the given store.
-func NewService(store data.Store) (*Service, error) {
+// New initializes a new real-time service with the given store, wiring its
+// Node to the broker/presence manager selected by engineCfg, then applying
+// opts (see WithBroker, WithLogger, WithChannelPrefix, WithReconnectBackoff,
+// WithNotificationChannel, WithEventFilter in options.go).
+func New(store data.Store, engineCfg EngineConfig, opts ...Option) (*Service, error) {
 	node, err := centrifuge.New(centrifuge.Config{
 		LogLevel: centrifuge.LogLevelDebug,
 		LogHandler: func(entry centrifuge.LogEntry) {
@@ -31,9 +64,17 @@ func NewService(store data.Store) (*Service, error) {
 		return nil, err
 	}
 
+	if err := applyEngine(node, engineCfg); err != nil {
+		return nil, err
+	}
+
 	service := &Service{
-		Node:  node,
-		Store: store,
+		Node:   node,
+		Store:  store,
+		Engine: engineCfg,
+	}
+	for _, opt := range opts {
+		opt(service)
 	}
 
 	// OnConnecting is where we authenticate the user based on the context populated by middleware
@@ -41,50 +82,33 @@ func NewService(store data.Store) (*Service, error) {
 
 	// OnConnect is called after successful authentication
 	node.OnConnect(func(client *centrifuge.Client) {
-		log.Info("Realtime: OnConnect called", "user", client.UserID())
+		service.logger().Info("Realtime: OnConnect called", "user", client.UserID())
 
 		client.OnSubscribe(func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
-			// Channel format: "trip:{trip_id}"
-			if !strings.HasPrefix(e.Channel, "trip:") {
-				cb(centrifuge.SubscribeReply{}, fmt.Errorf("invalid channel format"))
-				return
+			userID := client.UserID()
+			// Guests get synthetic "guest_"-prefixed IDs (see HandleConnect)
+			// rather than a real account, so IsTripCrew treats them like
+			// anonymous link-joiners instead of checking trip_crew for them.
+			if strings.HasPrefix(userID, "guest_") {
+				userID = ""
 			}
 
-			tripID := strings.TrimPrefix(e.Channel, "trip:")
-
-			// Verify access using the store
-			trip, err := store.GetTrip(context.Background(), tripID)
+			opts, err := service.HandleSubscribe(context.Background(), e.Channel, userID)
 			if err != nil {
-				cb(centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied)
+				cb(centrifuge.SubscribeReply{}, err)
 				return
 			}
 
-			// If trip exists, allow subscription.
-			_ = trip // unused variable for now
-
-			// Try to fetch initial presence list from server-side to bypass potential client-side restriction
-			var initialPresence []byte
-			res, err := node.Presence(e.Channel)
-			if err == nil {
-				// We need to marshal just the map to match client expectation
-				if res.Presence == nil {
-					initialPresence = []byte("{}")
-				} else {
-					initialPresence, _ = json.Marshal(res.Presence)
-				}
-			} else {
-				log.Warn("Failed to fetch initial presence on server", "error", err)
-				initialPresence = []byte("{}")
-			}
+			// Record the negotiated wire protocol for this channel so
+			// listener.go knows whether to publish JSON or protobuf frames.
+			service.channelProtocol.Store(e.Channel, clientProtocol(client))
 
-			cb(centrifuge.SubscribeReply{
-				Options: centrifuge.SubscribeOptions{
-					EmitPresence:  true,
-					EmitJoinLeave: true,
-					PushJoinLeave: true,
-					Data:          initialPresence,
-				},
-			}, nil)
+			cb(centrifuge.SubscribeReply{Options: opts}, nil)
+		})
+
+		client.OnRPC(func(e centrifuge.RPCEvent, cb centrifuge.RPCCallback) {
+			reply, err := service.HandleRPC(client, e)
+			cb(reply, err)
 		})
 	})
 
@@ -95,27 +119,197 @@ func NewService(store data.Store) (*Service, error) {
 	return service, nil
 }
 
+// Publisher lets a handler push an event onto a trip's channel, or read who
+// is currently subscribed to one, without depending on the rest of
+// realtime.Service. Service implements it via PublishTripEvent and Presence.
+type Publisher interface {
+	PublishTripEvent(ctx context.Context, tripID string, event any) error
+	// Presence returns who is currently subscribed to tripID's channel,
+	// keyed by client ID, backing the GET .../presence REST fallback for
+	// clients that can't hold a WS connection.
+	Presence(ctx context.Context, tripID string) (map[string]PresenceEntry, error)
+}
+
+// PresenceEntry describes one client subscribed to a trip's channel.
+type PresenceEntry struct {
+	UserID string          `json:"user_id"`
+	Info   json.RawMessage `json:"info,omitempty"`
+}
+
+// HandleSubscribe validates a client's subscription to channel - which must
+// be a "trip:{id}" channel the user has access to per Store.IsTripCrew -
+// and, on success, returns the SubscribeOptions to reply with, seeded with
+// the channel's current presence snapshot.
+func (s *Service) HandleSubscribe(ctx context.Context, channel, userID string) (centrifuge.SubscribeOptions, error) {
+	tripID, ok := s.tripIDFromChannel(channel)
+	if !ok {
+		return centrifuge.SubscribeOptions{}, fmt.Errorf("invalid channel format")
+	}
+
+	isCrew, err := s.Store.IsTripCrew(ctx, tripID, userID)
+	if err != nil || !isCrew {
+		return centrifuge.SubscribeOptions{}, centrifuge.ErrorPermissionDenied
+	}
+
+	// Try to fetch initial presence list from server-side to bypass potential
+	// client-side restriction. This stays on Node.Presence (rather than
+	// Broker.Presence) since its JSON shape is consumed directly by the
+	// subscribing centrifuge-js client, which expects Centrifuge's native
+	// presence format.
+	var initialPresence []byte
+	res, err := s.Node.Presence(channel)
+	if err == nil && res.Presence != nil {
+		initialPresence, _ = json.Marshal(res.Presence)
+	} else {
+		if err != nil {
+			s.logger().Warn("Failed to fetch initial presence on server", "error", err)
+		}
+		initialPresence = []byte("{}")
+	}
+
+	return s.subscribeOptions(initialPresence), nil
+}
+
+// presenceRPCMethods are the client->server RPCs that broadcast ephemeral
+// per-trip UI hints - who's focused on which checklist item, who's typing,
+// live cursor position - rather than persisted state. Unlike PublishTripEvent,
+// HandleRPC publishes them without history: a client that misses one just
+// waits for the next.
+var presenceRPCMethods = map[string]bool{
+	"focus_item": true,
+	"typing":     true,
+	"cursor":     true,
+}
+
+// HandleRPC dispatches a client->server RPC call. Only the presence RPCs in
+// presenceRPCMethods are supported, and only for trip channels the calling
+// client is already subscribed to.
+func (s *Service) HandleRPC(client *centrifuge.Client, e centrifuge.RPCEvent) (centrifuge.RPCReply, error) {
+	if !presenceRPCMethods[e.Method] {
+		return centrifuge.RPCReply{}, fmt.Errorf("realtime: unknown rpc method %q", e.Method)
+	}
+
+	tripID, err := parsePresenceRPCTripID(e.Data)
+	if err != nil {
+		return centrifuge.RPCReply{}, err
+	}
+
+	channel := s.tripChannel(tripID)
+	if _, subscribed := client.Channels()[channel]; !subscribed {
+		return centrifuge.RPCReply{}, centrifuge.ErrorPermissionDenied
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"type":    e.Method,
+		"trip_id": tripID,
+		"user_id": client.UserID(),
+		"info":    json.RawMessage(client.Info()),
+		"data":    json.RawMessage(e.Data),
+	})
+	if err != nil {
+		return centrifuge.RPCReply{}, fmt.Errorf("realtime: failed to marshal %s payload: %w", e.Method, err)
+	}
+
+	if err := s.broker().Publish(channel, payload); err != nil {
+		return centrifuge.RPCReply{}, fmt.Errorf("realtime: failed to publish %s to %s: %w", e.Method, channel, err)
+	}
+
+	return centrifuge.RPCReply{}, nil
+}
+
+// parsePresenceRPCTripID extracts "trip_id" from a presence RPC's raw
+// request payload.
+func parsePresenceRPCTripID(data []byte) (string, error) {
+	var req struct {
+		TripID string `json:"trip_id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return "", fmt.Errorf("realtime: invalid rpc payload: %w", err)
+	}
+	if req.TripID == "" {
+		return "", fmt.Errorf("realtime: rpc payload missing trip_id")
+	}
+	return req.TripID, nil
+}
+
+// Presence returns who is currently subscribed to tripID's channel, keyed
+// by client ID.
+func (s *Service) Presence(ctx context.Context, tripID string) (map[string]PresenceEntry, error) {
+	clients, err := s.broker().Presence(s.tripChannel(tripID))
+	if err != nil {
+		return nil, fmt.Errorf("realtime: failed to fetch presence for trip %s: %w", tripID, err)
+	}
+
+	entries := make(map[string]PresenceEntry, len(clients))
+	for _, client := range clients {
+		entries[client.ClientID] = PresenceEntry{
+			UserID: client.UserID,
+			Info:   client.Info,
+		}
+	}
+	return entries, nil
+}
+
+// PublishTripEvent publishes event, JSON-encoded, to the given trip's
+// channel. Unlike the checklist_item/artifact flow in listener.go - which
+// reacts to Postgres NOTIFY so any replica's DB trigger output reaches
+// every replica's subscribers - trip status/type changes are published
+// directly from the handler that made them, since there's no DB trigger
+// wired up for the trip table's non-timestamp columns.
+func (s *Service) PublishTripEvent(ctx context.Context, tripID string, event any) error {
+	channel := s.tripChannel(tripID)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("realtime: failed to marshal event for trip %s: %w", tripID, err)
+	}
+
+	if err := s.broker().Publish(channel, payload); err != nil {
+		return fmt.Errorf("realtime: failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Close shuts the Node down, disconnecting clients and releasing the
+// underlying broker/presence manager connections (e.g. the Redis clients
+// the "redis" engine opened), then closes an explicitly-configured Broker
+// too (the default CentrifugeBroker has nothing to close beyond Node).
+func (s *Service) Close(ctx context.Context) error {
+	if err := s.Node.Shutdown(ctx); err != nil {
+		return err
+	}
+	if s.Broker != nil {
+		return s.Broker.Close()
+	}
+	return nil
+}
+
 // HandleConnect authenticates the user based on the context populated by middleware.
 func (s *Service) HandleConnect(ctx context.Context, e centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
 	userID := data.GetUserID(ctx)
 	guestName := data.GetGuestName(ctx)
+	protocol := parseRequestedProtocol(e.Data)
 
 	transportName := "unknown"
 	if e.Transport != nil {
 		transportName = e.Transport.Name()
 	}
 
-	log.Info("Realtime: OnConnecting called",
+	s.logger().Info("Realtime: OnConnecting called",
 		"transport", transportName,
 		"userID", userID,
-		"guestName", guestName)
+		"guestName", guestName,
+		"protocol", protocol)
 
 	if userID == "" && guestName == "" {
-		log.Info("Realtime: Anonymous connection", "transport", transportName)
+		s.logger().Info("Realtime: Anonymous connection", "transport", transportName)
 		// Return empty credentials for anonymous access
+		info := map[string]string{"protocol": protocol}
+		userData, _ := json.Marshal(info)
 		return centrifuge.ConnectReply{
 			Credentials: &centrifuge.Credentials{
 				UserID: "",
+				Info:   userData,
 			},
 		}, nil
 	}
@@ -128,7 +322,7 @@ func (s *Service) HandleConnect(ctx context.Context, e centrifuge.ConnectEvent)
 		cleanName := strings.TrimPrefix(guestName, "guest_")
 		guestID := "guest_" + cleanName
 
-		info := map[string]string{"name": cleanName}
+		info := map[string]string{"name": cleanName, "protocol": protocol}
 		userData, _ = json.Marshal(info)
 
 		return centrifuge.ConnectReply{
@@ -142,12 +336,12 @@ func (s *Service) HandleConnect(ctx context.Context, e centrifuge.ConnectEvent)
 	// Fetch User Name to send as Client Info
 	user, err := s.Store.GetUser(context.Background(), userID)
 	if err == nil && user.Name != nil {
-		// Format: {"name": "Captain Steve"}
-		info := map[string]string{"name": *user.Name}
+		// Format: {"name": "Captain Steve", "protocol": "json"}
+		info := map[string]string{"name": *user.Name, "protocol": protocol}
 		userData, _ = json.Marshal(info)
 	} else {
 		// Fallback to ID if name not found or error
-		info := map[string]string{"name": userID}
+		info := map[string]string{"name": userID, "protocol": protocol}
 		userData, _ = json.Marshal(info)
 	}
 
@@ -158,3 +352,31 @@ func (s *Service) HandleConnect(ctx context.Context, e centrifuge.ConnectEvent)
 		},
 	}, nil
 }
+
+// parseRequestedProtocol reads the "protocol" connect param a client sends
+// to opt into the binary protobuf wire format; anything else (including no
+// param at all) falls back to "json".
+func parseRequestedProtocol(connectParams []byte) string {
+	if len(connectParams) == 0 {
+		return "json"
+	}
+	var params struct {
+		Protocol string `json:"protocol"`
+	}
+	if err := json.Unmarshal(connectParams, &params); err != nil || params.Protocol != "protobuf" {
+		return "json"
+	}
+	return "protobuf"
+}
+
+// clientProtocol reads back the protocol negotiated at connect time from the
+// client's Info, which HandleConnect populated above.
+func clientProtocol(client *centrifuge.Client) string {
+	var info struct {
+		Protocol string `json:"protocol"`
+	}
+	if err := json.Unmarshal(client.Info(), &info); err != nil || info.Protocol != "protobuf" {
+		return "json"
+	}
+	return "protobuf"
+}
@@ -5,7 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/charmbracelet/log"
+	"navallist/internal/data/models"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -17,19 +18,21 @@ type DBEvent struct {
 	TripID string          `json:"trip_id"`
 }
 
-// ListenToDB starts a loop to listen for database notifications and publish them to Centrifuge.
+// ListenToDB starts a loop to listen for database notifications and
+// publish them through s.Broker (see WithBroker).
 func (s *Service) ListenToDB(ctx context.Context, connStr string) {
 	// Loop to handle reconnection
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info("Stopping DB listener")
+			s.logger().Info("Stopping DB listener")
 			return
 		default:
 			err := s.listenLoop(ctx, connStr)
 			if err != nil {
-				log.Error("DB Listener failed, retrying in 5s", "error", err)
-				time.Sleep(5 * time.Second)
+				backoff := s.reconnectBackoff()
+				s.logger().Error("DB Listener failed, retrying", "backoff", backoff, "error", err)
+				time.Sleep(backoff)
 			}
 		}
 	}
@@ -42,16 +45,16 @@ func (s *Service) listenLoop(ctx context.Context, connStr string) error {
 	}
 	defer func() {
 		if err := conn.Close(ctx); err != nil {
-			log.Error("failed to close database connection", "error", err)
+			s.logger().Error("failed to close database connection", "error", err)
 		}
 	}()
 
-	_, err = conn.Exec(ctx, "LISTEN db_events")
-	if err != nil {
+	notificationChannel := s.notificationChannel()
+	if _, err := conn.Exec(ctx, "LISTEN "+notificationChannel); err != nil {
 		return err
 	}
 
-	log.Info("Listening for Postgres notifications on channel 'db_events'")
+	s.logger().Info("Listening for Postgres notifications", "channel", notificationChannel)
 
 	for {
 		notification, err := conn.WaitForNotification(ctx)
@@ -61,7 +64,7 @@ func (s *Service) listenLoop(ctx context.Context, connStr string) error {
 
 		var event DBEvent
 		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
-			log.Error("Failed to parse notification", "payload", notification.Payload, "error", err)
+			s.logger().Error("Failed to parse notification", "payload", notification.Payload, "error", err)
 			continue
 		}
 
@@ -70,11 +73,52 @@ func (s *Service) listenLoop(ctx context.Context, connStr string) error {
 			continue
 		}
 
-		channel := "trip:" + event.TripID
+		if s.EventFilter != nil && !s.EventFilter(event) {
+			continue
+		}
+
+		channel := s.tripChannel(event.TripID)
+
+		payload := []byte(notification.Payload)
+		if s.protocolFor(channel) == "protobuf" {
+			if encoded, ok := encodeBinaryFrame(event); ok {
+				payload = encoded
+			} else {
+				s.logger().Warn("Falling back to JSON for unencodable event", "channel", channel, "table", event.Table)
+			}
+		}
+
+		if err := s.broker().Publish(channel, payload); err != nil {
+			s.logger().Error("Failed to publish event", "channel", channel, "error", err)
+		}
+	}
+}
 
-		_, err = s.Node.Publish(channel, []byte(notification.Payload))
+// encodeBinaryFrame re-encodes a DB change notification as the protobuf
+// message matching its table, for channels that negotiated protocol=protobuf.
+func encodeBinaryFrame(event DBEvent) ([]byte, bool) {
+	switch event.Table {
+	case "checklist_item":
+		var item models.ChecklistItem
+		if err := json.Unmarshal(event.Data, &item); err != nil {
+			return nil, false
+		}
+		encoded, err := item.MarshalBinary()
+		if err != nil {
+			return nil, false
+		}
+		return encoded, true
+	case "artifact":
+		var artifact models.Artifact
+		if err := json.Unmarshal(event.Data, &artifact); err != nil {
+			return nil, false
+		}
+		encoded, err := artifact.MarshalBinary()
 		if err != nil {
-			log.Error("Failed to publish to Centrifuge", "channel", channel, "error", err)
+			return nil, false
 		}
+		return encoded, true
+	default:
+		return nil, false
 	}
 }
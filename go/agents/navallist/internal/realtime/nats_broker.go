@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSBroker publishes through a NATS connection instead of a Centrifuge
+// Node, for deployments that already run a NATS cluster and want
+// ListenToDB/PublishTripEvent/HandleRPC traffic to ride on it rather than
+// a second broker.
+//
+// NATS has no concept of a subject's current subscriber list, so unlike
+// CentrifugeBroker, NATSBroker tracks presence itself: callers drive it
+// with Join/Leave, typically from the subscribe/unsubscribe hooks of
+// whatever's consuming the subject on the other end.
+type NATSBroker struct {
+	conn *nats.Conn
+
+	mu       sync.Mutex
+	presence map[string]map[string]ClientInfo
+}
+
+var _ Broker = (*NATSBroker)(nil)
+
+// NewNATSBroker wraps conn as a Broker.
+func NewNATSBroker(conn *nats.Conn) *NATSBroker {
+	return &NATSBroker{conn: conn, presence: make(map[string]map[string]ClientInfo)}
+}
+
+func (b *NATSBroker) Publish(channel string, payload []byte) error {
+	return b.conn.Publish(channel, payload)
+}
+
+// Join records info as present on channel.
+func (b *NATSBroker) Join(channel string, info ClientInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.presence[channel] == nil {
+		b.presence[channel] = make(map[string]ClientInfo)
+	}
+	b.presence[channel][info.ClientID] = info
+}
+
+// Leave removes clientID's presence from channel.
+func (b *NATSBroker) Leave(channel, clientID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.presence[channel], clientID)
+}
+
+func (b *NATSBroker) Presence(channel string) ([]ClientInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := make([]ClientInfo, 0, len(b.presence[channel]))
+	for _, info := range b.presence[channel] {
+		entries = append(entries, info)
+	}
+	return entries, nil
+}
+
+func (b *NATSBroker) Close() error {
+	b.conn.Close()
+	return nil
+}
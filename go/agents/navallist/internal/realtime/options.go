@@ -0,0 +1,109 @@
+package realtime
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// defaultChannelPrefix/defaultReconnectBackoff/defaultNotificationChannel
+// are used when a Service's corresponding field is left at its zero value,
+// the same pattern historySize/historyTTL use for Engine's HistorySize/
+// HistoryTTL.
+const (
+	defaultChannelPrefix       = "trip:"
+	defaultReconnectBackoff    = 5 * time.Second
+	defaultNotificationChannel = "db_events"
+)
+
+// Option configures a Service built by New.
+type Option func(*Service)
+
+// WithBroker overrides the Broker Service publishes through. The default
+// is a CentrifugeBroker wrapping the Node New builds from EngineConfig.
+func WithBroker(broker Broker) Option {
+	return func(s *Service) { s.Broker = broker }
+}
+
+// WithLogger overrides where Service logs. The default is log.Default().
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Service) { s.Logger = logger }
+}
+
+// WithChannelPrefix overrides the prefix Service prepends to a trip ID to
+// form its channel name. The default is "trip:".
+func WithChannelPrefix(prefix string) Option {
+	return func(s *Service) { s.ChannelPrefix = prefix }
+}
+
+// WithReconnectBackoff overrides how long ListenToDB waits before retrying
+// a dropped Postgres LISTEN connection. The default is 5s.
+func WithReconnectBackoff(d time.Duration) Option {
+	return func(s *Service) { s.ReconnectBackoff = d }
+}
+
+// WithNotificationChannel overrides the Postgres NOTIFY channel ListenToDB
+// subscribes to. The default is "db_events". Distinct Services can use
+// distinct notification channels to shard one Postgres instance's change
+// feed across multiple listeners, e.g. one per trip shard.
+func WithNotificationChannel(channel string) Option {
+	return func(s *Service) { s.NotificationChannel = channel }
+}
+
+// WithEventFilter restricts which DBEvents ListenToDB publishes; events
+// filter returns false for are dropped before reaching the Broker. The
+// default (nil) publishes everything.
+func WithEventFilter(filter func(DBEvent) bool) Option {
+	return func(s *Service) { s.EventFilter = filter }
+}
+
+func (s *Service) broker() Broker {
+	if s.Broker != nil {
+		return s.Broker
+	}
+	return NewCentrifugeBroker(s.Node)
+}
+
+func (s *Service) logger() *log.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return log.Default()
+}
+
+func (s *Service) channelPrefix() string {
+	if s.ChannelPrefix != "" {
+		return s.ChannelPrefix
+	}
+	return defaultChannelPrefix
+}
+
+func (s *Service) reconnectBackoff() time.Duration {
+	if s.ReconnectBackoff > 0 {
+		return s.ReconnectBackoff
+	}
+	return defaultReconnectBackoff
+}
+
+func (s *Service) notificationChannel() string {
+	if s.NotificationChannel != "" {
+		return s.NotificationChannel
+	}
+	return defaultNotificationChannel
+}
+
+// tripChannel builds the channel/subject name for tripID.
+func (s *Service) tripChannel(tripID string) string {
+	return s.channelPrefix() + tripID
+}
+
+// tripIDFromChannel extracts the trip ID from a channel built by
+// tripChannel, reporting false if channel doesn't have the right prefix.
+func (s *Service) tripIDFromChannel(channel string) (string, bool) {
+	prefix := s.channelPrefix()
+	if !strings.HasPrefix(channel, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(channel, prefix), true
+}
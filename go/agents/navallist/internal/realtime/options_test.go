@@ -0,0 +1,137 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestOptionDefaults(t *testing.T) {
+	s := &Service{}
+	if got := s.channelPrefix(); got != defaultChannelPrefix {
+		t.Errorf("channelPrefix() = %q, want %q", got, defaultChannelPrefix)
+	}
+	if got := s.reconnectBackoff(); got != defaultReconnectBackoff {
+		t.Errorf("reconnectBackoff() = %v, want %v", got, defaultReconnectBackoff)
+	}
+	if got := s.notificationChannel(); got != defaultNotificationChannel {
+		t.Errorf("notificationChannel() = %q, want %q", got, defaultNotificationChannel)
+	}
+	if s.logger() == nil {
+		t.Error("logger() = nil, want a default logger")
+	}
+	if _, ok := s.broker().(*CentrifugeBroker); !ok {
+		t.Errorf("broker() = %T, want *CentrifugeBroker default", s.broker())
+	}
+}
+
+func TestOptionsOverrideDefaults(t *testing.T) {
+	s := &Service{}
+	broker := NewChannelBroker()
+	opts := []Option{
+		WithBroker(broker),
+		WithChannelPrefix("boat:"),
+		WithReconnectBackoff(2 * time.Second),
+		WithNotificationChannel("custom_events"),
+		WithEventFilter(func(e DBEvent) bool { return e.Table == "artifact" }),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.broker() != broker {
+		t.Errorf("broker() = %v, want the configured ChannelBroker", s.broker())
+	}
+	if got := s.channelPrefix(); got != "boat:" {
+		t.Errorf("channelPrefix() = %q, want %q", got, "boat:")
+	}
+	if got := s.reconnectBackoff(); got != 2*time.Second {
+		t.Errorf("reconnectBackoff() = %v, want 2s", got)
+	}
+	if got := s.notificationChannel(); got != "custom_events" {
+		t.Errorf("notificationChannel() = %q, want %q", got, "custom_events")
+	}
+	if s.EventFilter == nil || !s.EventFilter(DBEvent{Table: "artifact"}) || s.EventFilter(DBEvent{Table: "checklist_item"}) {
+		t.Error("EventFilter was not wired as configured")
+	}
+}
+
+func TestTripChannelRoundTrip(t *testing.T) {
+	s := &Service{ChannelPrefix: "boat:"}
+	channel := s.tripChannel("trip1")
+	if channel != "boat:trip1" {
+		t.Errorf("tripChannel(%q) = %q, want %q", "trip1", channel, "boat:trip1")
+	}
+
+	tripID, ok := s.tripIDFromChannel(channel)
+	if !ok || tripID != "trip1" {
+		t.Errorf("tripIDFromChannel(%q) = (%q, %v), want (%q, true)", channel, tripID, ok, "trip1")
+	}
+
+	if _, ok := s.tripIDFromChannel("lobby"); ok {
+		t.Error("tripIDFromChannel(\"lobby\") should fail for a non-matching prefix")
+	}
+}
+
+func TestChannelBroker_PublishAndPresence(t *testing.T) {
+	b := NewChannelBroker()
+
+	if err := b.Publish("trip:1", []byte("a")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := b.Publish("trip:1", []byte("b")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	got := b.Published("trip:1")
+	if len(got) != 2 || string(got[0]) != "a" || string(got[1]) != "b" {
+		t.Errorf("Published(%q) = %v, want [a b]", "trip:1", got)
+	}
+
+	b.Join("trip:1", ClientInfo{ClientID: "c1", UserID: "u1"})
+	entries, err := b.Presence("trip:1")
+	if err != nil {
+		t.Fatalf("Presence failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ClientID != "c1" {
+		t.Errorf("Presence(%q) = %v, want one entry for c1", "trip:1", entries)
+	}
+
+	b.Leave("trip:1", "c1")
+	entries, _ = b.Presence("trip:1")
+	if len(entries) != 0 {
+		t.Errorf("Presence(%q) after Leave = %v, want none", "trip:1", entries)
+	}
+
+	if b.Closed() {
+		t.Error("Closed() = true before Close was called")
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !b.Closed() {
+		t.Error("Closed() = false after Close was called")
+	}
+}
+
+func TestPublishTripEvent_UsesConfiguredBroker(t *testing.T) {
+	broker := NewChannelBroker()
+	s := &Service{Broker: broker}
+
+	if err := s.PublishTripEvent(context.Background(), "trip1", map[string]string{"status": "active"}); err != nil {
+		t.Fatalf("PublishTripEvent failed: %v", err)
+	}
+
+	published := broker.Published("trip:trip1")
+	if len(published) != 1 {
+		t.Fatalf("expected one published event, got %d", len(published))
+	}
+	var event map[string]string
+	if err := json.Unmarshal(published[0], &event); err != nil {
+		t.Fatalf("failed to unmarshal published event: %v", err)
+	}
+	if event["status"] != "active" {
+		t.Errorf("expected status=active, got %+v", event)
+	}
+}
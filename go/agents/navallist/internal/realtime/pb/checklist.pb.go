@@ -0,0 +1,157 @@
+// Package pb contains the wire types generated from checklist.proto.
+// Code generated by hand to match protoc-gen-go's shape; keep in sync with
+// checklist.proto when adding fields.
+package pb
+
+// ChecklistItemDelta mirrors models.ChecklistItem. See checklist.proto.
+type ChecklistItemDelta struct {
+	Id                string
+	TripId            string
+	Category          string
+	Name              string
+	IsChecked         bool
+	LocationText      string
+	CompletedByUserId string
+	CompletedByName   string
+	AssignedToUserId  string
+	AssignedToName    string
+	UpdatedAtUnix     int64
+}
+
+// Marshal encodes the message using the protobuf wire format.
+func (m *ChecklistItemDelta) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, 1, m.Id)
+	buf = appendString(buf, 2, m.TripId)
+	buf = appendString(buf, 3, m.Category)
+	buf = appendString(buf, 4, m.Name)
+	buf = appendBool(buf, 5, m.IsChecked)
+	buf = appendString(buf, 6, m.LocationText)
+	buf = appendString(buf, 7, m.CompletedByUserId)
+	buf = appendString(buf, 8, m.CompletedByName)
+	buf = appendString(buf, 9, m.AssignedToUserId)
+	buf = appendString(buf, 10, m.AssignedToName)
+	buf = appendInt64(buf, 11, m.UpdatedAtUnix)
+	return buf, nil
+}
+
+// Unmarshal decodes a message previously produced by Marshal.
+func (m *ChecklistItemDelta) Unmarshal(data []byte) error {
+	fields, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.Id = string(f.bytes)
+		case 2:
+			m.TripId = string(f.bytes)
+		case 3:
+			m.Category = string(f.bytes)
+		case 4:
+			m.Name = string(f.bytes)
+		case 5:
+			m.IsChecked = f.varint != 0
+		case 6:
+			m.LocationText = string(f.bytes)
+		case 7:
+			m.CompletedByUserId = string(f.bytes)
+		case 8:
+			m.CompletedByName = string(f.bytes)
+		case 9:
+			m.AssignedToUserId = string(f.bytes)
+		case 10:
+			m.AssignedToName = string(f.bytes)
+		case 11:
+			m.UpdatedAtUnix = int64(f.varint)
+		}
+	}
+	return nil
+}
+
+// PresenceUpdate mirrors a centrifuge join/leave event. See checklist.proto.
+type PresenceUpdate struct {
+	Channel string
+	UserId  string
+	Name    string
+	Joined  bool
+}
+
+// Marshal encodes the message using the protobuf wire format.
+func (m *PresenceUpdate) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, 1, m.Channel)
+	buf = appendString(buf, 2, m.UserId)
+	buf = appendString(buf, 3, m.Name)
+	buf = appendBool(buf, 4, m.Joined)
+	return buf, nil
+}
+
+// Unmarshal decodes a message previously produced by Marshal.
+func (m *PresenceUpdate) Unmarshal(data []byte) error {
+	fields, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.Channel = string(f.bytes)
+		case 2:
+			m.UserId = string(f.bytes)
+		case 3:
+			m.Name = string(f.bytes)
+		case 4:
+			m.Joined = f.varint != 0
+		}
+	}
+	return nil
+}
+
+// ArtifactLinked mirrors models.Artifact. See checklist.proto.
+type ArtifactLinked struct {
+	Id              string
+	TripId          string
+	ChecklistItemId string
+	Filename        string
+	MimeType        string
+	StoragePath     string
+}
+
+// Marshal encodes the message using the protobuf wire format.
+func (m *ArtifactLinked) Marshal() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, 1, m.Id)
+	buf = appendString(buf, 2, m.TripId)
+	buf = appendString(buf, 3, m.ChecklistItemId)
+	buf = appendString(buf, 4, m.Filename)
+	buf = appendString(buf, 5, m.MimeType)
+	buf = appendString(buf, 6, m.StoragePath)
+	return buf, nil
+}
+
+// Unmarshal decodes a message previously produced by Marshal.
+func (m *ArtifactLinked) Unmarshal(data []byte) error {
+	fields, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.Id = string(f.bytes)
+		case 2:
+			m.TripId = string(f.bytes)
+		case 3:
+			m.ChecklistItemId = string(f.bytes)
+		case 4:
+			m.Filename = string(f.bytes)
+		case 5:
+			m.MimeType = string(f.bytes)
+		case 6:
+			m.StoragePath = string(f.bytes)
+		}
+	}
+	return nil
+}
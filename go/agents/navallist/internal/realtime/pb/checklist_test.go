@@ -0,0 +1,36 @@
+package pb
+
+import "testing"
+
+func TestChecklistItemDeltaRoundTrip(t *testing.T) {
+	original := ChecklistItemDelta{
+		Id:              "item_1",
+		TripId:          "trip_1",
+		Category:        "Safety",
+		Name:            "Flares",
+		IsChecked:       true,
+		LocationText:    "Port locker",
+		CompletedByName: "Captain Steve",
+		UpdatedAtUnix:   1700000000,
+	}
+
+	encoded, err := original.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded ChecklistItemDelta
+	if err := decoded.Unmarshal(encoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if decoded != original {
+		t.Errorf("round trip mismatch: got %+v, want %+v", decoded, original)
+	}
+}
+
+func TestDecodeUnknownTable(t *testing.T) {
+	if _, err := Decode("unknown_table", nil); err == nil {
+		t.Error("expected error for unknown table, got nil")
+	}
+}
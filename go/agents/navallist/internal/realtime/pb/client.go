@@ -0,0 +1,25 @@
+package pb
+
+import "fmt"
+
+// Decode dispatches a raw frame to the matching message type based on the
+// table name carried alongside it (see realtime.DBEvent.Table), for Go
+// clients of the realtime websocket that negotiated protocol=protobuf.
+func Decode(table string, data []byte) (interface{}, error) {
+	switch table {
+	case "checklist_item":
+		var m ChecklistItemDelta
+		if err := m.Unmarshal(data); err != nil {
+			return nil, fmt.Errorf("pb: failed to decode ChecklistItemDelta: %w", err)
+		}
+		return &m, nil
+	case "artifact":
+		var m ArtifactLinked
+		if err := m.Unmarshal(data); err != nil {
+			return nil, fmt.Errorf("pb: failed to decode ArtifactLinked: %w", err)
+		}
+		return &m, nil
+	default:
+		return nil, fmt.Errorf("pb: unknown table %q", table)
+	}
+}
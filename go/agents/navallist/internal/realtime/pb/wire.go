@@ -0,0 +1,90 @@
+package pb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// wire.go implements the minimal subset of the protobuf wire format (varints
+// and length-delimited fields) needed to encode/decode the messages in this
+// package without pulling in the full protobuf runtime, since every field
+// here is a string, bool, or int64.
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendTag(buf []byte, fieldNum int, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(fieldNum)<<3|uint64(wireType))
+}
+
+func appendString(buf []byte, fieldNum int, v string) []byte {
+	if v == "" {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, wireBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+func appendBool(buf []byte, fieldNum int, v bool) []byte {
+	if !v {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, wireVarint)
+	return binary.AppendUvarint(buf, 1)
+}
+
+func appendInt64(buf []byte, fieldNum int, v int64) []byte {
+	if v == 0 {
+		return buf
+	}
+	buf = appendTag(buf, fieldNum, wireVarint)
+	return binary.AppendUvarint(buf, uint64(v))
+}
+
+// decodedField is one (field number, wire type, value) triple read off the wire.
+type decodedField struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+func decodeFields(data []byte) ([]decodedField, error) {
+	var fields []decodedField
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("pb: invalid tag")
+		}
+		data = data[n:]
+
+		field := decodedField{num: int(tag >> 3), wireType: int(tag & 0x7)}
+		switch field.wireType {
+		case wireVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, fmt.Errorf("pb: invalid varint")
+			}
+			field.varint = v
+			data = data[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, fmt.Errorf("pb: invalid length")
+			}
+			data = data[n:]
+			if uint64(len(data)) < length {
+				return nil, fmt.Errorf("pb: truncated message")
+			}
+			field.bytes = data[:length]
+			data = data[length:]
+		default:
+			return nil, fmt.Errorf("pb: unsupported wire type %d", field.wireType)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
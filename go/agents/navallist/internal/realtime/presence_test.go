@@ -0,0 +1,121 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"navallist/internal/data"
+)
+
+func TestHandleSubscribe(t *testing.T) {
+	mockStore := &data.MockStore{
+		IsTripCrewFunc: func(_ context.Context, tripID, userID string) (bool, error) {
+			if tripID == "missing" {
+				return false, nil
+			}
+			if userID == "outsider" {
+				return false, nil
+			}
+			return true, nil
+		},
+	}
+
+	service, err := New(mockStore, EngineConfig{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = service.Close(context.Background()) }()
+
+	t.Run("rejects non-trip channel", func(t *testing.T) {
+		if _, err := service.HandleSubscribe(context.Background(), "lobby", ""); err == nil {
+			t.Error("Expected an error for a non-trip channel")
+		}
+	})
+
+	t.Run("rejects unknown trip", func(t *testing.T) {
+		if _, err := service.HandleSubscribe(context.Background(), "trip:missing", ""); err == nil {
+			t.Error("Expected an error for an unknown trip")
+		}
+	})
+
+	t.Run("rejects a non-crew user", func(t *testing.T) {
+		if _, err := service.HandleSubscribe(context.Background(), "trip:trip1", "outsider"); err == nil {
+			t.Error("Expected an error for a non-crew user")
+		}
+	})
+
+	t.Run("allows an anonymous link-joiner", func(t *testing.T) {
+		opts, err := service.HandleSubscribe(context.Background(), "trip:trip1", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !opts.EmitPresence || !opts.EnableRecovery {
+			t.Errorf("Expected presence and recovery enabled, got %+v", opts)
+		}
+	})
+
+	t.Run("allows crew", func(t *testing.T) {
+		if _, err := service.HandleSubscribe(context.Background(), "trip:trip1", "captain"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestParsePresenceRPCTripID(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", data: []byte(`{"trip_id": "trip1"}`), want: "trip1"},
+		{name: "missing trip_id", data: []byte(`{}`), wantErr: true},
+		{name: "invalid json", data: []byte(`not json`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePresenceRPCTripID(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPresenceRPCMethods(t *testing.T) {
+	for _, method := range []string{"focus_item", "typing", "cursor"} {
+		if !presenceRPCMethods[method] {
+			t.Errorf("Expected %q to be a recognized presence RPC method", method)
+		}
+	}
+	if presenceRPCMethods["delete_trip"] {
+		t.Error("Expected an unrelated method name to be rejected")
+	}
+}
+
+func TestPresence_UnknownTrip(t *testing.T) {
+	mockStore := &data.MockStore{}
+	service, err := New(mockStore, EngineConfig{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = service.Close(context.Background()) }()
+
+	entries, err := service.Presence(context.Background(), "trip-with-no-subscribers")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no presence entries, got %v", entries)
+	}
+}
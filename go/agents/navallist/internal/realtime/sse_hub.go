@@ -0,0 +1,143 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sseRingBufferSize bounds how many recent events SSEHub keeps per trip for
+// Last-Event-ID resume; a client that's been disconnected longer than that
+// just has to reload the trip instead of replaying its full history.
+const sseRingBufferSize = 256
+
+// sseSubscriberBuffer is how many events a slow subscriber can fall behind
+// before Publish starts dropping events for it rather than blocking the
+// publisher (see tripTopic.publish).
+const sseSubscriberBuffer = 16
+
+// SSEEvent is one fanned-out update for a trip's /events stream. Seq doubles
+// as the event's SSE id: it's the UnixNano of the event's natural
+// updated_at timestamp (a ChecklistItem or Artifact's UpdatedAt) where one
+// exists, or the publish time otherwise, so a reconnecting client's
+// Last-Event-ID header is itself a meaningful "give me everything newer
+// than this moment" cursor rather than an opaque counter.
+type SSEEvent struct {
+	Seq  int64
+	Type string
+	Data json.RawMessage
+}
+
+// tripTopic is the ring buffer and subscriber set for one trip's events.
+type tripTopic struct {
+	mu   sync.Mutex
+	buf  []SSEEvent
+	subs map[chan SSEEvent]struct{}
+}
+
+func (t *tripTopic) publish(event SSEEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, event)
+	if len(t.buf) > sseRingBufferSize {
+		t.buf = t.buf[len(t.buf)-sseRingBufferSize:]
+	}
+
+	for ch := range t.subs {
+		select {
+		case ch <- event:
+		default:
+			// Slow consumer: drop this event for it rather than blocking
+			// every other subscriber (and the publisher) on one stalled
+			// browser tab. It'll notice the gap next time it resumes with
+			// Last-Event-ID and replay from the ring buffer.
+		}
+	}
+}
+
+func (t *tripTopic) subscribe(sinceSeq int64) (chan SSEEvent, []SSEEvent, func()) {
+	ch := make(chan SSEEvent, sseSubscriberBuffer)
+
+	t.mu.Lock()
+	if t.subs == nil {
+		t.subs = map[chan SSEEvent]struct{}{}
+	}
+	t.subs[ch] = struct{}{}
+
+	var replay []SSEEvent
+	for _, ev := range t.buf {
+		if ev.Seq > sinceSeq {
+			replay = append(replay, ev)
+		}
+	}
+	t.mu.Unlock()
+
+	unsubscribe := func() {
+		t.mu.Lock()
+		delete(t.subs, ch)
+		t.mu.Unlock()
+	}
+
+	return ch, replay, unsubscribe
+}
+
+// SSEHub fans out checklist item updates, artifact uploads, crew
+// presence, and agent tool-call summaries to a trip's connected SSE
+// clients. It's deliberately independent of the Centrifuge-based Service
+// in hub.go: Broker has no generic Subscribe primitive to build an SSE
+// stream on top of, and unlike listener.go's DB-NOTIFY fanout, SSEHub is
+// fed directly by the write paths that call Publish, so it doesn't depend
+// on a Postgres trigger being wired up for the tables involved.
+type SSEHub struct {
+	mu     sync.Mutex
+	topics map[string]*tripTopic
+}
+
+// NewSSEHub creates an empty hub. A single *SSEHub is shared across every
+// trip for the process's lifetime; topics are created lazily per trip ID.
+func NewSSEHub() *SSEHub {
+	return &SSEHub{topics: map[string]*tripTopic{}}
+}
+
+func (h *SSEHub) topic(tripID string) *tripTopic {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[tripID]
+	if !ok {
+		t = &tripTopic{subs: map[chan SSEEvent]struct{}{}}
+		h.topics[tripID] = t
+	}
+	return t
+}
+
+// Publish marshals payload and fans it out to tripID's subscribers, also
+// appending it to the trip's ring buffer so a client reconnecting with
+// Last-Event-ID can replay what it missed. at is the event's natural
+// updated_at timestamp (pass the zero Time if the event has none, e.g. a
+// crew join/leave); Publish falls back to the current time so Seq is
+// always a usable resume cursor.
+func (h *SSEHub) Publish(tripID, eventType string, at time.Time, payload any) error {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("realtime: failed to marshal %s event for trip %s: %w", eventType, tripID, err)
+	}
+
+	h.topic(tripID).publish(SSEEvent{Seq: at.UnixNano(), Type: eventType, Data: data})
+	return nil
+}
+
+// Subscribe registers a new subscriber for tripID and returns a channel of
+// future events, a replay of buffered events with Seq > sinceSeq (for
+// Last-Event-ID resume), and an unsubscribe func the caller must invoke
+// (typically via defer) once the client disconnects, so the topic doesn't
+// keep fanning out to a dead channel.
+func (h *SSEHub) Subscribe(tripID string, sinceSeq int64) (ch chan SSEEvent, replay []SSEEvent, unsubscribe func()) {
+	return h.topic(tripID).subscribe(sinceSeq)
+}
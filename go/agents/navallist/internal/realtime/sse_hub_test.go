@@ -0,0 +1,101 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSSEHub_SubscribeReplaysBufferedEventsSinceSeq(t *testing.T) {
+	hub := NewSSEHub()
+	base := time.Now()
+
+	if err := hub.Publish("trip1", "item.updated", base, map[string]string{"name": "Flares"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := hub.Publish("trip1", "item.updated", base.Add(time.Second), map[string]string{"name": "Anchor"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	_, replay, unsubscribe := hub.Subscribe("trip1", base.UnixNano())
+	defer unsubscribe()
+
+	if len(replay) != 1 {
+		t.Fatalf("expected 1 replayed event newer than the since cursor, got %d", len(replay))
+	}
+	if replay[0].Seq != base.Add(time.Second).UnixNano() {
+		t.Errorf("expected the replayed event to be the later one, got seq %d", replay[0].Seq)
+	}
+}
+
+func TestSSEHub_PublishFansOutToLiveSubscribers(t *testing.T) {
+	hub := NewSSEHub()
+	ch, _, unsubscribe := hub.Subscribe("trip1", 0)
+	defer unsubscribe()
+
+	if err := hub.Publish("trip1", "crew.joined", time.Now(), map[string]string{"name": "Sarah"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case ev := <-ch:
+		if ev.Type != "crew.joined" {
+			t.Errorf("expected crew.joined, got %s", ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscriber to receive the published event")
+	}
+}
+
+func TestSSEHub_PublishDropsEventsForASlowConsumer(t *testing.T) {
+	hub := NewSSEHub()
+	ch, _, unsubscribe := hub.Subscribe("trip1", 0)
+	defer unsubscribe()
+
+	// Fill the subscriber's buffer past capacity without ever reading from
+	// ch, simulating a stalled browser tab; Publish must not block.
+	for i := 0; i < sseSubscriberBuffer+5; i++ {
+		if err := hub.Publish("trip1", "item.updated", time.Now(), map[string]int{"i": i}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	if len(ch) != sseSubscriberBuffer {
+		t.Fatalf("expected the channel to be full at its buffer size, got %d", len(ch))
+	}
+}
+
+func TestSSEHub_RingBufferTrimsToMaxSize(t *testing.T) {
+	hub := NewSSEHub()
+	base := time.Now()
+
+	for i := 0; i < sseRingBufferSize+10; i++ {
+		if err := hub.Publish("trip1", "item.updated", base.Add(time.Duration(i)*time.Millisecond), nil); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	_, replay, unsubscribe := hub.Subscribe("trip1", 0)
+	defer unsubscribe()
+
+	if len(replay) != sseRingBufferSize {
+		t.Fatalf("expected the ring buffer to cap replay at %d events, got %d", sseRingBufferSize, len(replay))
+	}
+}
+
+func TestSSEHub_UnsubscribeStopsFanout(t *testing.T) {
+	hub := NewSSEHub()
+	ch, _, unsubscribe := hub.Subscribe("trip1", 0)
+	unsubscribe()
+
+	if err := hub.Publish("trip1", "item.updated", time.Now(), nil); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected no event to be delivered after unsubscribe")
+		}
+	default:
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"navallist/internal/data"
+
+	"github.com/charmbracelet/log"
+)
+
+// AdminHandler handles operator-facing maintenance endpoints.
+type AdminHandler struct {
+	Store   data.Store
+	Storage data.BlobStorage
+}
+
+// NewAdminHandler initializes a new AdminHandler with the given dependencies.
+func NewAdminHandler(store data.Store, storage data.BlobStorage) *AdminHandler {
+	return &AdminHandler{Store: store, Storage: storage}
+}
+
+// ExportTrip - GET /api/admin/trips/{id}/export
+// Streams a tar+gzip snapshot of the trip (row, checklist items, and
+// artifact blobs) for backup or migration to another deployment.
+func (h *AdminHandler) ExportTrip(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	blob, err := data.SnapshotTrip(r.Context(), h.Store, h.Storage, id)
+	if err != nil {
+		log.Error("Failed to snapshot trip", "err", err, "tripID", id)
+		http.Error(w, "Failed to export trip: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"trip_"+id+".tar.gz\"")
+	_, _ = w.Write(blob)
+}
+
+// ImportTrip - POST /api/admin/trips/import
+// Restores a snapshot produced by ExportTrip into a brand-new trip.
+func (h *AdminHandler) ImportTrip(w http.ResponseWriter, r *http.Request) {
+	blob, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := data.RestoreTrip(r.Context(), h.Store, h.Storage, blob); err != nil {
+		log.Error("Failed to restore trip", "err", err)
+		http.Error(w, "Failed to import trip: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
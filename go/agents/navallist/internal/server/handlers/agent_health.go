@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"navallist/internal/agent"
+)
+
+// AgentHealth - GET /healthz/agent. Reports the agent client's circuit
+// breaker state and the last few overload/rate-limit errors it's seen, for
+// an operator watching a Gemini outage or a load balancer deciding whether
+// to stop routing traffic to this instance's agent endpoints.
+func (h *TripsHandler) AgentHealth(w http.ResponseWriter, r *http.Request) {
+	if h.AgentClient == nil {
+		http.Error(w, "Agent is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	reporter, ok := h.AgentClient.(agent.AgentHealthReporter)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"breaker_state": "unknown"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reporter.HealthSnapshot())
+}
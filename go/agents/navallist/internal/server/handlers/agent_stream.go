@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"google.golang.org/adk/session"
+)
+
+// streamHeartbeatInterval is how often StreamAgentEvents writes a comment
+// line to keep intermediary proxies/load balancers from treating an
+// in-progress agent run as an idle connection and closing it.
+const streamHeartbeatInterval = 15 * time.Second
+
+// eventOrErr carries one item off an agent.Client.StreamInteraction
+// iterator onto a channel, so it can be select'd against the heartbeat
+// ticker and ctx.Done() instead of blocking the handler goroutine outright.
+type eventOrErr struct {
+	event *session.Event
+	err   error
+}
+
+// StreamAgentEvents proxies a checklist agent run to the browser as
+// Server-Sent Events (GET /api/trips/{id}/agent/stream?userId=...&message=...).
+// Each event is persisted to the agent_run/agent_run_event tables as it's
+// emitted, so a dropped connection can resume with a Last-Event-ID header
+// and the same run_id query param instead of re-running the agent, and so
+// the run can be replayed later for debugging. Cancelling r.Context() (the
+// browser tab closing) tears down the underlying agent.Client call too.
+func (h *TripsHandler) StreamAgentEvents(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.AgentClient == nil {
+		http.Error(w, "Agent is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID := r.URL.Query().Get("userId")
+	if userID == "" {
+		http.Error(w, "Missing userId", http.StatusBadRequest)
+		return
+	}
+
+	if !h.streamLimiter().Allow(userID + ":" + tripID) {
+		http.Error(w, "Too many agent stream requests for this trip, try again shortly", http.StatusTooManyRequests)
+		return
+	}
+
+	trip, err := h.Store.GetTrip(r.Context(), tripID)
+	if err != nil {
+		http.Error(w, "Trip not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	runID := r.URL.Query().Get("run_id")
+	var lastSeq int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		lastSeq, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if runID != "" {
+		replayed, err := h.Store.GetAgentRunEventsSince(ctx, runID, lastSeq)
+		if err != nil {
+			log.Error("Failed to replay agent run events", "run_id", runID, "error", err)
+		}
+		for _, e := range replayed {
+			writeSSEEvent(w, flusher, e.Seq, classifyEventType(e.Payload), e.Payload)
+			lastSeq = e.Seq
+		}
+	}
+
+	message := r.URL.Query().Get("message")
+	if message == "" {
+		// A resume-only reconnect with nothing new to run: the client is
+		// now caught up on everything that was persisted.
+		writeSSEComment(w, flusher, "caught up")
+		return
+	}
+
+	if runID == "" {
+		run, err := h.Store.CreateAgentRun(ctx, tripID, userID, trip.ADKSessionID, "local")
+		if err != nil {
+			log.Error("Failed to create agent run", "trip_id", tripID, "error", err)
+			writeSSEEvent(w, flusher, lastSeq+1, "error", jsonMessage(err.Error()))
+			return
+		}
+		runID = run.ID
+		writeSSEEvent(w, flusher, 0, "run_started", jsonMessage(runID))
+	}
+
+	payload := map[string]any{
+		"app_name":   "navallist_agent",
+		"user_id":    userID,
+		"session_id": trip.ADKSessionID,
+		"new_message": map[string]any{
+			"role":  "user",
+			"parts": []map[string]any{{"text": message}},
+		},
+	}
+
+	events, err := h.AgentClient.StreamInteraction(ctx, payload)
+	if err != nil {
+		log.Error("Failed to start agent stream", "trip_id", tripID, "run_id", runID, "error", err)
+		writeSSEEvent(w, flusher, lastSeq+1, "error", jsonMessage(err.Error()))
+		h.finishAgentRun(runID, err)
+		return
+	}
+
+	eventCh := make(chan eventOrErr)
+	go func() {
+		defer close(eventCh)
+		for ev, err := range events {
+			select {
+			case eventCh <- eventOrErr{ev, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var runErr error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			runErr = ctx.Err()
+			break loop
+		case item, ok := <-eventCh:
+			if !ok {
+				break loop
+			}
+			if item.err != nil {
+				runErr = item.err
+				lastSeq++
+				writeSSEEvent(w, flusher, lastSeq, "error", jsonMessage(item.err.Error()))
+				_ = h.Store.AppendAgentRunEvent(ctx, runID, lastSeq, jsonMessage(item.err.Error()))
+				break loop
+			}
+			payloadBytes, err := json.Marshal(item.event)
+			if err != nil {
+				log.Error("Failed to marshal agent event", "run_id", runID, "error", err)
+				continue
+			}
+			lastSeq++
+			if err := h.Store.AppendAgentRunEvent(ctx, runID, lastSeq, payloadBytes); err != nil {
+				log.Error("Failed to persist agent run event", "run_id", runID, "error", err)
+			}
+			writeSSEEvent(w, flusher, lastSeq, classifyEventType(payloadBytes), payloadBytes)
+		case <-heartbeat.C:
+			writeSSEComment(w, flusher, "heartbeat")
+		}
+	}
+
+	h.finishAgentRun(runID, runErr)
+}
+
+// finishAgentRun records runID's outcome using a context detached from the
+// request's, since by the time it's called r.Context() may already be
+// cancelled (the client disconnected or the stream just ended).
+func (h *TripsHandler) finishAgentRun(runID string, runErr error) {
+	if err := h.Store.FinishAgentRun(context.WithoutCancel(context.Background()), runID, runErr); err != nil {
+		log.Error("Failed to finalize agent run", "run_id", runID, "error", err)
+	}
+}
+
+// writeSSEEvent writes one "id/event/data" SSE frame and flushes it
+// immediately so the browser sees it without buffering delay.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, id int64, event string, data []byte) {
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data)
+	flusher.Flush()
+}
+
+// writeSSEComment writes an SSE comment line (ignored by EventSource
+// listeners but enough to keep idle-timeout proxies from closing the
+// connection).
+func writeSSEComment(w http.ResponseWriter, flusher http.Flusher, comment string) {
+	_, _ = fmt.Fprintf(w, ": %s\n\n", comment)
+	flusher.Flush()
+}
+
+func jsonMessage(msg string) []byte {
+	b, err := json.Marshal(map[string]string{"message": msg})
+	if err != nil {
+		return []byte(`{"message":"unknown error"}`)
+	}
+	return b
+}
+
+// classifyEventType sniffs a marshaled session.Event's JSON for the SSE/WS
+// event name it should be sent under, so a browser can tell a streaming
+// text token apart from a tool call or an emitted artifact without
+// re-parsing the full ADK event shape on every frame. It works off the
+// JSON rather than session.Event's Go fields directly so it keeps working
+// unchanged across ADK event shape tweaks, matching the rest of this
+// package's event handling, which already treats session.Event as an
+// opaque, marshal-as-is payload.
+func classifyEventType(payload []byte) string {
+	var ev struct {
+		Content *struct {
+			Parts []struct {
+				FunctionCall     json.RawMessage `json:"function_call"`
+				FunctionResponse json.RawMessage `json:"function_response"`
+				InlineData       json.RawMessage `json:"inline_data"`
+			} `json:"parts"`
+		} `json:"content"`
+		TurnComplete bool   `json:"turn_complete"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return "agent_event"
+	}
+
+	if ev.ErrorMessage != "" {
+		return "error"
+	}
+	if ev.Content != nil {
+		for _, p := range ev.Content.Parts {
+			if len(p.FunctionCall) > 0 || len(p.FunctionResponse) > 0 {
+				return "tool_call"
+			}
+			if len(p.InlineData) > 0 {
+				return "artifact"
+			}
+		}
+	}
+	if ev.TurnComplete {
+		return "done"
+	}
+	return "text_delta"
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"iter"
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+	"navallist/internal/ratelimit"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/session"
+)
+
+// fakeAgentClient is an agent.Client test double whose StreamInteraction is
+// driven by a closure, so tests can control exactly which events/errors a
+// run produces without a live ADK runner.
+type fakeAgentClient struct {
+	streamFunc func(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error)
+}
+
+func (f *fakeAgentClient) CreateSession(context.Context, string, string, string) error { return nil }
+
+func (f *fakeAgentClient) GetSession(context.Context, string, string, string) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeAgentClient) RunInteraction(context.Context, interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeAgentClient) StreamInteraction(ctx context.Context, payload interface{}) (iter.Seq2[*session.Event, error], error) {
+	return f.streamFunc(ctx, payload)
+}
+
+func newStreamTestStore(trip *models.Trip) *data.MockStore {
+	return &data.MockStore{
+		GetTripFunc: func(_ context.Context, _ string) (*models.Trip, error) {
+			return trip, nil
+		},
+		CreateAgentRunFunc: func(_ context.Context, tripID, userID, sessionID, transport string) (*models.AgentRun, error) {
+			return &models.AgentRun{ID: "run_1", TripID: tripID, UserID: userID, SessionID: sessionID, Transport: transport}, nil
+		},
+		AppendAgentRunEventFunc: func(_ context.Context, _ string, _ int64, _ []byte) error { return nil },
+		FinishAgentRunFunc:      func(_ context.Context, _ string, _ error) error { return nil },
+		GetAgentRunEventsSinceFunc: func(_ context.Context, _ string, _ int64) ([]models.AgentRunEvent, error) {
+			return nil, nil
+		},
+	}
+}
+
+func TestTripsHandler_StreamAgentEvents_MissingUserID(t *testing.T) {
+	h := NewTripsHandler(newStreamTestStore(&models.Trip{ID: "trip1"}), &fakeAgentClient{}, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/agent/stream?message=hi", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.StreamAgentEvents(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("Expected 400 for missing userId, got %d", w.Code)
+	}
+}
+
+func TestTripsHandler_StreamAgentEvents_ForwardsEventsAsSSE(t *testing.T) {
+	client := &fakeAgentClient{
+		streamFunc: func(_ context.Context, _ interface{}) (iter.Seq2[*session.Event, error], error) {
+			return func(yield func(*session.Event, error) bool) {
+				if !yield(&session.Event{}, nil) {
+					return
+				}
+				yield(&session.Event{}, nil)
+			}, nil
+		},
+	}
+	h := NewTripsHandler(newStreamTestStore(&models.Trip{ID: "trip1", ADKSessionID: "sess1"}), client, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/agent/stream?userId=user1&message=hi", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.StreamAgentEvents(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "event: run_started") {
+		t.Errorf("Expected a run_started event, got body: %s", body)
+	}
+	if strings.Count(body, "event: text_delta") != 2 {
+		t.Errorf("Expected 2 text_delta frames, got body: %s", body)
+	}
+	if !strings.Contains(w.Header().Get("Content-Type"), "text/event-stream") {
+		t.Errorf("Expected text/event-stream content type, got %q", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestTripsHandler_StreamAgentEvents_RateLimited(t *testing.T) {
+	client := &fakeAgentClient{
+		streamFunc: func(_ context.Context, _ interface{}) (iter.Seq2[*session.Event, error], error) {
+			return func(func(*session.Event, error) bool) {}, nil
+		},
+	}
+	h := NewTripsHandler(newStreamTestStore(&models.Trip{ID: "trip1"}), client, data.NewDiskStorage(t.TempDir()), nil, nil)
+	h.StreamLimiter = ratelimit.NewLimiter(0, 1)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/api/trips/trip1/agent/stream?userId=user1&message=hi", nil)
+		req.SetPathValue("id", "trip1")
+		w := httptest.NewRecorder()
+		h.StreamAgentEvents(w, req)
+
+		if i == 0 && w.Code != 200 {
+			t.Fatalf("Expected the first call within burst to succeed, got %d", w.Code)
+		}
+		if i == 1 && w.Code != 429 {
+			t.Fatalf("Expected the second call to be rate limited, got %d", w.Code)
+		}
+	}
+}
+
+func TestClassifyEventType(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"plain text", `{"content":{"parts":[{"text":"hi"}]}}`, "text_delta"},
+		{"function call", `{"content":{"parts":[{"function_call":{"name":"get_crew_list"}}]}}`, "tool_call"},
+		{"function response", `{"content":{"parts":[{"function_response":{"name":"get_crew_list"}}]}}`, "tool_call"},
+		{"inline data", `{"content":{"parts":[{"inline_data":{"mime_type":"image/jpeg","data":"AA=="}}]}}`, "artifact"},
+		{"error", `{"error_message":"boom"}`, "error"},
+		{"turn complete", `{"turn_complete":true}`, "done"},
+		{"empty event", `{}`, "text_delta"},
+		{"invalid json", `not json`, "agent_event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyEventType([]byte(tt.payload)); got != tt.want {
+				t.Errorf("classifyEventType(%s) = %q, want %q", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/gorilla/websocket"
+)
+
+// agentStreamUpgrader upgrades GET /api/trips/{id}/agent/ws to a
+// WebSocket. Origin checking is left to the browser/same-origin defaults,
+// matching how /connection/websocket's CheckOrigin is handled today.
+var agentStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+// wsAgentFrame is one event sent down the WebSocket to the browser: Type is
+// the same classification StreamAgentEvents' SSE uses (see
+// classifyEventType), Seq lets the client order/dedupe frames, and Data
+// carries the underlying session.Event (or, for "error", a
+// {"message":...} object).
+type wsAgentFrame struct {
+	Type string          `json:"type"`
+	Seq  int64           `json:"seq"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// StreamAgentEventsWS is StreamAgentEvents' WebSocket counterpart (GET
+// /api/trips/{id}/agent/ws?userId=...&message=...). It offers the same
+// event stream but over a connection the browser can also write to, so it
+// can send {"type":"cancel"} to abort the run before it finishes - a
+// "stop generating" button has no way to express that back to the server
+// over a one-way SSE connection.
+func (h *TripsHandler) StreamAgentEventsWS(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+	if h.AgentClient == nil {
+		http.Error(w, "Agent is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID := r.URL.Query().Get("userId")
+	message := r.URL.Query().Get("message")
+	if userID == "" || message == "" {
+		http.Error(w, "Missing userId or message", http.StatusBadRequest)
+		return
+	}
+
+	if !h.streamLimiter().Allow(userID + ":" + tripID) {
+		http.Error(w, "Too many agent stream requests for this trip, try again shortly", http.StatusTooManyRequests)
+		return
+	}
+
+	trip, err := h.Store.GetTrip(r.Context(), tripID)
+	if err != nil {
+		http.Error(w, "Trip not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := agentStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error("Failed to upgrade agent stream to WebSocket", "trip_id", tripID, "error", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// The only thing an incoming frame on this connection is used for: a
+	// {"type":"cancel"} aborts the run, and the connection closing does the
+	// same via ReadJSON's error.
+	go func() {
+		for {
+			var frame struct {
+				Type string `json:"type"`
+			}
+			if err := conn.ReadJSON(&frame); err != nil {
+				cancel()
+				return
+			}
+			if frame.Type == "cancel" {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	run, err := h.Store.CreateAgentRun(ctx, tripID, userID, trip.ADKSessionID, "websocket")
+	if err != nil {
+		log.Error("Failed to create agent run", "trip_id", tripID, "error", err)
+		_ = conn.WriteJSON(wsAgentFrame{Type: "error", Data: jsonMessage(err.Error())})
+		return
+	}
+
+	payload := map[string]any{
+		"app_name":   "navallist_agent",
+		"user_id":    userID,
+		"session_id": trip.ADKSessionID,
+		"new_message": map[string]any{
+			"role":  "user",
+			"parts": []map[string]any{{"text": message}},
+		},
+	}
+
+	events, err := h.AgentClient.StreamInteraction(ctx, payload)
+	if err != nil {
+		log.Error("Failed to start agent stream", "trip_id", tripID, "run_id", run.ID, "error", err)
+		_ = conn.WriteJSON(wsAgentFrame{Type: "error", Data: jsonMessage(err.Error())})
+		h.finishAgentRun(run.ID, err)
+		return
+	}
+
+	var seq int64
+	var runErr error
+	for event, evErr := range events {
+		if evErr != nil {
+			runErr = evErr
+			break
+		}
+		payloadBytes, err := json.Marshal(event)
+		if err != nil {
+			log.Error("Failed to marshal agent event", "run_id", run.ID, "error", err)
+			continue
+		}
+		seq++
+		if err := h.Store.AppendAgentRunEvent(ctx, run.ID, seq, payloadBytes); err != nil {
+			log.Error("Failed to persist agent run event", "run_id", run.ID, "error", err)
+		}
+		if err := conn.WriteJSON(wsAgentFrame{Type: classifyEventType(payloadBytes), Seq: seq, Data: payloadBytes}); err != nil {
+			runErr = ctx.Err()
+			break
+		}
+	}
+
+	seq++
+	if runErr != nil {
+		_ = conn.WriteJSON(wsAgentFrame{Type: "error", Seq: seq, Data: jsonMessage(runErr.Error())})
+	} else {
+		_ = conn.WriteJSON(wsAgentFrame{Type: "done", Seq: seq})
+	}
+	h.finishAgentRun(run.ID, runErr)
+}
@@ -1,35 +1,308 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"navallist/internal/auth"
 	"navallist/internal/data"
+	"navallist/internal/mailer"
+
+	"github.com/charmbracelet/log"
+)
+
+// oauthStateCookie and oauthVerifierCookie hold the CSRF state and PKCE
+// verifier between Login and Callback; they're short-lived and cleared once
+// Callback consumes them.
+const (
+	oauthStateCookie    = "oauth_state"
+	oauthVerifierCookie = "oauth_verifier"
+	refreshTokenCookie  = "refresh_token"
+
+	oauthFlowCookieTTL = 10 * time.Minute
+
+	// connectTokenTTL is how long a Centrifuge connect token stays valid;
+	// short-lived since it's only needed to complete the handshake.
+	connectTokenTTL = 1 * time.Minute
 )
 
-// AuthHandler handles authentication flows (simplified for local sample).
+// AuthHandler handles authentication flows: provider-redirect login,
+// email-code login, and the session/refresh token lifecycle that follows
+// either one.
 type AuthHandler struct {
-	Store data.Store
+	Store  data.Store
+	Mailer mailer.Mailer
+	// Provider is nil-able: a deployment can run with email-code login only.
+	Provider auth.Provider
+	// PasswordProvider is nil-able: set only for directory-style deployments
+	// (LDAP, Keystone), mutually exclusive with Provider since both are
+	// selected by the same NAVALLIST_AUTH_KIND value.
+	PasswordProvider auth.PasswordProvider
+
+	SessionSecret    []byte
+	VerificationTTL  time.Duration
+	SessionCookieTTL time.Duration
+	RefreshTokenTTL  time.Duration
 }
 
 // NewAuthHandler initializes a new AuthHandler with the given store.
-func NewAuthHandler(store data.Store) *AuthHandler {
+func NewAuthHandler(store data.Store, m mailer.Mailer, provider auth.Provider, passwordProvider auth.PasswordProvider, sessionSecret []byte, verificationTTL, sessionCookieTTL, refreshTokenTTL time.Duration) *AuthHandler {
 	return &AuthHandler{
-		Store: store,
+		Store:            store,
+		Mailer:           m,
+		Provider:         provider,
+		PasswordProvider: passwordProvider,
+		SessionSecret:    sessionSecret,
+		VerificationTTL:  verificationTTL,
+		SessionCookieTTL: sessionCookieTTL,
+		RefreshTokenTTL:  refreshTokenTTL,
 	}
 }
 
-// Logout clears the user session.
+// Logout revokes the refresh token cookie server-side (so it can't be
+// replayed against POST /auth/refresh by whoever captured it) and clears
+// the session/refresh cookies.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(refreshTokenCookie); err == nil && cookie.Value != "" {
+		if _, err := h.Store.ConsumeRefreshToken(r.Context(), cookie.Value); err != nil && !errors.Is(err, data.ErrRefreshTokenInvalid) {
+			log.Error("Failed to revoke refresh token on logout", "error", err)
+		}
+	}
+
 	http.SetCookie(w, &http.Cookie{
 		Name:   "user_session",
 		Value:  "",
 		Path:   "/",
 		MaxAge: -1,
 	})
+	http.SetCookie(w, &http.Cookie{
+		Name:   refreshTokenCookie,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
 	http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 }
 
+// Login starts the provider redirect flow: a CSRF state and PKCE verifier
+// are generated, stashed in short-lived cookies, and the user is sent to
+// the provider's authorization URL.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if h.Provider == nil {
+		http.Error(w, "No login provider configured", http.StatusNotImplemented)
+		return
+	}
+
+	state, err := auth.GenerateState()
+	if err != nil {
+		log.Error("Failed to generate oauth state", "error", err)
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	verifier, err := auth.GenerateCodeVerifier()
+	if err != nil {
+		log.Error("Failed to generate PKCE verifier", "error", err)
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Value: state, Path: "/", HttpOnly: true, MaxAge: int(oauthFlowCookieTTL.Seconds())})
+	http.SetCookie(w, &http.Cookie{Name: oauthVerifierCookie, Value: verifier, Path: "/", HttpOnly: true, MaxAge: int(oauthFlowCookieTTL.Seconds())})
+
+	challenge := auth.CodeChallengeS256(verifier)
+	http.Redirect(w, r, h.Provider.AuthorizationURL(state, challenge), http.StatusFound)
+}
+
+// Callback completes the provider redirect flow: the CSRF state is checked
+// against its cookie, the authorization code is exchanged for an Identity,
+// and a user is resolved by email before issuing a session cookie and
+// refresh token.
+func (h *AuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if h.Provider == nil {
+		http.Error(w, "No login provider configured", http.StatusNotImplemented)
+		return
+	}
+
+	stateCookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || stateCookie.Value == "" || stateCookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "Invalid or expired login state", http.StatusBadRequest)
+		return
+	}
+	verifierCookie, err := r.Cookie(oauthVerifierCookie)
+	if err != nil || verifierCookie.Value == "" {
+		http.Error(w, "Invalid or expired login state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Value: "", Path: "/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{Name: oauthVerifierCookie, Value: "", Path: "/", MaxAge: -1})
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		return
+	}
+
+	identity, err := h.Provider.Exchange(r.Context(), code, verifierCookie.Value)
+	if err != nil {
+		log.Error("Failed to exchange authorization code", "error", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	if identity.Email == "" {
+		http.Error(w, "Login provider did not return an email address", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.Store.GetOrCreateUserByEmail(r.Context(), strings.ToLower(identity.Email))
+	if err != nil {
+		log.Error("Failed to get or create user", "error", err)
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.issueSession(w, r, user.ID); err != nil {
+		log.Error("Failed to issue session", "error", err)
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// LoginPassword authenticates a username/password pair directly against the
+// configured PasswordProvider (LDAP bind, Keystone password auth) - no
+// redirect or PKCE involved, unlike Login/Callback.
+func (h *AuthHandler) LoginPassword(w http.ResponseWriter, r *http.Request) {
+	if h.PasswordProvider == nil {
+		http.Error(w, "No password login provider configured", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Username and password are required", http.StatusBadRequest)
+		return
+	}
+
+	identity, err := h.PasswordProvider.Authenticate(r.Context(), req.Username, req.Password)
+	if err != nil {
+		log.Error("Password authentication failed", "error", err, "username", req.Username)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	// A directory bind doesn't always resolve an email address (e.g. a plain
+	// LDAP bind - see LDAPProvider.Authenticate); fall back to Subject (the
+	// bind DN, or the Keystone user ID) as the user's stable key.
+	key := identity.Email
+	if key == "" {
+		key = identity.Subject
+	}
+
+	user, err := h.Store.GetOrCreateUserByEmail(r.Context(), strings.ToLower(key))
+	if err != nil {
+		log.Error("Failed to get or create user", "error", err)
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.issueSession(w, r, user.ID); err != nil {
+		log.Error("Failed to issue session", "error", err)
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(user)
+}
+
+// Refresh exchanges a valid refresh token cookie for a new session cookie
+// (and a new refresh token, rotating the old one out).
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(refreshTokenCookie)
+	if err != nil || cookie.Value == "" {
+		http.Error(w, "No refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := h.Store.ConsumeRefreshToken(r.Context(), cookie.Value)
+	if err != nil {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.issueSession(w, r, userID); err != nil {
+		log.Error("Failed to issue session", "error", err)
+		http.Error(w, "Failed to refresh session", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// IssueConnectToken mints a short-lived, signed Centrifuge connect token for
+// the authenticated caller, passed by the frontend as a "token" query param
+// on the /connection/websocket upgrade (see Server.ConnectTokenMiddleware).
+func (h *AuthHandler) IssueConnectToken(w http.ResponseWriter, r *http.Request) {
+	userID := data.GetUserID(r.Context())
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := auth.SignToken(h.SessionSecret, userID, connectTokenTTL)
+	if err != nil {
+		log.Error("Failed to sign connect token", "error", err)
+		http.Error(w, "Failed to issue connect token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+// issueSession sets a signed session cookie for userID and persists a fresh
+// refresh token cookie alongside it.
+func (h *AuthHandler) issueSession(w http.ResponseWriter, r *http.Request, userID string) error {
+	sessionToken, err := auth.SignToken(h.SessionSecret, userID, h.SessionCookieTTL)
+	if err != nil {
+		return fmt.Errorf("failed to sign session token: %w", err)
+	}
+	http.SetCookie(w, &http.Cookie{Name: "user_session", Value: sessionToken, Path: "/", HttpOnly: true, MaxAge: int(h.SessionCookieTTL.Seconds())})
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if err := h.Store.SaveRefreshToken(r.Context(), refreshToken, userID, h.RefreshTokenTTL); err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	http.SetCookie(w, &http.Cookie{Name: refreshTokenCookie, Value: refreshToken, Path: "/", HttpOnly: true, MaxAge: int(h.RefreshTokenTTL.Seconds())})
+
+	return nil
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // GetMe returns the currently logged in user (if any).
 func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	userID := data.GetUserID(r.Context())
@@ -76,3 +349,108 @@ func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) DevLogin(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 }
+
+// StartEmailVerification generates a 6-digit code for the given email,
+// stores it with a TTL/attempt counter, and dispatches it via Mailer.
+func (h *AuthHandler) StartEmailVerification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if email == "" {
+		http.Error(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	code, err := data.GenerateVerificationCode()
+	if err != nil {
+		log.Error("Failed to generate verification code", "error", err)
+		http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Store.CreateEmailVerification(r.Context(), email, code, h.VerificationTTL); err != nil {
+		log.Error("Failed to store verification code", "error", err)
+		http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+		return
+	}
+
+	body := fmt.Sprintf("Your NavalList verification code is %s. It expires in %s.", code, h.VerificationTTL)
+	if err := h.Mailer.Send(r.Context(), email, "Your NavalList verification code", body); err != nil {
+		log.Error("Failed to send verification email", "error", err)
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// VerifyEmail consumes a verification code, creates or finds the user, and
+// issues the session cookie. If a guest_name is supplied, any checklist
+// contributions previously attributed to that guest on trips the user now
+// has access to are claimed under the verified user's ID.
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email     string `json:"email"`
+		Code      string `json:"code"`
+		GuestName string `json:"guest_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if email == "" || req.Code == "" {
+		http.Error(w, "Email and code are required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Store.ConsumeEmailVerification(r.Context(), email, req.Code)
+	switch {
+	case errors.Is(err, data.ErrInvalidCode):
+		http.Error(w, "Invalid code", http.StatusUnauthorized)
+		return
+	case errors.Is(err, data.ErrCodeExpired):
+		http.Error(w, "Code expired", http.StatusUnauthorized)
+		return
+	case errors.Is(err, data.ErrTooManyAttempts):
+		http.Error(w, "Too many attempts, request a new code", http.StatusTooManyRequests)
+		return
+	case err != nil:
+		log.Error("Failed to verify email code", "error", err)
+		http.Error(w, "Failed to verify code", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.Store.GetOrCreateUserByEmail(r.Context(), email)
+	if err != nil {
+		log.Error("Failed to get or create user", "error", err)
+		http.Error(w, "Failed to verify code", http.StatusInternalServerError)
+		return
+	}
+
+	guestName := strings.TrimSpace(strings.TrimPrefix(req.GuestName, "guest_"))
+	if guestName != "" {
+		claimed, err := h.Store.ClaimGuestContributions(r.Context(), guestName, user.ID)
+		if err != nil {
+			log.Error("Failed to claim guest contributions", "error", err, "guestName", guestName, "userID", user.ID)
+		} else {
+			log.Info("Claimed guest contributions", "guestName", guestName, "userID", user.ID, "itemsClaimed", claimed)
+		}
+	}
+
+	if err := h.issueSession(w, r, user.ID); err != nil {
+		log.Error("Failed to issue session", "error", err)
+		http.Error(w, "Failed to verify code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(user)
+}
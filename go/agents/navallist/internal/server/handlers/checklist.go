@@ -1,24 +1,27 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"navallist/internal/agent"
 	"navallist/internal/data"
+	ctxlog "navallist/internal/log"
 
 	"github.com/charmbracelet/log"
 )
 
 type ChecklistHandler struct {
-	Client    *agent.LocalAgentClient
+	Client    agent.Client
 	Store     data.Store
 	AgentName string
 }
 
-func NewChecklistHandler(client *agent.LocalAgentClient, store data.Store) *ChecklistHandler {
+func NewChecklistHandler(client agent.Client, store data.Store) *ChecklistHandler {
 	return &ChecklistHandler{
 		Client:    client,
 		Store:     store,
@@ -104,22 +107,29 @@ func (h *ChecklistHandler) RunInteraction(w http.ResponseWriter, r *http.Request
 		userID, _ = payload["userId"].(string)
 	}
 
-	resp, err := h.Client.RunInteraction(r.Context(), payload)
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.streamRunInteraction(w, r, payload, sessionID, userID)
+		return
+	}
+
+	ctx := ctxlog.WithFields(r.Context(), "adk_session_id", sessionID)
+
+	resp, err := h.Client.RunInteraction(ctx, payload)
 	if errors.Is(err, data.ErrNotFound) {
 		// Self-healing: Try to create session and retry
 		if sessionID != "" && userID != "" {
 			// Try to create the session
-			if createErr := h.Client.CreateSession(r.Context(), h.AgentName, userID, sessionID); createErr == nil {
+			if createErr := h.Client.CreateSession(ctx, h.AgentName, userID, sessionID); createErr == nil {
 				// Retry Run if creation succeeded
-				resp, err = h.Client.RunInteraction(r.Context(), payload)
+				resp, err = h.Client.RunInteraction(ctx, payload)
 			} else {
-				log.Error("Self-healing session creation failed", "err", createErr, "session", sessionID)
+				ctxlog.FromContext(ctx).Error("Self-healing session creation failed", "err", createErr, "session", sessionID)
 			}
 		}
 	}
 
 	if err != nil {
-		log.Error("Agent interaction failed", "err", err, "session", sessionID)
+		ctxlog.FromContext(ctx).Error("Agent interaction failed", "err", err, "session", sessionID)
 		
 		w.Header().Set("Content-Type", "application/json")
 		
@@ -129,10 +139,12 @@ func (h *ChecklistHandler) RunInteraction(w http.ResponseWriter, r *http.Request
 		if errors.Is(err, data.ErrNotFound) {
 			status = http.StatusNotFound
 			code = "session_not_found"
-		} else if strings.Contains(strings.ToLower(err.Error()), "overloaded") || 
-		           strings.Contains(strings.ToLower(err.Error()), "rate limit") {
+		} else if retryAfter, ok := agent.AsRetryAfter(err); ok {
 			status = http.StatusTooManyRequests
 			code = "overloaded"
+			if secs := retryAfter.RetryAfterSeconds(); secs > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(secs))
+			}
 		}
 
 		w.WriteHeader(status)
@@ -147,3 +159,99 @@ func (h *ChecklistHandler) RunInteraction(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+// streamRunInteraction is RunInteraction's SSE variant, used when the
+// caller sends Accept: text/event-stream. It interleaves "progress" frames
+// emitted by tools iterating a bulk update (see ChecklistTool.UpdateItems)
+// with the run's own per-event-type frames (see classifyEventType), so a
+// bulk update shows real-time per-item check-offs instead of a single
+// blocking reply. Built on StreamInteraction + a context-injected
+// agent.ProgressEmitter rather than a dedicated Client method, so it works
+// unchanged for any Client whose in-process tools observe the emitter we
+// attach to ctx.
+func (h *ChecklistHandler) streamRunInteraction(w http.ResponseWriter, r *http.Request, payload map[string]interface{}, sessionID, userID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := ctxlog.WithFields(r.Context(), "adk_session_id", sessionID)
+	progressCh := make(chan agent.ProgressFrame)
+	runCtx := agent.WithProgressEmitter(ctx, &sseProgressEmitter{ch: progressCh, ctx: ctx})
+
+	events, err := h.Client.StreamInteraction(runCtx, payload)
+	if errors.Is(err, data.ErrNotFound) && sessionID != "" && userID != "" {
+		if createErr := h.Client.CreateSession(ctx, h.AgentName, userID, sessionID); createErr == nil {
+			events, err = h.Client.StreamInteraction(runCtx, payload)
+		} else {
+			ctxlog.FromContext(ctx).Error("Self-healing session creation failed", "err", createErr, "session", sessionID)
+		}
+	}
+	if err != nil {
+		ctxlog.FromContext(ctx).Error("Agent interaction failed", "err", err, "session", sessionID)
+		writeSSEEvent(w, flusher, 0, "error", jsonMessage(err.Error()))
+		return
+	}
+
+	var seq int64
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for frame := range progressCh {
+			b, err := json.Marshal(frame)
+			if err != nil {
+				continue
+			}
+			seq++
+			writeSSEEvent(w, flusher, seq, "progress", b)
+		}
+	}()
+
+	var runErr error
+	for event, evErr := range events {
+		if evErr != nil {
+			runErr = evErr
+			break
+		}
+		b, err := json.Marshal(event)
+		if err != nil {
+			log.Error("Failed to marshal agent event", "error", err)
+			continue
+		}
+		seq++
+		writeSSEEvent(w, flusher, seq, classifyEventType(b), b)
+	}
+
+	close(progressCh)
+	<-progressDone
+
+	seq++
+	if runErr != nil {
+		writeSSEEvent(w, flusher, seq, "error", jsonMessage(runErr.Error()))
+		return
+	}
+	writeSSEEvent(w, flusher, seq, "result", jsonMessage("done"))
+}
+
+// sseProgressEmitter forwards ProgressFrame updates onto a channel so
+// streamRunInteraction can relay them as SSE frames alongside the run's
+// events. EmitProgress gives up once ctx is done rather than blocking
+// forever on a reader that's gone away (e.g. the browser tab closed).
+type sseProgressEmitter struct {
+	ch  chan<- agent.ProgressFrame
+	ctx context.Context
+}
+
+func (e *sseProgressEmitter) EmitProgress(frame agent.ProgressFrame) {
+	select {
+	case e.ch <- frame:
+	case <-e.ctx.Done():
+	}
+}
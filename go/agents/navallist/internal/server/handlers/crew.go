@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"navallist/internal/data/models"
+)
+
+// crewResolveRequest is the body POST /api/trips/{id}/crew/resolve expects.
+type crewResolveRequest struct {
+	Query string `json:"query"`
+}
+
+// crewResolveResponse is ResolveCrewMember's response body. Ambiguous is
+// set when the top two Matches are within 0.1 of each other, a signal to
+// the frontend that it should surface a disambiguation UI rather than
+// silently picking Matches[0].
+type crewResolveResponse struct {
+	Matches   []models.CrewMatch `json:"matches"`
+	Ambiguous bool               `json:"ambiguous"`
+}
+
+// ambiguousMatchMargin is how close the top two CrewMatch scores have to be
+// before ResolveCrewMember flags the result as ambiguous.
+const ambiguousMatchMargin = 0.1
+
+// ResolveCrewMember ranks every crew name on a trip against a fuzzy query
+// and returns the full candidate list, so a client can disambiguate between
+// near-tied matches instead of only ever seeing the one
+// UpdateItemWithAssignment would have silently picked.
+func (h *TripsHandler) ResolveCrewMember(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	var req crewResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "Missing query", http.StatusBadRequest)
+		return
+	}
+
+	matches, err := h.Store.FindCrewMember(r.Context(), tripID, req.Query)
+	if err != nil {
+		http.Error(w, "Failed to resolve crew member: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ambiguous := len(matches) >= 2 && matches[0].Score-matches[1].Score < ambiguousMatchMargin
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(crewResolveResponse{Matches: matches, Ambiguous: ambiguous})
+}
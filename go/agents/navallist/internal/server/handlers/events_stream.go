@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"navallist/internal/data"
+)
+
+// eventsHeartbeatInterval mirrors streamHeartbeatInterval's reasoning for
+// StreamAgentEvents: enough to keep idle-timeout proxies from closing the
+// connection, not so often it's noisy.
+const eventsHeartbeatInterval = 15 * time.Second
+
+// StreamEvents proxies a trip's SSEHub topic to the browser as
+// Server-Sent Events (GET /api/trips/{id}/events), fanning out
+// checklist_item updates, artifact uploads, crew join/leave, and agent
+// tool-call summaries to every connected crew browser for tripID. A
+// reconnecting client's Last-Event-ID header replays whatever the hub's
+// ring buffer still has for events it missed; anything older than that,
+// the client is expected to pick up by reloading the trip.
+func (h *TripsHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.SSEHub == nil {
+		http.Error(w, "Event stream is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID := data.GetUserID(r.Context())
+	guestName := data.GetGuestName(r.Context())
+	if userID == "" && guestName == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Crew membership is only meaningful for a real account; a named guest
+	// is allowed in by the same anonymous-link-join convention the
+	// Centrifuge path's HandleSubscribe uses (see realtime.Service).
+	if userID != "" {
+		isCrew, err := h.Store.IsTripCrew(r.Context(), tripID, userID)
+		if err != nil || !isCrew {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	} else if isCrew, err := h.Store.IsTripCrew(r.Context(), tripID, ""); err != nil || !isCrew {
+		http.Error(w, "Trip not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var sinceSeq int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		sinceSeq, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	ch, replay, unsubscribe := h.SSEHub.Subscribe(tripID, sinceSeq)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, ev := range replay {
+		writeSSEEvent(w, flusher, ev.Seq, ev.Type, ev.Data)
+	}
+
+	name := guestName
+	if name == "" {
+		name = userID
+	}
+	_ = h.SSEHub.Publish(tripID, "crew.joined", time.Time{}, map[string]string{"name": name})
+	defer func() {
+		_ = h.SSEHub.Publish(tripID, "crew.left", time.Time{}, map[string]string{"name": name})
+	}()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeSSEEvent(w, flusher, ev.Seq, ev.Type, ev.Data)
+		case <-heartbeat.C:
+			writeSSEComment(w, flusher, "heartbeat")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"navallist/internal/data/syncstore"
+
+	"github.com/charmbracelet/log"
+)
+
+// PushOps merges a batch of offline-authored ChecklistOps
+// (POST /api/trips/{id}/ops) into tripID's checklist via the CRDT merge
+// path (data.Store.ApplyOps), for a peer that went fully offline rather
+// than just queuing a mutation batch against a known base version
+// (contrast SyncItems).
+func (h *TripsHandler) PushOps(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	var ops []syncstore.ChecklistOp
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.Store.ApplyOps(r.Context(), tripID, ops)
+	if err != nil {
+		log.Error("Failed to apply ops", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to apply ops", http.StatusInternalServerError)
+		return
+	}
+
+	for _, item := range items {
+		h.publishTripEvent(r.Context(), tripID, "item.updated", item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+}
+
+// GetOps returns every op accepted for tripID since the client's
+// high-water mark (GET /api/trips/{id}/ops?since=<lamport>), so a peer
+// reconciling after a stretch offline can replay exactly what it missed.
+func (h *TripsHandler) GetOps(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+
+	ops, err := h.Store.GetOpsSince(r.Context(), tripID, since)
+	if err != nil {
+		log.Error("Failed to fetch ops", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to fetch ops", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ops)
+}
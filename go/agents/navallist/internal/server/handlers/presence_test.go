@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+	"navallist/internal/realtime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakePublisher is a realtime.Publisher test double that records the last
+// published event instead of touching a real Centrifuge node.
+type fakePublisher struct {
+	lastTripID    string
+	lastEvent     any
+	presence      map[string]realtime.PresenceEntry
+	presenceErr   error
+	presenceCalls int
+}
+
+func (f *fakePublisher) PublishTripEvent(_ context.Context, tripID string, event any) error {
+	f.lastTripID = tripID
+	f.lastEvent = event
+	return nil
+}
+
+func (f *fakePublisher) Presence(_ context.Context, _ string) (map[string]realtime.PresenceEntry, error) {
+	f.presenceCalls++
+	return f.presence, f.presenceErr
+}
+
+func TestTripsHandler_UpdateItem_PublishesItemUpdated(t *testing.T) {
+	item := &models.ChecklistItem{Name: "anchor", IsChecked: true}
+	mockStore := &data.MockStore{
+		UpdateItemFunc: func(_ context.Context, tripID, itemName string, isChecked bool, location string, photoArtifactID string, userID *string, completedByName string, assignedToUserID *string, assignedToName *string) (*models.ChecklistItem, error) {
+			return item, nil
+		},
+	}
+	publisher := &fakePublisher{}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), publisher, nil)
+
+	body, _ := json.Marshal(map[string]any{"is_checked": true})
+	req := httptest.NewRequest("PUT", "/api/trips/trip1/items/anchor", bytes.NewReader(body))
+	req.SetPathValue("id", "trip1")
+	req.SetPathValue("itemId", "anchor")
+	w := httptest.NewRecorder()
+	h.UpdateItem(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if publisher.lastTripID != "trip1" {
+		t.Errorf("Expected a publish for trip1, got %q", publisher.lastTripID)
+	}
+	published, ok := publisher.lastEvent.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected a map event, got %T", publisher.lastEvent)
+	}
+	if published["type"] != "item.updated" {
+		t.Errorf("Expected type item.updated, got %v", published["type"])
+	}
+	if published["data"] != item {
+		t.Errorf("Expected the updated item to be attached as data")
+	}
+}
+
+func TestTripsHandler_GetPresence_Unit(t *testing.T) {
+	publisher := &fakePublisher{presence: map[string]realtime.PresenceEntry{
+		"client1": {UserID: "user1"},
+	}}
+	h := NewTripsHandler(&data.MockStore{}, nil, data.NewDiskStorage(t.TempDir()), publisher, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/presence", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.GetPresence(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if publisher.presenceCalls != 1 {
+		t.Errorf("Expected one Presence call, got %d", publisher.presenceCalls)
+	}
+	var resp map[string]realtime.PresenceEntry
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp["client1"].UserID != "user1" {
+		t.Errorf("Expected client1's user to be user1, got %+v", resp["client1"])
+	}
+}
+
+func TestTripsHandler_GetPresence_NoRealtimeConfigured(t *testing.T) {
+	h := NewTripsHandler(&data.MockStore{}, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/presence", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.GetPresence(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when Realtime isn't configured, got %d", w.Code)
+	}
+}
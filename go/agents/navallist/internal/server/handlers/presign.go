@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"navallist/internal/data"
+
+	"github.com/charmbracelet/log"
+)
+
+// defaultPresignUploadTTL is how long a presigned upload URL stays valid
+// before the client must request a fresh one.
+const defaultPresignUploadTTL = 15 * time.Minute
+
+type presignUploadRequest struct {
+	TripID   string `json:"trip_id"`
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type"`
+}
+
+type presignUploadResponse struct {
+	ArtifactID string    `json:"artifact_id"`
+	UploadURL  string    `json:"upload_url"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// PresignArtifactUpload lets the agent/client upload an artifact's bytes
+// directly to the configured storage backend instead of round-tripping
+// them through this server: it mints a presigned PUT URL and records a
+// "pending" artifact for it, which internal/storagereconciler later
+// confirms or orphans depending on whether the upload actually lands.
+// Only backends implementing data.PresignUploader (currently S3/GCS/Azure)
+// support this; DiskStorage/WebDAV callers should keep using the existing
+// multipart upload endpoints.
+func (h *TripsHandler) PresignArtifactUpload(w http.ResponseWriter, r *http.Request) {
+	var req presignUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.TripID == "" || req.Filename == "" {
+		http.Error(w, "trip_id and filename are required", http.StatusBadRequest)
+		return
+	}
+
+	signer, ok := h.Storage.(data.PresignUploader)
+	if !ok {
+		http.Error(w, "Configured storage backend does not support direct uploads", http.StatusNotImplemented)
+		return
+	}
+
+	cleanName := strings.ReplaceAll(req.Filename, "/", "-")
+	objectName := fmt.Sprintf("upload_%s_%d_%s", req.TripID, time.Now().Unix(), cleanName)
+
+	storagePath, uploadURL, expiresAt, err := signer.SignedPutURL(r.Context(), objectName, req.MimeType, defaultPresignUploadTTL)
+	if err != nil {
+		log.Error("Failed to presign artifact upload", "trip_id", req.TripID, "error", err)
+		http.Error(w, "Failed to create upload URL", http.StatusInternalServerError)
+		return
+	}
+
+	artifact, err := h.Store.CreateExternalArtifact(r.Context(), req.TripID, objectName, req.MimeType, storagePath)
+	if err != nil {
+		log.Error("Failed to record external artifact", "trip_id", req.TripID, "error", err)
+		http.Error(w, "Failed to create upload URL", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(presignUploadResponse{
+		ArtifactID: artifact.ID,
+		UploadURL:  uploadURL,
+		ExpiresAt:  expiresAt,
+	})
+}
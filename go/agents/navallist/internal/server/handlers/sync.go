@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"navallist/internal/data"
+
+	"github.com/charmbracelet/log"
+)
+
+// SyncItems applies a batch of offline-queued checklist edits
+// (POST /api/trips/{id}/sync) and reports each mutation's outcome, so a
+// reconnecting client can reconcile its local store against what the
+// server actually persisted.
+func (h *TripsHandler) SyncItems(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	var muts []data.ItemMutation
+	if err := json.NewDecoder(r.Body).Decode(&muts); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var userID *string
+	if uid := data.GetUserID(r.Context()); uid != "" {
+		userID = &uid
+	}
+
+	results, err := h.Store.ApplyMutations(r.Context(), tripID, userID, muts)
+	if err != nil {
+		log.Error("Failed to apply sync mutations", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to apply mutations", http.StatusInternalServerError)
+		return
+	}
+
+	published := make(map[string]bool)
+	for _, result := range results {
+		if result.Item == nil || published[result.Item.ID] {
+			continue
+		}
+		published[result.Item.ID] = true
+		h.publishTripEvent(r.Context(), tripID, "item.updated", result.Item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"results": results})
+}
+
+// GetChanges returns tripID's checklist items that changed since the
+// client's high-water mark (GET /api/trips/{id}/changes?since=N), for a
+// reconnecting device to catch up incrementally instead of re-fetching
+// the whole report.
+func (h *TripsHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+
+	items, err := h.Store.GetChangedItems(r.Context(), tripID, since)
+	if err != nil {
+		log.Error("Failed to fetch trip changes", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to fetch changes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(items)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTripsHandler_SyncItems_Unit(t *testing.T) {
+	item := &models.ChecklistItem{ID: "item_1", Name: "Anchor", IsChecked: true, Version: 4}
+	var capturedMuts []data.ItemMutation
+
+	mockStore := &data.MockStore{
+		ApplyMutationsFunc: func(_ context.Context, tripID string, userID *string, muts []data.ItemMutation) ([]data.MutationResult, error) {
+			if tripID != "trip1" {
+				t.Errorf("Expected trip1, got %s", tripID)
+			}
+			capturedMuts = muts
+			return []data.MutationResult{{DeviceID: muts[0].DeviceID, ClientSeq: muts[0].ClientSeq, Status: data.MutationAccepted, Item: item}}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	body, _ := json.Marshal([]data.ItemMutation{{ItemName: "Anchor", DeviceID: "phoneA", ClientSeq: 1, IsChecked: boolPtr(true)}})
+	req := httptest.NewRequest("POST", "/api/trips/trip1/sync", bytes.NewReader(body))
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.SyncItems(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(capturedMuts) != 1 || capturedMuts[0].ItemName != "Anchor" {
+		t.Fatalf("Expected the mutation to be decoded through, got %+v", capturedMuts)
+	}
+
+	var resp struct {
+		Results []data.MutationResult `json:"results"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Status != data.MutationAccepted {
+		t.Errorf("Expected one accepted result, got %+v", resp.Results)
+	}
+}
+
+func TestTripsHandler_GetChanges_Unit(t *testing.T) {
+	mockStore := &data.MockStore{
+		GetChangedItemsFunc: func(_ context.Context, tripID string, sinceVersion int64) ([]models.ChecklistItem, error) {
+			if sinceVersion != 7 {
+				t.Errorf("Expected since=7, got %d", sinceVersion)
+			}
+			return []models.ChecklistItem{{Name: "Anchor", Version: 8}}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/changes?since=7", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.GetChanges(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var items []models.ChecklistItem
+	if err := json.NewDecoder(w.Body).Decode(&items); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(items) != 1 || items[0].Version != 8 {
+		t.Errorf("Expected the one changed item, got %+v", items)
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"navallist/internal/agent"
+	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
+)
+
+// timelineEntry is one item in GetTimeline's merged feed: either a
+// checklist_item_op field delta (see internal/data/syncstore) or an
+// AgentEvent tool-call record, ordered by when it happened so a post-trip
+// debrief can see what the agent did interleaved with what actually
+// changed on the checklist.
+type timelineEntry struct {
+	Type string    `json:"type"` // "checklist_delta" or "agent_event"
+	At   time.Time `json:"at"`
+	Data any       `json:"data"`
+}
+
+// GetTimeline returns a merged, time-ordered feed of checklist field
+// deltas (from the CRDT op log ApplyOps writes to - see
+// internal/data/syncstore) and agent tool-call events for tripID, for a
+// post-trip debrief of what the agent did and what actually changed.
+func (h *TripsHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	ops, err := h.Store.GetOpsSince(r.Context(), tripID, 0)
+	if err != nil {
+		log.Error("Failed to fetch checklist ops for timeline", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to build timeline", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := h.Store.GetAgentEvents(r.Context(), tripID, 0)
+	if err != nil {
+		log.Error("Failed to fetch agent events for timeline", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to build timeline", http.StatusInternalServerError)
+		return
+	}
+
+	entries := make([]timelineEntry, 0, len(ops)+len(events))
+	for _, op := range ops {
+		entries = append(entries, timelineEntry{Type: "checklist_delta", At: op.WallClock, Data: op})
+	}
+	for _, event := range events {
+		entries = append(entries, timelineEntry{Type: "agent_event", At: event.CreatedAt, Data: event})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+// ReplayTrip rebuilds checklist item state into a fresh sandbox trip by
+// re-applying every mutating agent tool call recorded for tripID up to
+// and including upToEventID (0 means "replay everything"), so a what-if
+// analysis or bug reproduction (e.g. the agent misassigning crew) can be
+// driven against a disposable copy rather than the live trip. Read-only
+// tool calls (get_crew_list, get_checklist_status) are skipped since they
+// don't change state to replay.
+func (h *TripsHandler) ReplayTrip(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	var upToEventID int64
+	if v := r.URL.Query().Get("upToEventID"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid upToEventID", http.StatusBadRequest)
+			return
+		}
+		upToEventID = parsed
+	}
+
+	trip, err := h.Store.GetTrip(r.Context(), tripID)
+	if err != nil {
+		http.Error(w, "Trip not found", http.StatusNotFound)
+		return
+	}
+
+	events, err := h.Store.GetAgentEvents(r.Context(), tripID, upToEventID)
+	if err != nil {
+		log.Error("Failed to fetch agent events for replay", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to replay trip", http.StatusInternalServerError)
+		return
+	}
+
+	captainName := ""
+	if trip.CaptainName != nil {
+		captainName = *trip.CaptainName
+	}
+	sandboxSessionID := fmt.Sprintf("replay_%s_%d", tripID, time.Now().UnixNano())
+	sandbox, err := h.Store.GetOrCreateTrip(r.Context(), sandboxSessionID, "", captainName, trip.TripType)
+	if err != nil {
+		log.Error("Failed to create sandbox trip for replay", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to replay trip", http.StatusInternalServerError)
+		return
+	}
+
+	for _, event := range events {
+		switch event.ToolName {
+		case "update_checklist_items":
+			var args agent.UpdateItemsArgs
+			if err := json.Unmarshal(event.ArgsJSON, &args); err != nil {
+				log.Warn("Failed to unmarshal replayed args", "event_id", event.ID, "tool", event.ToolName, "error", err)
+				continue
+			}
+			for _, update := range args.Updates {
+				if _, _, err := h.Store.UpdateItemWithAssignment(r.Context(), sandbox.ID, update.ItemName, update.IsChecked, update.Location, update.PhotoArtifactID, event.UserID, update.AssignedToName); err != nil {
+					log.Warn("Failed to replay checklist update", "event_id", event.ID, "item", update.ItemName, "error", err)
+				}
+			}
+		case "update_trip_details":
+			var args agent.UpdateTripArgs
+			if err := json.Unmarshal(event.ArgsJSON, &args); err != nil {
+				log.Warn("Failed to unmarshal replayed args", "event_id", event.ID, "tool", event.ToolName, "error", err)
+				continue
+			}
+			var boatName, updatedCaptainName *string
+			if args.BoatName != "" {
+				boatName = &args.BoatName
+			}
+			if args.CaptainName != "" {
+				updatedCaptainName = &args.CaptainName
+			}
+			if _, err := h.Store.UpdateTripMetadata(r.Context(), sandboxSessionID, boatName, updatedCaptainName); err != nil {
+				log.Warn("Failed to replay trip metadata update", "event_id", event.ID, "error", err)
+			}
+		}
+	}
+
+	items, err := h.Store.GetTripReport(r.Context(), sandbox.ID)
+	if err != nil {
+		items = []models.ChecklistItem{}
+	}
+	sandbox, err = h.Store.GetTrip(r.Context(), sandbox.ID)
+	if err != nil {
+		http.Error(w, "Failed to reload sandbox trip", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(models.UnifiedTrip{
+		Trip:  sandbox,
+		Items: items,
+	})
+}
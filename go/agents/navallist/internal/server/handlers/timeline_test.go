@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+	"navallist/internal/data/syncstore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTripsHandler_GetTimeline_MergesOpsAndAgentEventsByTime(t *testing.T) {
+	base := time.Now()
+	mockStore := &data.MockStore{
+		GetOpsSinceFunc: func(_ context.Context, tripID string, sinceLamport int64) ([]syncstore.ChecklistOp, error) {
+			return []syncstore.ChecklistOp{
+				{ItemName: "Anchor", Field: syncstore.FieldIsChecked, Value: true, OriginUserID: "agent", Lamport: 1, WallClock: base.Add(time.Second)},
+			}, nil
+		},
+		GetAgentEventsFunc: func(_ context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error) {
+			return []models.AgentEvent{
+				{ID: 1, ToolName: "update_checklist_items", Status: "success", CreatedAt: base},
+			}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/timeline", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.GetTimeline(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var entries []timelineEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 merged entries, got %d", len(entries))
+	}
+	if entries[0].Type != "agent_event" || entries[1].Type != "checklist_delta" {
+		t.Errorf("Expected entries ordered by time (agent_event first), got %+v", entries)
+	}
+}
+
+func TestTripsHandler_ReplayTrip_ReappliesChecklistUpdates(t *testing.T) {
+	argsJSON, _ := json.Marshal(map[string]any{
+		"updates": []map[string]any{{"item_name": "Anchor", "is_checked": true}},
+	})
+
+	var capturedTripID string
+	mockStore := &data.MockStore{
+		GetTripFunc: func(_ context.Context, id string) (*models.Trip, error) {
+			if id == "sandbox1" {
+				return &models.Trip{ID: "sandbox1", TripType: "Leisure"}, nil
+			}
+			return &models.Trip{ID: "trip1", TripType: "Leisure"}, nil
+		},
+		GetAgentEventsFunc: func(_ context.Context, tripID string, upToEventID int64) ([]models.AgentEvent, error) {
+			return []models.AgentEvent{
+				{ID: 1, ToolName: "update_checklist_items", ArgsJSON: argsJSON},
+			}, nil
+		},
+		GetOrCreateTripFunc: func(_ context.Context, sessionID, userID, captainName, tripType string) (*models.Trip, error) {
+			return &models.Trip{ID: "sandbox1", ADKSessionID: sessionID, TripType: tripType}, nil
+		},
+		UpdateItemWithAssignmentFunc: func(_ context.Context, tripID, itemName string, isChecked bool, location, photoID, currentUserID, assignedToName string) (*models.ChecklistItem, bool, error) {
+			capturedTripID = tripID
+			if itemName != "Anchor" || !isChecked {
+				t.Errorf("Expected the replayed args to reapply Anchor=true, got %s=%v", itemName, isChecked)
+			}
+			return &models.ChecklistItem{Name: itemName, IsChecked: isChecked}, true, nil
+		},
+		GetTripReportFunc: func(_ context.Context, tripID string) ([]models.ChecklistItem, error) {
+			return []models.ChecklistItem{{Name: "Anchor", IsChecked: true}}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/trips/trip1/replay", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.ReplayTrip(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if capturedTripID != "sandbox1" {
+		t.Errorf("Expected the update to be replayed against the sandbox trip, got %q", capturedTripID)
+	}
+
+	var unified models.UnifiedTrip
+	if err := json.NewDecoder(w.Body).Decode(&unified); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(unified.Items) != 1 || !unified.Items[0].IsChecked {
+		t.Errorf("Expected the sandbox report to reflect the replayed update, got %+v", unified.Items)
+	}
+}
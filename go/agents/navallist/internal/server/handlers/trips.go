@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"navallist/internal/agent"
 	"navallist/internal/data"
 	"navallist/internal/data/models"
+	"navallist/internal/imaging"
+	"navallist/internal/ratelimit"
+	"navallist/internal/realtime"
 
 	"github.com/charmbracelet/log"
 )
@@ -19,16 +27,72 @@ import (
 // TripsHandler handles trip-related requests.
 type TripsHandler struct {
 	Store       data.Store
-	AgentClient *agent.LocalAgentClient
+	AgentClient agent.Client
 	Storage     data.BlobStorage
+
+	// Realtime publishes trip status/type changes onto the trip's
+	// Centrifuge channel so connected clients see them live. May be nil,
+	// in which case updates simply aren't broadcast in real time.
+	Realtime realtime.Publisher
+
+	// SSEHub fans out checklist/artifact/crew/agent events to StreamEvents
+	// subscribers. May be nil, in which case StreamEvents is unavailable
+	// but every other handler's publish calls are simply no-ops.
+	SSEHub *realtime.SSEHub
+
+	// SignedURLTTL overrides how long a GetArtifact signed URL stays valid.
+	// Zero uses defaultSignedArtifactURLTTL.
+	SignedURLTTL time.Duration
+
+	// StreamLimiter caps how often a user may open StreamAgentEvents or
+	// StreamAgentEventsWS for a given trip. Zero uses a per-process default limiter shared across all
+	// trips/users.
+	StreamLimiter *ratelimit.Limiter
 }
 
 // NewTripsHandler initializes a new TripsHandler with the given dependencies.
-func NewTripsHandler(store data.Store, agentClient *agent.LocalAgentClient, storage data.BlobStorage) *TripsHandler {
-	return &TripsHandler{Store: store, AgentClient: agentClient, Storage: storage}
+func NewTripsHandler(store data.Store, agentClient agent.Client, storage data.BlobStorage, realtimePublisher realtime.Publisher, sseHub *realtime.SSEHub) *TripsHandler {
+	return &TripsHandler{Store: store, AgentClient: agentClient, Storage: storage, Realtime: realtimePublisher, SSEHub: sseHub}
+}
+
+// defaultSignedArtifactURLTTL is how long a GetArtifact signed URL stays
+// valid before a client must request a fresh one.
+const defaultSignedArtifactURLTTL = 15 * time.Minute
+
+func (h *TripsHandler) signedURLTTL() time.Duration {
+	if h.SignedURLTTL > 0 {
+		return h.SignedURLTTL
+	}
+	return defaultSignedArtifactURLTTL
+}
+
+// defaultStreamRatePerSec/defaultStreamBurst bound how often one
+// userID+tripID pair may open an agent stream, so one user's agent panel
+// left open and refreshing can't starve the shared agent pool for others.
+const (
+	defaultStreamRatePerSec = 0.2 // one new stream every 5s, sustained
+	defaultStreamBurst      = 3
+)
+
+var defaultStreamLimiter = ratelimit.NewLimiter(defaultStreamRatePerSec, defaultStreamBurst)
+
+func (h *TripsHandler) streamLimiter() *ratelimit.Limiter {
+	if h.StreamLimiter != nil {
+		return h.StreamLimiter
+	}
+	return defaultStreamLimiter
 }
 
 // UploadItemPhoto handles the upload of a photo for a specific checklist item.
+// maxPhotoUploadSize caps a single UploadItemPhoto request; larger uploads
+// should go through the resumable /photo/uploads protocol instead.
+const maxPhotoUploadSize = 10 << 20 // 10MB
+
+// uploadReadDeadline bounds how long UploadItemPhoto will wait to read the
+// photo part of the request body, so a stalled or malicious client can't
+// hold the request (and its in-memory buffer) open indefinitely.
+const uploadReadDeadline = 30 * time.Second
+
 func (h *TripsHandler) UploadItemPhoto(w http.ResponseWriter, r *http.Request) {
 	tripID := r.PathValue("id")
 	itemID := r.PathValue("itemId")
@@ -38,62 +102,247 @@ func (h *TripsHandler) UploadItemPhoto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 1. Parse File
-	// Limit upload size to 10MB
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "File too large", http.StatusBadRequest)
+	ctx, cancel := context.WithTimeout(r.Context(), uploadReadDeadline)
+	defer cancel()
+
+	// Stream the file part incrementally via MultipartReader rather than
+	// buffering the whole request body up front with ParseMultipartForm.
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected multipart/form-data", http.StatusBadRequest)
 		return
 	}
-	file, handler, err := r.FormFile("file")
-	if err != nil {
-		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+
+	var part *multipart.Part
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, "Error reading multipart body", http.StatusBadRequest)
+			return
+		}
+		if p.FormName() == "file" {
+			part = p
+			break
+		}
+		_ = p.Close()
+	}
+	if part == nil {
+		http.Error(w, "Missing file", http.StatusBadRequest)
 		return
 	}
 	defer func() {
-		if err := file.Close(); err != nil {
-			log.Error("failed to close uploaded file", "error", err)
+		if err := part.Close(); err != nil {
+			log.Error("failed to close uploaded file part", "error", err)
 		}
 	}()
 
-	fileBytes, err := io.ReadAll(file)
+	// ctxReader cancels the read as soon as uploadReadDeadline passes,
+	// mirroring the read-deadline pattern net.Conn uses. LimitReader still
+	// caps the size so a client that never stalls can't send more than
+	// maxPhotoUploadSize anyway.
+	limited := io.LimitReader(ctxReader{ctx: ctx, r: part}, maxPhotoUploadSize+1)
+	fileBytes, err := io.ReadAll(limited)
+	if err != nil {
+		if ctx.Err() != nil {
+			http.Error(w, "Upload deadline exceeded", http.StatusRequestTimeout)
+		} else {
+			http.Error(w, "Error reading file", http.StatusInternalServerError)
+		}
+		return
+	}
+	if len(fileBytes) > maxPhotoUploadSize {
+		http.Error(w, "File too large", http.StatusBadRequest)
+		return
+	}
+
+	contentType := part.Header.Get("Content-Type")
+	fileExt := filepath.Ext(part.FileName())
 
+	artifactID, err := h.ingestPhotoUpload(ctx, tripID, itemID, fileBytes, contentType, fileExt)
 	if err != nil {
-		http.Error(w, "Error reading file", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// 2. Generate Filename
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":      "success",
+		"artifact_id": artifactID,
+	})
+}
+
+// ctxReader aborts Read as soon as ctx is done, so a reader wrapped around
+// a slow client's request body can't block a goroutine past its deadline.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// ingestPhotoUpload runs the dedupe/normalize/store/link pipeline shared by
+// UploadItemPhoto and the resumable upload finalize step: it dedupes
+// against an existing byte-identical artifact, strips EXIF/GPS before
+// writing to BlobStorage, persists the extracted EXIF separately, links the
+// result to the checklist item, and best-effort prefills the item's
+// location from the photo's GPS tag. Returns the linked artifact's ID.
+func (h *TripsHandler) ingestPhotoUpload(ctx context.Context, tripID, itemID string, fileBytes []byte, contentType, fileExt string) (string, error) {
+	// 1. Dedupe re-uploads of a byte-identical photo onto the artifact
+	// already created for it, rather than storing it again.
+	contentHash := imaging.ContentHash(fileBytes)
+	if existing, err := h.Store.FindArtifactByHash(ctx, contentHash); err == nil {
+		item, err := h.Store.AddItemPhoto(ctx, tripID, itemID, existing.ID)
+		if err != nil {
+			return "", fmt.Errorf("error linking photo to item: %w", err)
+		}
+		h.publishSSEEvent(tripID, "artifact.uploaded", time.Time{}, item)
+		return existing.ID, nil
+	} else if !errors.Is(err, data.ErrNotFound) {
+		log.Error("Failed to check for duplicate artifact by hash", "error", err)
+	}
+
+	// 2. Extract EXIF metadata, then strip it (GPS included) from the bytes
+	// actually written to storage.
+	meta := imaging.ExtractMetadata(fileBytes)
+	normalized, err := imaging.Normalize(fileBytes, meta.Orientation)
+	if err != nil {
+		log.Warn("Failed to normalize photo; storing original bytes", "error", err)
+	}
+
+	// 3. Generate Filename
 	// photo_{tripID}_{sanitized_item_name}_{timestamp}.jpg
 	cleanItemName := strings.ReplaceAll(itemID, " ", "_")
 	cleanItemName = strings.ReplaceAll(cleanItemName, "/", "-")
-	filename := fmt.Sprintf("photo_%s_%s_%d%s", tripID, cleanItemName, time.Now().Unix(), filepath.Ext(handler.Filename))
+	filename := fmt.Sprintf("photo_%s_%s_%d%s", tripID, cleanItemName, time.Now().Unix(), fileExt)
+
+	// 4. Save to Storage
+	storagePath, _, err := h.Storage.SaveStream(ctx, filename, bytes.NewReader(normalized), contentType)
+	if err != nil {
+		return "", fmt.Errorf("error saving file: %w", err)
+	}
+
+	// 5. Create Artifact
+	artifact, err := h.Store.CreateArtifact(ctx, tripID, filename, contentType, storagePath)
+	if err != nil {
+		return "", fmt.Errorf("error creating artifact record: %w", err)
+	}
+
+	// 5b. Record the stored bytes as a content-addressed blob/manifest,
+	// best-effort: this unlocks cross-trip dedup and export/GC but a
+	// failure here shouldn't fail an upload that's already been linked.
+	h.recordArtifactManifest(ctx, tripID, itemID, normalized, contentType)
+
+	// 6. Save EXIF metadata/hashes, best-effort: a failure here shouldn't
+	// fail the upload, since the artifact itself was already stored.
+	exif := models.ArtifactExif{
+		ArtifactID:  artifact.ID,
+		ContentHash: contentHash,
+		GPSLat:      meta.GPSLat,
+		GPSLon:      meta.GPSLon,
+		CapturedAt:  meta.CapturedAt,
+		Orientation: meta.Orientation,
+	}
+	if phash, err := imaging.PerceptualHash(fileBytes); err == nil {
+		signed := int64(phash)
+		exif.PHash = &signed
+	}
+	if err := h.Store.SaveArtifactExif(ctx, exif); err != nil {
+		log.Error("Failed to save artifact EXIF metadata", "artifact_id", artifact.ID, "error", err)
+	}
 
-	// 3. Save to Storage
-	storagePath, err := h.Storage.Save(r.Context(), filename, fileBytes, handler.Header.Get("Content-Type"))
+	// 7. Link to Item
+	item, err := h.Store.AddItemPhoto(ctx, tripID, itemID, artifact.ID)
 	if err != nil {
-		http.Error(w, "Error saving file: "+err.Error(), http.StatusInternalServerError)
+		return "", fmt.Errorf("error linking photo to item: %w", err)
+	}
+	h.publishSSEEvent(tripID, "artifact.uploaded", time.Time{}, item)
+
+	// 8. If the item has no location yet and the photo was geotagged,
+	// prefill it - best-effort, same reasoning as 6 above.
+	if item != nil && (item.LocationText == nil || *item.LocationText == "") && meta.GPSLat != nil && meta.GPSLon != nil {
+		h.prefillItemLocation(ctx, tripID, itemID, item, *meta.GPSLat, *meta.GPSLon)
+	}
+
+	return artifact.ID, nil
+}
+
+// recordArtifactManifest hashes the bytes that were just written to storage
+// and records them as a content-addressed blob plus a single-layer
+// ArtifactManifest tagged with trip_id/checklist_item_id, so re-uploading
+// the same photo to a different trip shares the existing blob. Errors are
+// logged, not returned: manifests are a dedup/export optimization layered
+// on top of the artifact that's already been created and linked.
+func (h *TripsHandler) recordArtifactManifest(ctx context.Context, tripID, itemID string, data []byte, contentType string) {
+	hash := imaging.ContentHash(data)
+	blobPath := fmt.Sprintf("%s/%s", hash[:2], hash)
+	if _, err := h.Store.GetOrCreateBlob(ctx, hash, int64(len(data)), contentType, blobPath); err != nil {
+		log.Error("Failed to record content-addressed blob", "trip_id", tripID, "item_id", itemID, "error", err)
+		return
+	}
+
+	layers := []models.Layer{{ContentHash: hash, Size: int64(len(data)), MimeType: contentType}}
+	meta := map[string]string{"trip_id": tripID, "checklist_item_id": itemID}
+	if _, err := h.Store.CreateArtifactManifest(ctx, layers, meta); err != nil {
+		log.Error("Failed to record artifact manifest", "trip_id", tripID, "item_id", itemID, "error", err)
+	}
+}
+
+// prefillItemLocation fills in a checklist item's location from a photo's
+// GPS tag, preserving every other field UpdateItem's upsert would
+// otherwise overwrite with the call's (zero-value) arguments.
+func (h *TripsHandler) prefillItemLocation(ctx context.Context, tripID, itemID string, item *models.ChecklistItem, lat, lon float64) {
+	location := fmt.Sprintf("%.6f,%.6f", lat, lon)
+	var completedByName string
+	if item.CompletedByName != nil {
+		completedByName = *item.CompletedByName
+	}
+	if _, err := h.Store.UpdateItem(ctx, tripID, itemID, item.IsChecked, location, "", item.CompletedByUserID, completedByName, item.AssignedToUserID, item.AssignedToName); err != nil {
+		log.Error("Failed to prefill item location from photo GPS tag", "trip_id", tripID, "item_id", itemID, "error", err)
+	}
+}
+
+// GetPhotosNear returns every artifact on a trip with a GPS tag within
+// radius_m meters of (lat, lon), for "photos near this waypoint" views.
+func (h *TripsHandler) GetPhotosNear(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
 		return
 	}
 
-	// 4. Create Artifact
-	artifact, err := h.Store.CreateArtifact(r.Context(), tripID, filename, handler.Header.Get("Content-Type"), storagePath)
+	q := r.URL.Query()
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "Invalid or missing lat", http.StatusBadRequest)
+		return
+	}
+	lon, err := strconv.ParseFloat(q.Get("lon"), 64)
+	if err != nil {
+		http.Error(w, "Invalid or missing lon", http.StatusBadRequest)
+		return
+	}
+	radiusM, err := strconv.ParseFloat(q.Get("radius_m"), 64)
 	if err != nil {
-		http.Error(w, "Error creating artifact record: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid or missing radius_m", http.StatusBadRequest)
 		return
 	}
 
-	// 5. Link to Item
-	_, err = h.Store.AddItemPhoto(r.Context(), tripID, itemID, artifact.ID)
+	artifacts, err := h.Store.FindArtifactsNear(r.Context(), tripID, lat, lon, radiusM)
 	if err != nil {
-		http.Error(w, "Error linking photo to item: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"status":      "success",
-		"artifact_id": artifact.ID,
-	})
+	_ = json.NewEncoder(w).Encode(artifacts)
 }
 
 // UpdateStatus handles the update of a trip's status.
@@ -117,6 +366,8 @@ func (h *TripsHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishTripEvent(r.Context(), id, "trip.status_changed", map[string]string{"status": req.Status})
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -141,9 +392,40 @@ func (h *TripsHandler) UpdateType(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishTripEvent(r.Context(), id, "trip.type_changed", map[string]string{"trip_type": req.TripType})
+
 	w.WriteHeader(http.StatusOK)
 }
 
+// publishTripEvent best-effort publishes a realtime event for a trip; a
+// publish failure (or no Realtime publisher configured) is logged, not
+// returned, since the underlying store update already succeeded and a
+// client can always reload the trip to pick up the change.
+func (h *TripsHandler) publishTripEvent(ctx context.Context, tripID, eventType string, data any) {
+	if h.Realtime == nil {
+		return
+	}
+
+	event := map[string]any{"type": eventType, "trip_id": tripID, "data": data}
+	if err := h.Realtime.PublishTripEvent(ctx, tripID, event); err != nil {
+		log.Error("Failed to publish trip event", "trip_id", tripID, "type", eventType, "error", err)
+	}
+}
+
+// publishSSEEvent best-effort publishes an event onto tripID's SSEHub
+// topic, at mirroring Publish's "pass the zero Time if there's no natural
+// updated_at" contract. A failure (or no SSEHub configured) is logged, not
+// returned, for the same reason publishTripEvent's is: the DB write this
+// follows already succeeded.
+func (h *TripsHandler) publishSSEEvent(tripID, eventType string, at time.Time, payload any) {
+	if h.SSEHub == nil {
+		return
+	}
+	if err := h.SSEHub.Publish(tripID, eventType, at, payload); err != nil {
+		log.Error("Failed to publish SSE event", "trip_id", tripID, "type", eventType, "error", err)
+	}
+}
+
 // GetArtifact handles the retrieval of an artifact's data.
 func (h *TripsHandler) GetArtifact(w http.ResponseWriter, r *http.Request) {
 	// Query params: path (which is the filename)
@@ -178,6 +460,10 @@ func (h *TripsHandler) GetArtifact(w http.ResponseWriter, r *http.Request) {
 				art, err = h.Store.GetArtifactByID(r.Context(), cleanFilename)
 			}
 
+			if err != nil {
+				art, err = h.resolveManifestOrBlobArtifact(r.Context(), cleanFilename)
+			}
+
 			if err != nil {
 				http.Error(w, "Artifact not found", http.StatusNotFound)
 				return
@@ -185,28 +471,205 @@ func (h *TripsHandler) GetArtifact(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// 2. Serve
-	// Check if we can redirect to a public URL (e.g. GCS)
+	// 2. Serve. By default we hand back a time-limited signed URL rather
+	// than the bytes themselves, so large photos support Range/resume
+	// downloads via ServeSignedArtifact. ?inline=1 preserves the old
+	// direct-streaming behavior for callers that don't follow a URL.
+	if r.URL.Query().Get("inline") == "1" {
+		h.streamArtifact(w, r, art)
+		return
+	}
+
+	tripID := ""
+	if art.TripID != nil {
+		tripID = *art.TripID
+	}
+
+	url, expiresAt, err := h.Storage.SignedURL(r.Context(), art.StoragePath, h.signedURLTTL(), data.SignedURLOptions{
+		ArtifactID: art.ID,
+		TripID:     tripID,
+		UserID:     data.GetUserID(r.Context()),
+	})
+	if err != nil {
+		http.Error(w, "Failed to generate signed URL: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"url":        url,
+		"expires_at": expiresAt,
+	})
+}
+
+// resolveManifestOrBlobArtifact is GetArtifact's last resort once no
+// artifact row matches id by ID or filename: id might instead be an
+// ArtifactManifest ID or a raw blob content hash, both of which serve the
+// same signed-URL/inline-stream path as a regular artifact once wrapped in
+// a synthetic *models.Artifact pointed at the blob's storage path.
+func (h *TripsHandler) resolveManifestOrBlobArtifact(ctx context.Context, id string) (*models.Artifact, error) {
+	var blob *models.Blob
+
+	manifest, err := h.Store.GetArtifactManifest(ctx, id)
+	switch {
+	case err == nil && len(manifest.Layers) > 0:
+		blob, err = h.Store.GetBlob(ctx, manifest.Layers[0].ContentHash)
+	case errors.Is(err, data.ErrNotFound):
+		blob, err = h.Store.GetBlob(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, data.ErrNotFound
+	}
+
+	mimeType := blob.MimeType
+	return &models.Artifact{
+		ID:          id,
+		Filename:    id,
+		MimeType:    &mimeType,
+		StoragePath: blob.StoragePath,
+		CreatedAt:   blob.CreatedAt,
+	}, nil
+}
+
+// streamArtifact writes the artifact's bytes (or a redirect to its public
+// URL) directly to w. It backs GetArtifact's ?inline=1 fallback and
+// ServeSignedArtifact once a signed token has been verified.
+func (h *TripsHandler) streamArtifact(w http.ResponseWriter, r *http.Request, art *models.Artifact) {
+	// Check if we can redirect to a public URL (e.g. S3)
 	if pubURL := h.Storage.GetPublicURL(art.StoragePath); pubURL != "" {
 		http.Redirect(w, r, pubURL, http.StatusFound)
 		return
 	}
 
 	// Fallback to loading from storage (e.g. Disk)
-	data, err := h.Storage.Load(r.Context(), art.StoragePath)
+	blob, err := h.Storage.Load(r.Context(), art.StoragePath)
 	if err != nil {
 		http.Error(w, "Failed to read artifact from storage", http.StatusInternalServerError)
 		return
 	}
 
-	if art.MimeType != nil {
-		w.Header().Set("Content-Type", *art.MimeType)
+	if ct := h.resolveContentType(r.Context(), art); ct != "" {
+		w.Header().Set("Content-Type", ct)
 	} else {
 		w.Header().Set("Content-Type", "application/octet-stream")
 	}
 
 	w.Header().Set("Cache-Control", "public, max-age=3600")
-	_, _ = w.Write(data)
+	_, _ = w.Write(blob)
+}
+
+// resolveContentType returns art's content type, preferring the DB's
+// MimeType but falling back to the storage backend's own native metadata
+// (see data.ContentTypeReader) for artifacts or backends where it's unset -
+// e.g. a file written to DiskStorage before this field existed.
+func (h *TripsHandler) resolveContentType(ctx context.Context, art *models.Artifact) string {
+	if art.MimeType != nil && *art.MimeType != "" {
+		return *art.MimeType
+	}
+	if reader, ok := h.Storage.(data.ContentTypeReader); ok {
+		if ct, err := reader.ContentType(ctx, art.StoragePath); err == nil {
+			return ct
+		}
+	}
+	return ""
+}
+
+// ServeSignedArtifact redeems a token minted by GetArtifact's signed URL and
+// streams the artifact with Range-request support, so large photos can be
+// resumed or streamed without re-authenticating. Only storage backends that
+// implement data.SignedURLVerifier (currently DiskStorage) use this route;
+// S3/GCS-style backends hand out provider-native presigned URLs instead.
+func (h *TripsHandler) ServeSignedArtifact(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+	if token == "" {
+		http.Error(w, "Missing token", http.StatusBadRequest)
+		return
+	}
+
+	verifier, ok := h.Storage.(data.SignedURLVerifier)
+	if !ok {
+		http.Error(w, "Signed URLs are not supported by this storage backend", http.StatusNotImplemented)
+		return
+	}
+
+	claim, err := verifier.VerifySignedURL(token)
+	if err != nil {
+		http.Error(w, "Invalid or expired URL", http.StatusForbidden)
+		return
+	}
+
+	art, err := h.Store.GetArtifactByID(r.Context(), claim.ArtifactID)
+	if err != nil || art.StoragePath != claim.Path {
+		http.Error(w, "Invalid or expired URL", http.StatusForbidden)
+		return
+	}
+
+	// A token only grants access to the trip it was minted for (claim.TripID,
+	// see GetArtifact's SignedURL call) - without this check, artifact IDs
+	// and filenames aren't secret (they're visible in GetArtifact's own
+	// query params and trip report payloads), so a leaked URL could be
+	// replayed to serve an artifact under a different trip.
+	artTripID := ""
+	if art.TripID != nil {
+		artTripID = *art.TripID
+	}
+	if claim.TripID == "" || artTripID != claim.TripID {
+		http.Error(w, "Invalid or expired URL", http.StatusForbidden)
+		return
+	}
+
+	blob, err := h.Storage.Load(r.Context(), claim.Path)
+	if err != nil {
+		http.Error(w, "Failed to read artifact from storage", http.StatusInternalServerError)
+		return
+	}
+
+	if ct := h.resolveContentType(r.Context(), art); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename=%q`, art.Filename))
+
+	http.ServeContent(w, r, art.Filename, art.CreatedAt, bytes.NewReader(blob))
+}
+
+// GetArtifactThumbnail serves a resized derivative of an artifact (see
+// data.Store.GetArtifactSized), so the trip report UI and mobile clients can
+// load a small thumbnail per checklist row instead of the full-resolution
+// original. size defaults to 512 and must be one of the allow-listed
+// thumbnail sizes.
+func (h *TripsHandler) GetArtifactThumbnail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Missing artifact ID", http.StatusBadRequest)
+		return
+	}
+
+	size := 512
+	if raw := r.URL.Query().Get("size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid size parameter", http.StatusBadRequest)
+			return
+		}
+		size = parsed
+	}
+
+	blob, contentType, err := h.Store.GetArtifactSized(r.Context(), id, size)
+	if err != nil {
+		if errors.Is(err, data.ErrNotFound) {
+			http.Error(w, "Artifact not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to generate thumbnail: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = w.Write(blob)
 }
 
 // GetReport handles the generation of a trip's report.
@@ -391,6 +854,34 @@ func (h *TripsHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.publishTripEvent(r.Context(), tripID, "item.updated", item)
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(item)
 }
+
+// GetPresence reports who is currently subscribed to a trip's realtime
+// channel, for clients that can't hold a WS connection (e.g. polling
+// integrations) to approximate "who's viewing this trip" without one.
+func (h *TripsHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "Missing trip ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.Realtime == nil {
+		http.Error(w, "Realtime is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	presence, err := h.Realtime.Presence(r.Context(), tripID)
+	if err != nil {
+		log.Error("Failed to fetch trip presence", "trip_id", tripID, "error", err)
+		http.Error(w, "Failed to fetch presence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(presence)
+}
@@ -10,6 +10,7 @@ import (
 	"navallist/internal/data/models"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -25,7 +26,7 @@ func TestTripsHandler_GetTrip_Unit(t *testing.T) {
 			return []models.ChecklistItem{}, nil
 		},
 	}
-	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage("/tmp"))
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage("/tmp"), nil, nil)
 	tests := []struct {
 		name           string
 		tripID         string
@@ -70,6 +71,7 @@ func TestTripsHandler_GetTrip_Unit(t *testing.T) {
 }
 func TestTripsHandler_GetArtifact_Unit(t *testing.T) {
 	mockStorage := data.NewDiskStorage(t.TempDir())
+	mockStorage.SignSecret = []byte("unit-test-secret")
 	ctx := context.Background()
 	// Pre-seed storage and get real path
 	storagePath, _ := mockStorage.Save(ctx, "photo.jpg", []byte("fake image data"), "image/jpeg")
@@ -87,7 +89,7 @@ func TestTripsHandler_GetArtifact_Unit(t *testing.T) {
 			return nil, errors.New("not found")
 		},
 	}
-	h := NewTripsHandler(mockStore, nil, mockStorage)
+	h := NewTripsHandler(mockStore, nil, mockStorage, nil, nil)
 	tests := []struct {
 		name           string
 		pathParam      string
@@ -125,17 +127,167 @@ func TestTripsHandler_GetArtifact_Unit(t *testing.T) {
 		})
 	}
 }
+
+func TestTripsHandler_GetArtifactThumbnail_Unit(t *testing.T) {
+	mockStore := &data.MockStore{
+		GetArtifactSizedFunc: func(_ context.Context, id string, size int) ([]byte, string, error) {
+			switch id {
+			case "art_123":
+				return []byte("thumbnail bytes"), "image/jpeg", nil
+			case "bad_size":
+				return nil, "", errors.New("unsupported size")
+			default:
+				return nil, "", data.ErrNotFound
+			}
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage("/tmp"), nil, nil)
+	tests := []struct {
+		name           string
+		artifactID     string
+		size           string
+		expectedStatus int
+	}{
+		{name: "Success", artifactID: "art_123", size: "128", expectedStatus: http.StatusOK},
+		{name: "DefaultSize", artifactID: "art_123", size: "", expectedStatus: http.StatusOK},
+		{name: "InvalidSizeParam", artifactID: "art_123", size: "not-a-number", expectedStatus: http.StatusBadRequest},
+		{name: "UnsupportedSize", artifactID: "bad_size", size: "999", expectedStatus: http.StatusBadRequest},
+		{name: "NotFound", artifactID: "missing", size: "128", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/api/artifacts/" + tt.artifactID + "/thumbnail"
+			if tt.size != "" {
+				url += "?size=" + tt.size
+			}
+			req := httptest.NewRequest("GET", url, nil)
+			req.SetPathValue("id", tt.artifactID)
+			w := httptest.NewRecorder()
+			h.GetArtifactThumbnail(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestTripsHandler_GetArtifact_SignedURLRoundTrip(t *testing.T) {
+	mockStorage := data.NewDiskStorage(t.TempDir())
+	mockStorage.SignSecret = []byte("unit-test-secret")
+	ctx := context.Background()
+	storagePath, _ := mockStorage.Save(ctx, "photo.jpg", []byte("fake image data"), "image/jpeg")
+	jpegMime := "image/jpeg"
+	tripID := "trip_123"
+	mockStore := &data.MockStore{
+		GetArtifactByIDFunc: func(_ context.Context, id string) (*models.Artifact, error) {
+			if id != "art_123" {
+				return nil, errors.New("not found")
+			}
+			return &models.Artifact{ID: "art_123", Filename: "photo.jpg", StoragePath: storagePath, MimeType: &jpegMime, TripID: &tripID}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, mockStorage, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/artifacts?path=art_123", nil)
+	w := httptest.NewRecorder()
+	h.GetArtifact(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		URL       string `json:"url"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(resp.URL, "/artifacts/signed/") {
+		t.Fatalf("Expected a /artifacts/signed/ URL, got %q", resp.URL)
+	}
+	if resp.ExpiresAt == "" {
+		t.Error("Expected a non-empty expires_at")
+	}
+
+	token := strings.TrimPrefix(resp.URL, "/artifacts/signed/")
+	signedReq := httptest.NewRequest("GET", resp.URL, nil)
+	signedReq.SetPathValue("token", token)
+	signedW := httptest.NewRecorder()
+	h.ServeSignedArtifact(signedW, signedReq)
+	if signedW.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", signedW.Code, signedW.Body.String())
+	}
+	if signedW.Body.String() != "fake image data" {
+		t.Errorf("Expected artifact bytes, got %q", signedW.Body.String())
+	}
+}
+
+func TestTripsHandler_ServeSignedArtifact_RejectsTripMismatch(t *testing.T) {
+	mockStorage := data.NewDiskStorage(t.TempDir())
+	mockStorage.SignSecret = []byte("unit-test-secret")
+	ctx := context.Background()
+	storagePath, _ := mockStorage.Save(ctx, "photo.jpg", []byte("fake image data"), "image/jpeg")
+	jpegMime := "image/jpeg"
+	actualTripID := "trip_123"
+	mockStore := &data.MockStore{
+		GetArtifactByIDFunc: func(_ context.Context, id string) (*models.Artifact, error) {
+			if id != "art_123" {
+				return nil, errors.New("not found")
+			}
+			return &models.Artifact{ID: "art_123", Filename: "photo.jpg", StoragePath: storagePath, MimeType: &jpegMime, TripID: &actualTripID}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, mockStorage, nil, nil)
+
+	// Mint a token bound to a different trip than the artifact actually
+	// belongs to - simulates a URL leaked from trip "trip_456" being replayed
+	// against art_123, which really belongs to "trip_123".
+	token, _, err := mockStorage.SignedURL(ctx, storagePath, 0, data.SignedURLOptions{ArtifactID: "art_123", TripID: "trip_456"})
+	if err != nil {
+		t.Fatalf("Failed to mint signed URL: %v", err)
+	}
+	token = strings.TrimPrefix(token, "/artifacts/signed/")
+
+	req := httptest.NewRequest("GET", "/artifacts/signed/"+token, nil)
+	req.SetPathValue("token", token)
+	w := httptest.NewRecorder()
+	h.ServeSignedArtifact(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a trip-mismatched token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTripsHandler_ServeSignedArtifact_RejectsTamperedToken(t *testing.T) {
+	mockStorage := data.NewDiskStorage(t.TempDir())
+	mockStorage.SignSecret = []byte("unit-test-secret")
+	h := NewTripsHandler(&data.MockStore{}, nil, mockStorage, nil, nil)
+
+	req := httptest.NewRequest("GET", "/artifacts/signed/not-a-real-token", nil)
+	req.SetPathValue("token", "not-a-real-token")
+	w := httptest.NewRecorder()
+	h.ServeSignedArtifact(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", w.Code)
+	}
+}
+
 func TestTripsHandler_UploadItemPhoto_Unit(t *testing.T) {
 	mockStore := &data.MockStore{
+		FindArtifactByHashFunc: func(_ context.Context, _ string) (*models.Artifact, error) {
+			return nil, data.ErrNotFound
+		},
 		CreateArtifactFunc: func(_ context.Context, _, filename, _, _ string) (*models.Artifact, error) {
 			return &models.Artifact{ID: "art_new", Filename: filename}, nil
 		},
+		SaveArtifactExifFunc: func(_ context.Context, _ models.ArtifactExif) error {
+			return nil
+		},
 		AddItemPhotoFunc: func(_ context.Context, _, itemName, _ string) (*models.ChecklistItem, error) {
 			return &models.ChecklistItem{Name: itemName}, nil
 		},
 	}
 	mockStorage := data.NewDiskStorage(t.TempDir())
-	h := NewTripsHandler(mockStore, nil, mockStorage)
+	h := NewTripsHandler(mockStore, nil, mockStorage, nil, nil)
 	// Create multipart form request
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
@@ -157,3 +309,73 @@ func TestTripsHandler_UploadItemPhoto_Unit(t *testing.T) {
 		t.Errorf("Expected status success, got %s", resp["status"])
 	}
 }
+
+func TestTripsHandler_UploadItemPhoto_DedupesByHash(t *testing.T) {
+	addItemPhotoCalls := 0
+	mockStore := &data.MockStore{
+		FindArtifactByHashFunc: func(_ context.Context, _ string) (*models.Artifact, error) {
+			return &models.Artifact{ID: "art_existing"}, nil
+		},
+		AddItemPhotoFunc: func(_ context.Context, _, itemName, photoArtifactID string) (*models.ChecklistItem, error) {
+			addItemPhotoCalls++
+			if photoArtifactID != "art_existing" {
+				t.Errorf("Expected the existing artifact to be linked, got %q", photoArtifactID)
+			}
+			return &models.ChecklistItem{Name: itemName}, nil
+		},
+	}
+	mockStorage := data.NewDiskStorage(t.TempDir())
+	h := NewTripsHandler(mockStore, nil, mockStorage, nil, nil)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "test.jpg")
+	_, _ = part.Write([]byte("image content"))
+	_ = writer.Close()
+	req := httptest.NewRequest("POST", "/api/trips/trip1/items/item1/photo", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetPathValue("id", "trip1")
+	req.SetPathValue("itemId", "item1")
+	w := httptest.NewRecorder()
+	h.UploadItemPhoto(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp["artifact_id"] != "art_existing" {
+		t.Errorf("Expected the existing artifact_id to be reused, got %s", resp["artifact_id"])
+	}
+	if addItemPhotoCalls != 1 {
+		t.Errorf("Expected AddItemPhoto to be called once, got %d", addItemPhotoCalls)
+	}
+}
+
+func TestTripsHandler_GetPhotosNear_Unit(t *testing.T) {
+	mockStore := &data.MockStore{
+		FindArtifactsNearFunc: func(_ context.Context, tripID string, lat, lon, radiusMeters float64) ([]models.Artifact, error) {
+			if tripID != "trip1" || lat != 1.5 || lon != -2.5 || radiusMeters != 100 {
+				t.Errorf("Unexpected args: tripID=%s lat=%v lon=%v radius=%v", tripID, lat, lon, radiusMeters)
+			}
+			return []models.Artifact{{ID: "art_1"}}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/trips/trip1/photos/near?lat=1.5&lon=-2.5&radius_m=100", nil)
+	req.SetPathValue("id", "trip1")
+	w := httptest.NewRecorder()
+	h.GetPhotosNear(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var artifacts []models.Artifact
+	if err := json.NewDecoder(w.Body).Decode(&artifacts); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(artifacts) != 1 || artifacts[0].ID != "art_1" {
+		t.Errorf("Expected one artifact art_1, got %v", artifacts)
+	}
+}
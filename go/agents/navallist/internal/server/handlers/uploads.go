@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+
+	"github.com/charmbracelet/log"
+)
+
+// maxResumableUploadSize caps a session's declared total size; beyond this
+// a photo/video should be split client-side rather than uploaded as one
+// in-memory blob (the session's accumulated bytes live in a single BYTEA
+// column until FinalizeUpload runs).
+const maxResumableUploadSize = 200 << 20 // 200MB
+
+// CreateUploadSession starts a tus-style resumable upload for a large photo
+// or video: POST /api/trips/{id}/items/{itemId}/photo/uploads.
+func (h *TripsHandler) CreateUploadSession(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	itemID := r.PathValue("itemId")
+	if tripID == "" || itemID == "" {
+		http.Error(w, "Missing trip ID or Item ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Filename  string `json:"filename"`
+		MimeType  string `json:"mime_type"`
+		TotalSize int64  `json:"total_size"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Filename == "" || req.TotalSize <= 0 {
+		http.Error(w, "filename and a positive total_size are required", http.StatusBadRequest)
+		return
+	}
+	if req.TotalSize > maxResumableUploadSize {
+		http.Error(w, "total_size exceeds the maximum upload size", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.Store.CreateUploadSession(r.Context(), tripID, itemID, req.Filename, req.MimeType, req.TotalSize)
+	if err != nil {
+		http.Error(w, "Error creating upload session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"upload_id": session.ID})
+}
+
+// AppendUploadChunk appends a chunk to an in-progress resumable upload:
+// PATCH /api/trips/{id}/items/{itemId}/photo/uploads/{uploadId}. The
+// Upload-Offset header must match the session's current received size, per
+// tus semantics, so a retried or out-of-order chunk is rejected rather than
+// silently corrupting the upload. Once the chunk completes the declared
+// total size, the upload is auto-finalized into a real artifact as a
+// deliberate simplification over a separate explicit "complete" step.
+func (h *TripsHandler) AppendUploadChunk(w http.ResponseWriter, r *http.Request) {
+	uploadID := r.PathValue("uploadId")
+	if uploadID == "" {
+		http.Error(w, "Missing upload ID", http.StatusBadRequest)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "Missing or invalid Upload-Offset header", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.Store.GetUploadSession(r.Context(), uploadID)
+	if err != nil {
+		h.respondUploadSessionError(w, err)
+		return
+	}
+	if offset != session.ReceivedSize {
+		http.Error(w, fmt.Sprintf("Upload-Offset %d does not match current offset %d", offset, session.ReceivedSize), http.StatusConflict)
+		return
+	}
+
+	chunk, err := io.ReadAll(io.LimitReader(r.Body, maxResumableUploadSize-session.ReceivedSize+1))
+	if err != nil {
+		http.Error(w, "Error reading chunk", http.StatusInternalServerError)
+		return
+	}
+	if session.ReceivedSize+int64(len(chunk)) > session.TotalSize {
+		http.Error(w, "chunk would exceed the upload's declared total_size", http.StatusBadRequest)
+		return
+	}
+
+	session, err = h.Store.AppendUploadChunk(r.Context(), uploadID, chunk)
+	if err != nil {
+		http.Error(w, "Error appending upload chunk: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if session.ReceivedSize < session.TotalSize {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(session.ReceivedSize, 10))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	artifactID, err := h.finalizeUploadSession(r.Context(), session)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":      "success",
+		"artifact_id": artifactID,
+	})
+}
+
+// GetUploadProgress reports how much of a resumable upload has arrived so
+// far: HEAD /api/trips/{id}/items/{itemId}/photo/uploads/{uploadId}.
+func (h *TripsHandler) GetUploadProgress(w http.ResponseWriter, r *http.Request) {
+	uploadID := r.PathValue("uploadId")
+	if uploadID == "" {
+		http.Error(w, "Missing upload ID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.Store.GetUploadSession(r.Context(), uploadID)
+	if err != nil {
+		h.respondUploadSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.ReceivedSize, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(session.TotalSize, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// finalizeUploadSession marks session complete (enforcing the trip's upload
+// quota) and runs its accumulated bytes through the same dedupe/normalize/
+// store/link pipeline UploadItemPhoto uses, returning the linked artifact's
+// ID.
+func (h *TripsHandler) finalizeUploadSession(ctx context.Context, session *models.ArtifactUpload) (string, error) {
+	finalized, err := h.Store.FinalizeUpload(ctx, session.ID)
+	if err != nil {
+		return "", fmt.Errorf("error finalizing upload: %w", err)
+	}
+
+	mimeType := ""
+	if finalized.MimeType != nil {
+		mimeType = *finalized.MimeType
+	}
+
+	artifactID, err := h.ingestPhotoUpload(ctx, finalized.TripID, finalized.ItemName, finalized.Data, mimeType, filepath.Ext(finalized.Filename))
+	if err != nil {
+		return "", err
+	}
+	return artifactID, nil
+}
+
+// respondUploadSessionError maps a Store error from an upload-session
+// lookup to the HTTP status a client should see.
+func (h *TripsHandler) respondUploadSessionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, data.ErrNotFound) {
+		http.Error(w, "Upload session not found", http.StatusNotFound)
+		return
+	}
+	log.Error("Failed to look up upload session", "error", err)
+	http.Error(w, "Error looking up upload session", http.StatusInternalServerError)
+}
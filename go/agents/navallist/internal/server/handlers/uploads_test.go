@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTripsHandler_CreateUploadSession_Unit(t *testing.T) {
+	mockStore := &data.MockStore{
+		CreateUploadSessionFunc: func(_ context.Context, tripID, itemName, filename, mimeType string, totalSize int64) (*models.ArtifactUpload, error) {
+			if tripID != "trip1" || itemName != "item1" || filename != "video.mp4" || totalSize != 20 {
+				t.Errorf("unexpected args: %s %s %s %d", tripID, itemName, filename, totalSize)
+			}
+			return &models.ArtifactUpload{ID: "upload_1", TripID: tripID, ItemName: itemName, Filename: filename, TotalSize: totalSize}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	body, _ := json.Marshal(map[string]any{"filename": "video.mp4", "mime_type": "video/mp4", "total_size": 20})
+	req := httptest.NewRequest("POST", "/api/trips/trip1/items/item1/photo/uploads", bytes.NewReader(body))
+	req.SetPathValue("id", "trip1")
+	req.SetPathValue("itemId", "item1")
+	w := httptest.NewRecorder()
+	h.CreateUploadSession(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp["upload_id"] != "upload_1" {
+		t.Errorf("Expected upload_1, got %s", resp["upload_id"])
+	}
+}
+
+func TestTripsHandler_AppendUploadChunk_AutoFinalizes(t *testing.T) {
+	chunk := []byte("image content")
+	session := &models.ArtifactUpload{ID: "upload_1", TripID: "trip1", ItemName: "item1", Filename: "test.jpg", TotalSize: int64(len(chunk))}
+
+	mockStore := &data.MockStore{
+		GetUploadSessionFunc: func(_ context.Context, uploadID string) (*models.ArtifactUpload, error) {
+			return session, nil
+		},
+		AppendUploadChunkFunc: func(_ context.Context, uploadID string, data []byte) (*models.ArtifactUpload, error) {
+			session.Data = append(session.Data, data...)
+			session.ReceivedSize += int64(len(data))
+			return session, nil
+		},
+		FinalizeUploadFunc: func(_ context.Context, uploadID string) (*models.ArtifactUpload, error) {
+			session.Status = "complete"
+			return session, nil
+		},
+		FindArtifactByHashFunc: func(_ context.Context, _ string) (*models.Artifact, error) {
+			return nil, data.ErrNotFound
+		},
+		CreateArtifactFunc: func(_ context.Context, _, filename, _, _ string) (*models.Artifact, error) {
+			return &models.Artifact{ID: "art_new", Filename: filename}, nil
+		},
+		SaveArtifactExifFunc: func(_ context.Context, _ models.ArtifactExif) error { return nil },
+		AddItemPhotoFunc: func(_ context.Context, _, itemName, photoArtifactID string) (*models.ChecklistItem, error) {
+			return &models.ChecklistItem{Name: itemName}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/trips/trip1/items/item1/photo/uploads/upload_1", bytes.NewReader(chunk))
+	req.Header.Set("Upload-Offset", "0")
+	req.SetPathValue("id", "trip1")
+	req.SetPathValue("itemId", "item1")
+	req.SetPathValue("uploadId", "upload_1")
+	w := httptest.NewRecorder()
+	h.AppendUploadChunk(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 once the declared size is reached, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if resp["artifact_id"] != "art_new" {
+		t.Errorf("Expected art_new, got %s", resp["artifact_id"])
+	}
+}
+
+func TestTripsHandler_AppendUploadChunk_RejectsOffsetMismatch(t *testing.T) {
+	mockStore := &data.MockStore{
+		GetUploadSessionFunc: func(_ context.Context, uploadID string) (*models.ArtifactUpload, error) {
+			return &models.ArtifactUpload{ID: uploadID, TotalSize: 100, ReceivedSize: 10}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/trips/trip1/items/item1/photo/uploads/upload_1", bytes.NewReader([]byte("x")))
+	req.Header.Set("Upload-Offset", "0")
+	req.SetPathValue("uploadId", "upload_1")
+	w := httptest.NewRecorder()
+	h.AppendUploadChunk(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 on offset mismatch, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTripsHandler_GetUploadProgress_Unit(t *testing.T) {
+	mockStore := &data.MockStore{
+		GetUploadSessionFunc: func(_ context.Context, uploadID string) (*models.ArtifactUpload, error) {
+			if uploadID == "missing" {
+				return nil, data.ErrNotFound
+			}
+			return &models.ArtifactUpload{ID: uploadID, TotalSize: 100, ReceivedSize: 40}, nil
+		},
+	}
+	h := NewTripsHandler(mockStore, nil, data.NewDiskStorage(t.TempDir()), nil, nil)
+
+	req := httptest.NewRequest("HEAD", "/api/trips/trip1/items/item1/photo/uploads/upload_1", nil)
+	req.SetPathValue("uploadId", "upload_1")
+	w := httptest.NewRecorder()
+	h.GetUploadProgress(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if w.Header().Get("Upload-Offset") != "40" || w.Header().Get("Upload-Length") != "100" {
+		t.Errorf("Unexpected headers: offset=%s length=%s", w.Header().Get("Upload-Offset"), w.Header().Get("Upload-Length"))
+	}
+
+	req = httptest.NewRequest("HEAD", "/api/trips/trip1/items/item1/photo/uploads/missing", nil)
+	req.SetPathValue("uploadId", "missing")
+	w = httptest.NewRecorder()
+	h.GetUploadProgress(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown upload, got %d", w.Code)
+	}
+}
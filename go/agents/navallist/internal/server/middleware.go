@@ -3,20 +3,25 @@ package server
 import (
 	"net/http"
 
+	"navallist/internal/auth"
 	"navallist/internal/data"
+	"navallist/internal/data/models"
 )
 
-// AuthMiddleware wraps handlers to enforce authentication.
+// AuthMiddleware wraps handlers to enforce authentication. The user_session
+// cookie carries an auth.SignToken-signed userID rather than a bare ID, so a
+// forged or stale cookie can't be replayed after the signing secret changes.
 func (s *Server) AuthMiddleware(authLevel int, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Attempt to resolve user from cookie
-		cookie, err := r.Cookie("user_session")
-		if err == nil {
-			userID := cookie.Value
-			user, err := s.Store.GetUser(r.Context(), userID)
-			if err == nil {
-				// User found, add to context using helpers
-				r = r.WithContext(data.WithUser(r.Context(), user))
+		var user *models.User
+
+		// Attempt to resolve user from the signed session cookie.
+		if cookie, err := r.Cookie("user_session"); err == nil {
+			if userID, err := auth.VerifyToken(s.Config.Auth.SessionSecret, cookie.Value); err == nil {
+				if u, err := s.Store.GetUser(r.Context(), userID); err == nil {
+					user = u
+					r = r.WithContext(data.WithUser(r.Context(), user))
+				}
 			}
 		}
 
@@ -25,17 +30,43 @@ func (s *Server) AuthMiddleware(authLevel int, next http.HandlerFunc) http.Handl
 			r = r.WithContext(data.WithGuestName(r.Context(), name))
 		}
 
-		// Enforce Authentication if required
-		if authLevel != AuthLevelPublic && data.GetUserID(r.Context()) == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+		switch authLevel {
+		case AuthLevelUser:
+			if data.GetUserID(r.Context()) == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		case AuthLevelAdmin:
+			if user == nil || !user.IsAdmin {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
 		}
 
 		next(w, r)
 	}
 }
 
-// PermissiveAuthMiddleware resolves user if possible but always allows the request.
-func (s *Server) PermissiveAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return s.AuthMiddleware(AuthLevelPublic, next)
+// ConnectTokenMiddleware resolves the caller's identity from a signed
+// Centrifuge connect token (the "token" query param, issued by
+// AuthHandler.IssueConnectToken) instead of the user_session cookie, since
+// browsers don't attach cookies to WebSocket upgrade requests from a
+// different origin. Unauthenticated and guest connections are still
+// allowed, matching the prior PermissiveAuthMiddleware behavior.
+func (s *Server) ConnectTokenMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" {
+			if userID, err := auth.VerifyToken(s.Config.Auth.SessionSecret, token); err == nil {
+				if user, err := s.Store.GetUser(r.Context(), userID); err == nil {
+					r = r.WithContext(data.WithUser(r.Context(), user))
+				}
+			}
+		}
+
+		if name := r.URL.Query().Get("name"); name != "" {
+			r = r.WithContext(data.WithGuestName(r.Context(), name))
+		}
+
+		next(w, r)
+	}
 }
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"navallist/internal/auth"
 	"navallist/internal/config"
 	"navallist/internal/data"
 	"navallist/internal/data/models"
@@ -16,6 +18,8 @@ func TestAuthMiddleware_Unit(t *testing.T) {
 	// AuthLevelUser requires a valid user session.
 	const AuthLevelUser = 1
 
+	sessionSecret := []byte("test-secret")
+
 	mockStore := &data.MockStore{
 		GetUserFunc: func(_ context.Context, id string) (*models.User, error) {
 			if id == "user_123" {
@@ -27,12 +31,17 @@ func TestAuthMiddleware_Unit(t *testing.T) {
 
 	srv := &Server{
 		Store:  mockStore,
-		Config: &config.Config{},
+		Config: &config.Config{Auth: config.AuthConfig{SessionSecret: sessionSecret}},
 	}
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	validToken, err := auth.SignToken(sessionSecret, "user_123", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to sign test token: %v", err)
+	}
+
 	tests := []struct {
 		name           string
 		cookieValue    string
@@ -40,7 +49,7 @@ func TestAuthMiddleware_Unit(t *testing.T) {
 	}{
 		{
 			name:           "Valid User",
-			cookieValue:    "user_123",
+			cookieValue:    validToken,
 			expectedStatus: http.StatusOK,
 		},
 		{
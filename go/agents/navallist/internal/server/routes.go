@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"navallist/internal/agent"
+	"navallist/internal/auth"
 	"navallist/internal/config"
 	"navallist/internal/data"
+	"navallist/internal/mailer"
 	"navallist/internal/realtime"
 	"navallist/internal/server/handlers"
 
@@ -14,12 +16,17 @@ import (
 
 // Server holds dependencies for the HTTP server.
 type Server struct {
-	Store       data.Store
-	Config      *config.Config
-	Mux         *http.ServeMux
-	AgentClient *agent.LocalAgentClient
-	Storage     data.BlobStorage
-	FrontendFS  http.FileSystem
+	Store            data.Store
+	Config           *config.Config
+	Mux              *http.ServeMux
+	AgentClient      agent.Client
+	Storage          data.BlobStorage
+	FrontendFS       http.FileSystem
+	Mailer           mailer.Mailer
+	AuthProvider     auth.Provider
+	PasswordProvider auth.PasswordProvider
+	Realtime         realtime.Publisher
+	SSEHub           *realtime.SSEHub
 }
 
 // Route definition
@@ -34,19 +41,30 @@ type route struct {
 const (
 	// AuthLevelPublic allows access without authentication.
 	AuthLevelPublic = 0
+	// AuthLevelUser requires a valid logged-in user session.
+	AuthLevelUser = 1
+	// AuthLevelAdmin requires a logged-in user with IsAdmin set.
+	AuthLevelAdmin = 2
 )
 
-// NewServer initializes the server with routes.
-func NewServer(store data.Store, cfg *config.Config, agentClient *agent.LocalAgentClient, storage data.BlobStorage, frontendFS http.FileSystem) *Server {
+// NewServer initializes the server with routes. realtimePublisher and
+// sseHub may be nil if the caller doesn't want trip events published in
+// real time (e.g. tests).
+func NewServer(store data.Store, cfg *config.Config, agentClient agent.Client, storage data.BlobStorage, frontendFS http.FileSystem, m mailer.Mailer, provider auth.Provider, passwordProvider auth.PasswordProvider, realtimePublisher realtime.Publisher, sseHub *realtime.SSEHub) *Server {
 	mux := http.NewServeMux()
 
 	s := &Server{
-		Mux:         mux,
-		Store:       store,
-		Config:      cfg,
-		AgentClient: agentClient,
-		Storage:     storage,
-		FrontendFS:  frontendFS,
+		Mux:              mux,
+		Store:            store,
+		Config:           cfg,
+		AgentClient:      agentClient,
+		Storage:          storage,
+		FrontendFS:       frontendFS,
+		Mailer:           m,
+		AuthProvider:     provider,
+		PasswordProvider: passwordProvider,
+		Realtime:         realtimePublisher,
+		SSEHub:           sseHub,
 	}
 
 	s.registerRoutes()
@@ -55,8 +73,9 @@ func NewServer(store data.Store, cfg *config.Config, agentClient *agent.LocalAge
 
 func (s *Server) registerRoutes() {
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(s.Store)
-	tripsHandler := handlers.NewTripsHandler(s.Store, s.AgentClient, s.Storage)
+	authHandler := handlers.NewAuthHandler(s.Store, s.Mailer, s.AuthProvider, s.PasswordProvider, s.Config.Auth.SessionSecret, s.Config.Mailer.VerificationTTL, s.Config.Auth.SessionCookieTTL, s.Config.Auth.RefreshTokenTTL)
+	tripsHandler := handlers.NewTripsHandler(s.Store, s.AgentClient, s.Storage, s.Realtime, s.SSEHub)
+	adminHandler := handlers.NewAdminHandler(s.Store, s.Storage)
 
 	routes := []route{
 		// Health
@@ -64,24 +83,56 @@ func (s *Server) registerRoutes() {
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte("OK"))
 		}, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/healthz/agent", Handler: tripsHandler.AgentHealth, AuthLevel: AuthLevelPublic},
 
-		// Auth Routes (Simplified)
+		// Auth Routes
+		{Verb: "GET", Path: "/auth/login", Handler: authHandler.Login, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/auth/callback", Handler: authHandler.Callback, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/auth/login/password", Handler: authHandler.LoginPassword, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/auth/refresh", Handler: authHandler.Refresh, AuthLevel: AuthLevelPublic},
 		{Verb: "GET", Path: "/auth/logout", Handler: authHandler.Logout, AuthLevel: AuthLevelPublic},
 		{Verb: "GET", Path: "/auth/me", Handler: authHandler.GetMe, AuthLevel: AuthLevelPublic},
 		{Verb: "PUT", Path: "/auth/me", Handler: authHandler.UpdateMe, AuthLevel: AuthLevelPublic},
-
-		// Trip Routes
-		{Verb: "GET", Path: "/api/trips", Handler: tripsHandler.ListTrips, AuthLevel: AuthLevelPublic},
-		{Verb: "POST", Path: "/api/trips", Handler: tripsHandler.CreateTrip, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/auth/ws-token", Handler: authHandler.IssueConnectToken, AuthLevel: AuthLevelUser},
+		{Verb: "POST", Path: "/api/auth/email/start", Handler: authHandler.StartEmailVerification, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/auth/email/verify", Handler: authHandler.VerifyEmail, AuthLevel: AuthLevelPublic},
+
+		// Trip Routes. Listing/creation require a real user so trips are
+		// scoped to an account; GetOrCreateTrip still supports anonymous
+		// guest sessions joined by link, which is an existing core feature.
+		{Verb: "GET", Path: "/api/trips", Handler: tripsHandler.ListTrips, AuthLevel: AuthLevelUser},
+		{Verb: "POST", Path: "/api/trips", Handler: tripsHandler.CreateTrip, AuthLevel: AuthLevelUser},
 		{Verb: "GET", Path: "/api/trips/{id}", Handler: tripsHandler.GetTrip, AuthLevel: AuthLevelPublic},
 		{Verb: "PUT", Path: "/api/trips/{id}/items/{itemId}", Handler: tripsHandler.UpdateItem, AuthLevel: AuthLevelPublic},
 		{Verb: "POST", Path: "/api/trips/{id}/items/{itemId}/photo", Handler: tripsHandler.UploadItemPhoto, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/trips/{id}/items/{itemId}/photo/uploads", Handler: tripsHandler.CreateUploadSession, AuthLevel: AuthLevelPublic},
+		{Verb: "PATCH", Path: "/api/trips/{id}/items/{itemId}/photo/uploads/{uploadId}", Handler: tripsHandler.AppendUploadChunk, AuthLevel: AuthLevelPublic},
+		{Verb: "HEAD", Path: "/api/trips/{id}/items/{itemId}/photo/uploads/{uploadId}", Handler: tripsHandler.GetUploadProgress, AuthLevel: AuthLevelPublic},
 		{Verb: "DELETE", Path: "/api/trips/{id}", Handler: tripsHandler.DeleteTrip, AuthLevel: AuthLevelPublic},
 		{Verb: "GET", Path: "/api/trips/{id}/status", Handler: tripsHandler.UpdateStatus, AuthLevel: AuthLevelPublic},
 		{Verb: "PATCH", Path: "/api/trips/{id}/status", Handler: tripsHandler.UpdateStatus, AuthLevel: AuthLevelPublic},
 		{Verb: "PATCH", Path: "/api/trips/{id}/type", Handler: tripsHandler.UpdateType, AuthLevel: AuthLevelPublic},
 		{Verb: "GET", Path: "/api/trips/{id}/report", Handler: tripsHandler.GetReport, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/presence", Handler: tripsHandler.GetPresence, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/agent/stream", Handler: tripsHandler.StreamAgentEvents, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/agent/ws", Handler: tripsHandler.StreamAgentEventsWS, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/trips/{id}/sync", Handler: tripsHandler.SyncItems, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/changes", Handler: tripsHandler.GetChanges, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/trips/{id}/ops", Handler: tripsHandler.PushOps, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/ops", Handler: tripsHandler.GetOps, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/events", Handler: tripsHandler.StreamEvents, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/timeline", Handler: tripsHandler.GetTimeline, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/trips/{id}/replay", Handler: tripsHandler.ReplayTrip, AuthLevel: AuthLevelPublic},
 		{Verb: "GET", Path: "/api/artifacts", Handler: tripsHandler.GetArtifact, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/artifacts/{id}/thumbnail", Handler: tripsHandler.GetArtifactThumbnail, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/artifacts/presign", Handler: tripsHandler.PresignArtifactUpload, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/api/trips/{id}/photos/near", Handler: tripsHandler.GetPhotosNear, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/trips/{id}/crew/resolve", Handler: tripsHandler.ResolveCrewMember, AuthLevel: AuthLevelPublic},
+		{Verb: "GET", Path: "/artifacts/signed/{token}", Handler: tripsHandler.ServeSignedArtifact, AuthLevel: AuthLevelPublic},
+
+		// Admin Routes
+		{Verb: "GET", Path: "/api/admin/trips/{id}/export", Handler: adminHandler.ExportTrip, AuthLevel: AuthLevelAdmin},
+		{Verb: "POST", Path: "/api/admin/trips/import", Handler: adminHandler.ImportTrip, AuthLevel: AuthLevelAdmin},
 	}
 
 	for _, r := range routes {
@@ -91,13 +142,13 @@ func (s *Server) registerRoutes() {
 		s.Mux.HandleFunc(pattern, s.AuthMiddleware(r.AuthLevel, r.Handler))
 	}
 
-	// Agent Routes
+	// Agent Routes require a real user; the agent acts on a user's trips.
 	checklistHandler := handlers.NewChecklistHandler(s.AgentClient, s.Store)
 
 	agentRoutes := []route{
-		{Verb: "POST", Path: "/api/agent/sessions", Handler: checklistHandler.CreateSession, AuthLevel: AuthLevelPublic},
-		{Verb: "GET", Path: "/api/agent/sessions/{sessionID}", Handler: checklistHandler.GetSession, AuthLevel: AuthLevelPublic},
-		{Verb: "POST", Path: "/api/agent/run", Handler: checklistHandler.RunInteraction, AuthLevel: AuthLevelPublic},
+		{Verb: "POST", Path: "/api/agent/sessions", Handler: checklistHandler.CreateSession, AuthLevel: AuthLevelUser},
+		{Verb: "GET", Path: "/api/agent/sessions/{sessionID}", Handler: checklistHandler.GetSession, AuthLevel: AuthLevelUser},
+		{Verb: "POST", Path: "/api/agent/run", Handler: checklistHandler.RunInteraction, AuthLevel: AuthLevelUser},
 	}
 
 	for _, r := range agentRoutes {
@@ -115,5 +166,5 @@ func (s *Server) MountWebSocket(rtService *realtime.Service) {
 			return true // Allow all origins for now
 		},
 	})
-	s.Mux.Handle("/connection/websocket", s.PermissiveAuthMiddleware(wsHandler.ServeHTTP))
+	s.Mux.Handle("/connection/websocket", s.ConnectTokenMiddleware(wsHandler.ServeHTTP))
 }
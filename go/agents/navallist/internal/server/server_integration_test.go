@@ -26,7 +26,7 @@ func TestNewServer(t *testing.T) {
 	cfg := &config.Config{
 		FrontendDir: "../frontend",
 	}
-	srv := NewServer(store, cfg, nil, nil, nil)
+	srv := NewServer(store, cfg, nil, nil, nil, nil, nil, nil, nil, nil)
 	if srv.Mux == nil {
 		t.Fatal("Server Mux is nil")
 	}
@@ -0,0 +1,79 @@
+// Package storagereconciler periodically reconciles artifacts created via
+// the direct-upload flow (data.PresignUploader / POST /api/artifacts/presign)
+// against the storage backend, since this server never sees the bytes for
+// those uploads and so can't confirm at request time whether they landed.
+package storagereconciler
+
+import (
+	"context"
+	"time"
+
+	"navallist/internal/data"
+
+	"github.com/charmbracelet/log"
+)
+
+// pendingGracePeriod is how long a "pending" artifact is left alone before
+// a sweep checks whether its direct upload ever landed, giving a slow
+// client's in-flight PUT time to finish before it's judged orphaned.
+const pendingGracePeriod = 10 * time.Minute
+
+// Reconciler periodically checks every artifact still "pending" a direct
+// upload against the storage backend, confirming it once its bytes
+// actually exist or marking it "orphan" if the client never completed the
+// upload.
+type Reconciler struct {
+	Store   data.Store
+	Storage data.BlobStorage
+}
+
+// New creates a Reconciler over store/storage.
+func New(store data.Store, storage data.BlobStorage) *Reconciler {
+	return &Reconciler{Store: store, Storage: storage}
+}
+
+// Run sweeps for pending artifacts every interval until ctx is canceled.
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep checks every pending artifact older than pendingGracePeriod,
+// confirming it if its object now exists in the backend or marking it
+// orphan otherwise. Exported so callers (and tests) can run a single pass
+// without waiting on Run's ticker.
+func (r *Reconciler) Sweep(ctx context.Context) {
+	pending, err := r.Store.ListPendingArtifacts(ctx, time.Now().Add(-pendingGracePeriod))
+	if err != nil {
+		log.Error("storage reconciler: failed to list pending artifacts", "error", err)
+		return
+	}
+
+	for _, artifact := range pending {
+		exists, err := r.Storage.Exists(ctx, artifact.StoragePath)
+		if err != nil {
+			log.Warn("storage reconciler: failed to check artifact existence", "artifact_id", artifact.ID, "error", err)
+			continue
+		}
+
+		if exists {
+			if _, err := r.Store.ConfirmArtifact(ctx, artifact.ID); err != nil {
+				log.Error("storage reconciler: failed to confirm artifact", "artifact_id", artifact.ID, "error", err)
+			}
+			continue
+		}
+
+		if err := r.Store.MarkArtifactOrphan(ctx, artifact.ID); err != nil {
+			log.Error("storage reconciler: failed to mark artifact orphan", "artifact_id", artifact.ID, "error", err)
+		}
+	}
+}
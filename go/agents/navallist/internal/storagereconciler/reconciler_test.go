@@ -0,0 +1,71 @@
+package storagereconciler
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"navallist/internal/data"
+	"navallist/internal/data/models"
+)
+
+// fakeStorage is a minimal data.BlobStorage stub: Sweep only ever calls
+// Exists, so every other method just panics if reached.
+type fakeStorage struct {
+	existsFunc func(ctx context.Context, path string) (bool, error)
+}
+
+func (f *fakeStorage) Save(context.Context, string, []byte, string) (string, error) { panic("unused") }
+func (f *fakeStorage) SaveStream(context.Context, string, io.Reader, string) (string, int64, error) {
+	panic("unused")
+}
+func (f *fakeStorage) Load(context.Context, string) ([]byte, error) { panic("unused") }
+func (f *fakeStorage) Delete(context.Context, string) error         { panic("unused") }
+func (f *fakeStorage) GetPublicURL(string) string                   { panic("unused") }
+func (f *fakeStorage) SignedURL(context.Context, string, time.Duration, data.SignedURLOptions) (string, time.Time, error) {
+	panic("unused")
+}
+func (f *fakeStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return f.existsFunc(ctx, path)
+}
+
+func TestReconciler_Sweep_ConfirmsArtifactsThatExist(t *testing.T) {
+	var confirmedID string
+	store := &data.MockStore{
+		ListPendingArtifactsFunc: func(_ context.Context, _ time.Time) ([]models.Artifact, error) {
+			return []models.Artifact{{ID: "art1", StoragePath: "s3://bucket/key1"}}, nil
+		},
+		ConfirmArtifactFunc: func(_ context.Context, id string) (*models.Artifact, error) {
+			confirmedID = id
+			return &models.Artifact{ID: id, Status: "confirmed"}, nil
+		},
+	}
+	storage := &fakeStorage{existsFunc: func(context.Context, string) (bool, error) { return true, nil }}
+
+	New(store, storage).Sweep(context.Background())
+
+	if confirmedID != "art1" {
+		t.Errorf("expected art1 to be confirmed, got %q", confirmedID)
+	}
+}
+
+func TestReconciler_Sweep_OrphansArtifactsThatDontExist(t *testing.T) {
+	var orphanedID string
+	store := &data.MockStore{
+		ListPendingArtifactsFunc: func(_ context.Context, _ time.Time) ([]models.Artifact, error) {
+			return []models.Artifact{{ID: "art2", StoragePath: "s3://bucket/key2"}}, nil
+		},
+		MarkArtifactOrphanFunc: func(_ context.Context, id string) error {
+			orphanedID = id
+			return nil
+		},
+	}
+	storage := &fakeStorage{existsFunc: func(context.Context, string) (bool, error) { return false, nil }}
+
+	New(store, storage).Sweep(context.Background())
+
+	if orphanedID != "art2" {
+		t.Errorf("expected art2 to be marked orphan, got %q", orphanedID)
+	}
+}